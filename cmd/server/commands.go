@@ -0,0 +1,186 @@
+// cmd/server/commands.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/importer"
+	"github.com/shravanirajulu2004/go-user-api/internal/migrate"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/service"
+)
+
+const usage = `usage:
+  server                       start the HTTP server
+  server migrate up            apply all pending migrations
+  server migrate down          roll back the latest migration
+  server migrate status        show applied and pending migrations
+  server migrate to <version>  migrate up or down to the given version
+  server purge [retention]     hard-delete users soft-deleted longer than
+                               retention (default PURGE_RETENTION)
+  server import [flags] <file> import users from a CSV or NDJSON file
+      -format csv|ndjson       file format (default from the file extension)
+      -dry-run                 validate without inserting
+      -dedup name|name_dob     skip rows matching an earlier row or user
+      -chunk-size N            rows per COPY transaction (default 1000)
+      -report <file>           write the JSON report to file`
+
+// commands holds what the CLI subcommands operate on
+type commands struct {
+	migrator migrate.Migrator
+	users    service.UserService
+}
+
+func (cmd *commands) run(ctx context.Context, args []string) error {
+	switch args[0] {
+	case "migrate":
+		return cmd.migrate(ctx, args[1:])
+	case "purge":
+		return cmd.purge(ctx, args[1:])
+	case "import":
+		return cmd.importUsers(ctx, args[1:])
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+}
+
+func (cmd *commands) purge(ctx context.Context, args []string) error {
+	var retention time.Duration
+	if len(args) > 0 {
+		d, err := time.ParseDuration(args[0])
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid retention %q", args[0])
+		}
+		retention = d
+	}
+
+	purged, err := cmd.users.PurgeDeletedUsers(ctx, retention)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Purged %d deleted users\n", purged)
+	return nil
+}
+
+func (cmd *commands) importUsers(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	formatName := flags.String("format", "", "")
+	reportPath := flags.String("report", "", "")
+	dedup := flags.String("dedup", "", "")
+	var opts models.ImportOptions
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "")
+	flags.IntVar(&opts.ChunkSize, "chunk-size", 0, "")
+	flags.Usage = func() {}
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w\n%s", err, usage)
+	}
+	opts.Dedup = models.DedupKey(*dedup)
+	if flags.NArg() != 1 {
+		return fmt.Errorf("import requires a file\n%s", usage)
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	path := flags.Arg(0)
+	if *formatName == "" {
+		*formatName = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	format, ok := importer.ParseFormat(*formatName)
+	if !ok {
+		return fmt.Errorf("unknown import format %q, use -format csv or -format ndjson", *formatName)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader, err := importer.NewReader(file, format)
+	if err != nil {
+		return err
+	}
+	// Chunks committed before a failure stay imported, so report them too
+	report, importErr := cmd.users.ImportUsers(ctx, reader, opts)
+	if report == nil {
+		return importErr
+	}
+
+	if *reportPath != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*reportPath, data, 0o644); err != nil {
+			return err
+		}
+	} else {
+		for _, e := range report.Errors {
+			fmt.Printf("line %d: %s\n", e.Line, e.Message)
+			for _, f := range e.Fields {
+				fmt.Printf("  %s\n", f.Message)
+			}
+		}
+	}
+
+	verb := "Imported"
+	if report.DryRun {
+		verb = "Would import"
+	}
+	fmt.Printf("%s %d of %d rows (%d skipped, %d failed)\n", verb, report.Imported, report.Total, report.Skipped, report.Failed)
+	return importErr
+}
+
+func (cmd *commands) migrate(ctx context.Context, args []string) error {
+	migrator := cmd.migrator
+
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate action\n%s", usage)
+	}
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		return migrator.Down(ctx)
+	case "to":
+		if len(args) != 2 {
+			return fmt.Errorf("migrate to requires a version\n%s", usage)
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		return migrator.To(ctx, version)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range statuses {
+			state, appliedAt := "pending", "-"
+			if s.Applied {
+				state = "applied"
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown migrate action %q\n%s", args[0], usage)
+	}
+}
@@ -2,12 +2,16 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
 	"database/sql"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -15,12 +19,20 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/shravanirajulu2004/go-user-api/config"
+	"github.com/shravanirajulu2004/go-user-api/db/migrations"
+	"github.com/shravanirajulu2004/go-user-api/internal/digest"
 	"github.com/shravanirajulu2004/go-user-api/internal/handler"
+	"github.com/shravanirajulu2004/go-user-api/internal/idempotency"
+	"github.com/shravanirajulu2004/go-user-api/internal/live"
 	"github.com/shravanirajulu2004/go-user-api/internal/logger"
 	"github.com/shravanirajulu2004/go-user-api/internal/middleware"
+	"github.com/shravanirajulu2004/go-user-api/internal/migrate"
+	"github.com/shravanirajulu2004/go-user-api/internal/outbox"
 	"github.com/shravanirajulu2004/go-user-api/internal/repository"
 	"github.com/shravanirajulu2004/go-user-api/internal/routes"
 	"github.com/shravanirajulu2004/go-user-api/internal/service"
+	"github.com/shravanirajulu2004/go-user-api/internal/stream"
+	"github.com/shravanirajulu2004/go-user-api/internal/webhook"
 )
 
 func main() {
@@ -54,25 +66,125 @@ func main() {
 
 	logger.Log.Info("Successfully connected to database")
 
+	migrator, err := migrate.NewMigrator(db, migrations.FS, logger.Log)
+	if err != nil {
+		logger.Log.Fatal("Failed to load migrations", zap.Error(err))
+	}
+
+	cursorSecret := []byte(cfg.CursorSecret)
+	if len(cursorSecret) == 0 {
+		logger.Log.Warn("CURSOR_SECRET is not set, pagination cursors will not survive a restart")
+		cursorSecret = make([]byte, 32)
+		if _, err := rand.Read(cursorSecret); err != nil {
+			logger.Log.Fatal("Failed to generate cursor secret", zap.Error(err))
+		}
+	}
+
 	// Initialize layers
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo, logger.Log)
+	userService := service.NewUserService(userRepo, logger.Log, service.Config{
+		PurgeRetention: cfg.PurgeRetention,
+		CursorSecret:   cursorSecret,
+
+		SearchMinSimilarity: cfg.SearchMinSimilarity,
+		MajorityAge:         cfg.MajorityAge,
+
+		DuplicatePolicy:        service.DuplicatePolicy(cfg.DuplicatePolicy),
+		DuplicateMinSimilarity: cfg.DuplicateMinSimilarity,
+	})
+
+	// Changes made through the service are pushed to WebSocket clients
+	liveHub := live.NewHub(live.Config{
+		BufferSize:       cfg.WSSendBuffer,
+		MaxSubscriptions: cfg.WSMaxSubscriptions,
+	})
+	userService = live.NewService(userService, liveHub)
 	userHandler := handler.NewUserHandler(userService, logger.Log)
+	webSocketHandler := handler.NewWebSocketHandler(userService, liveHub, handler.WebSocketConfig{
+		MaxMessageSize: int64(cfg.WSMaxMessageSize),
+		PingInterval:   cfg.WSPingInterval,
+	}, logger.Log)
+
+	webhookRepo := repository.NewWebhookRepository(db)
+	webhookService := service.NewWebhookService(webhookRepo, logger.Log, service.WebhookConfig{
+		CursorSecret: cursorSecret,
+	})
+	webhookHandler := handler.NewWebhookHandler(webhookService, logger.Log)
+
+	hub := stream.NewHub(stream.Config{LogSize: cfg.StreamLogSize})
+	streamHandler := handler.NewStreamHandler(hub, cfg.StreamHeartbeat, logger.Log)
+
+	// Run a CLI subcommand instead of the server when one is given
+	if len(os.Args) > 1 {
+		cmd := &commands{migrator: migrator, users: userService}
+		if err := cmd.run(context.Background(), os.Args[1:]); err != nil {
+			logger.Log.Fatal("Command failed", zap.String("command", os.Args[1]), zap.Error(err))
+		}
+		return
+	}
+
+	// Bring the schema up to date, refusing to start against a newer schema
+	if cfg.AutoMigrate {
+		err = migrator.Up(context.Background())
+	} else {
+		err = migrator.Verify(context.Background())
+	}
+	if err != nil {
+		logger.Log.Fatal("Database schema check failed", zap.Error(err))
+	}
 
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
 		AppName:      "User API",
 		ErrorHandler: customErrorHandler,
+
+		// Lets imports read large uploads without buffering them whole
+		StreamRequestBody: true,
 	})
 
 	// Global middleware
-	app.Use(cors.New())
+	app.Use(cors.New(cors.Config{
+		ExposeHeaders: "ETag, Link, X-Total-Count, X-Request-ID, Idempotent-Replayed",
+	}))
 	app.Use(middleware.RequestIDMiddleware())
+	app.Use(middleware.AdminMiddleware(cfg.AdminAPIKey))
 	app.Use(middleware.RecoveryMiddleware(logger.Log))
 	app.Use(middleware.LoggerMiddleware(logger.Log))
 
+	// Background jobs stop when the server shuts down
+	jobs, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
 	// Setup routes
-	routes.SetupRoutes(app, userHandler)
+	idempotencyStore := idempotency.NewPostgresStore(db)
+	go idempotency.RunCleanup(jobs, idempotencyStore, time.Hour, logger.Log)
+	routes.SetupRoutes(app, userHandler, webhookHandler, streamHandler, webSocketHandler, middleware.Idempotency(idempotencyStore, cfg.IdempotencyTTL, logger.Log))
+
+	if notifier := digestNotifier(cfg); notifier != nil {
+		job := digest.NewJob(userService, notifier, digest.Config{
+			At:       cfg.BirthdayDigestAt,
+			Location: cfg.BirthdayDigestLocation,
+			Within:   cfg.BirthdayDigestWithin,
+		}, logger.Log)
+		go job.Run(jobs)
+	}
+
+	// Webhook subscriptions always receive user events
+	sinks := append(eventSinks(cfg), webhook.NewSink(webhookRepo))
+	relay := outbox.NewRelay(outbox.NewPostgresStore(db), sinks, outbox.Config{
+		PollInterval: cfg.EventPollInterval,
+		Retention:    cfg.EventRetention,
+	}, logger.Log)
+	go relay.Run(jobs)
+	// The relay runs on one replica at a time, so every replica feeds its own
+	// event stream
+	go outbox.NewFeed(db, cfg.DatabaseURL, hub, logger.Log).Run(jobs)
+
+	dispatcher := webhook.NewDispatcher(webhookRepo, &http.Client{}, webhook.Config{
+		Timeout:     cfg.WebhookTimeout,
+		MaxAttempts: cfg.WebhookMaxAttempts,
+	}, logger.Log)
+	go dispatcher.Run(jobs)
 
 	// Start server in goroutine
 	go func() {
@@ -89,6 +201,11 @@ func main() {
 	<-quit
 
 	logger.Log.Info("Shutting down server...")
+	stopJobs()
+	// Streams and sockets never finish on their own, so end them before
+	// waiting for open connections
+	hub.Close()
+	liveHub.Close()
 	if err := app.Shutdown(); err != nil {
 		logger.Log.Error("Server shutdown error", zap.Error(err))
 	}
@@ -96,13 +213,38 @@ func main() {
 	logger.Log.Info("Server stopped")
 }
 
-func customErrorHandler(c *fiber.Ctx, err error) error {
-	code := fiber.StatusInternalServerError
-	if e, ok := err.(*fiber.Error); ok {
-		code = e.Code
+// digestNotifier returns the configured birthday digest notifier, or nil
+// when the digest is disabled
+func digestNotifier(cfg *config.Config) digest.Notifier {
+	switch cfg.BirthdayDigest {
+	case "log":
+		return digest.NewLogNotifier(logger.Log)
+	case "webhook":
+		return digest.NewWebhookNotifier(cfg.BirthdayDigestWebhookURL, &http.Client{Timeout: 10 * time.Second})
+	case "smtp":
+		return digest.NewSMTPNotifier(cfg.SMTPAddr, cfg.SMTPFrom, cfg.SMTPTo)
+	default:
+		return nil
 	}
+}
 
-	return c.Status(code).JSON(fiber.Map{
-		"error": err.Error(),
-	})
-}
\ No newline at end of file
+// eventSinks returns the configured sinks user events are published to
+func eventSinks(cfg *config.Config) []outbox.Sink {
+	var sinks []outbox.Sink
+	for _, name := range cfg.EventSinks {
+		switch name {
+		case "log":
+			sinks = append(sinks, outbox.NewLogSink(logger.Log))
+		case "webhook":
+			sinks = append(sinks, outbox.NewWebhookSink(cfg.EventWebhookURL, &http.Client{Timeout: 10 * time.Second}))
+		}
+	}
+	return sinks
+}
+
+func customErrorHandler(c *fiber.Ctx, err error) error {
+	if handler.StatusCode(err) >= fiber.StatusInternalServerError {
+		logger.Log.Error("Unhandled error", zap.Error(err))
+	}
+	return handler.WriteError(c, err)
+}
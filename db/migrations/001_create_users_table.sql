@@ -1,3 +1,4 @@
+-- +migrate Up
 CREATE TABLE IF NOT EXISTS users (
     id SERIAL PRIMARY KEY,
     name TEXT NOT NULL,
@@ -6,4 +7,7 @@ CREATE TABLE IF NOT EXISTS users (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
-CREATE INDEX idx_users_id ON users(id);
+CREATE INDEX IF NOT EXISTS idx_users_id ON users(id);
+
+-- +migrate Down
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,9 @@
+-- +migrate Up
+CREATE INDEX IF NOT EXISTS idx_users_lower_name ON users (lower(name) text_pattern_ops);
+
+CREATE INDEX IF NOT EXISTS idx_users_dob ON users (dob);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_users_dob;
+
+DROP INDEX IF EXISTS idx_users_lower_name;
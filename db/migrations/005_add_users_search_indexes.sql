@@ -0,0 +1,11 @@
+-- +migrate Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_users_name_tsv ON users USING GIN (to_tsvector('simple', name));
+
+CREATE INDEX IF NOT EXISTS idx_users_name_trgm ON users USING GIN (name gin_trgm_ops);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_users_name_trgm;
+
+DROP INDEX IF EXISTS idx_users_name_tsv;
@@ -0,0 +1,7 @@
+-- +migrate Up
+CREATE INDEX IF NOT EXISTS idx_users_birthday
+    ON users ((EXTRACT(MONTH FROM dob)::int * 100 + EXTRACT(DAY FROM dob)::int))
+    WHERE deleted_at IS NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_users_birthday;
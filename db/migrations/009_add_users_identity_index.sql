@@ -0,0 +1,8 @@
+-- +migrate Up
+-- Matches the normalized name used by duplicate detection
+CREATE INDEX IF NOT EXISTS idx_users_identity
+    ON users ((lower(regexp_replace(btrim(name), '\s+', ' ', 'g'))), dob)
+    WHERE deleted_at IS NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_users_identity;
@@ -0,0 +1,11 @@
+-- +migrate Up
+-- A user merged into another is soft-deleted and keeps a pointer to the
+-- survivor, so its old ID can redirect there
+ALTER TABLE users ADD COLUMN IF NOT EXISTS merged_into INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_merged_into ON users(merged_into) WHERE merged_into IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_users_merged_into;
+
+ALTER TABLE users DROP COLUMN IF EXISTS merged_into;
@@ -0,0 +1,30 @@
+-- +migrate Up
+-- One row per change to a user, keyed by the version the change produced.
+-- There is no foreign key so history outlives purged users.
+CREATE TABLE IF NOT EXISTS user_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    action TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    request_id TEXT NOT NULL DEFAULT '',
+    changes JSONB NOT NULL DEFAULT '[]',
+    snapshot JSONB NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_history_user_version ON user_history (user_id, version);
+
+-- Existing users start their history with their current state
+INSERT INTO user_history (user_id, action, version, snapshot)
+SELECT id, 'baseline', version, jsonb_strip_nulls(jsonb_build_object(
+    'name', name,
+    'dob', dob,
+    'timezone', timezone,
+    'deleted_at', to_char(deleted_at, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+    'merged_into', merged_into))
+FROM users
+ON CONFLICT DO NOTHING;
+
+-- +migrate Down
+DROP TABLE IF EXISTS user_history;
@@ -0,0 +1,31 @@
+-- name: ClaimIdempotencyKey :execrows
+INSERT INTO idempotency_keys (key, fingerprint, expires_at)
+VALUES (sqlc.arg('key'), sqlc.arg('fingerprint'), CURRENT_TIMESTAMP + make_interval(secs => sqlc.arg('ttl_seconds')::float8))
+ON CONFLICT (key) DO UPDATE
+SET fingerprint = EXCLUDED.fingerprint,
+    status = NULL,
+    headers = '{}',
+    body = NULL,
+    created_at = CURRENT_TIMESTAMP,
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= CURRENT_TIMESTAMP;
+
+-- name: GetIdempotencyKey :one
+SELECT key, fingerprint, status, headers, body, created_at, expires_at
+FROM idempotency_keys
+WHERE key = $1
+  AND expires_at > CURRENT_TIMESTAMP;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status = $2, headers = $3, body = $4
+WHERE key = $1;
+
+-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE key = $1
+  AND status IS NULL;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= CURRENT_TIMESTAMP;
@@ -0,0 +1,72 @@
+-- name: CreateOutboxEvent :exec
+INSERT INTO outbox_events (event_type, user_id, payload)
+VALUES ($1, $2, $3);
+
+-- name: EnqueueInsertedUsersEvents :execrows
+-- Enqueues a UserCreated event for each of the given users from the
+-- creation RecordInsertedUsersHistory recorded
+INSERT INTO outbox_events (event_type, user_id, payload)
+SELECT 'UserCreated', h.user_id, jsonb_strip_nulls(jsonb_build_object(
+    'action', h.action,
+    'version', h.version,
+    'actor', NULLIF(h.actor, ''),
+    'request_id', NULLIF(h.request_id, ''),
+    'user', jsonb_build_object('id', h.user_id) || h.snapshot))
+    || jsonb_build_object('changes', h.changes)
+FROM user_history h
+WHERE h.user_id = ANY(sqlc.arg('ids')::int[])
+  AND h.action = 'create'
+ORDER BY h.user_id;
+
+-- name: ListDueOutboxEvents :many
+-- Lists the oldest unpublished event of each user, when it is due. Later
+-- events of a user wait until the ones before them are published.
+SELECT id, event_type, user_id, payload, created_at, attempts, last_error, next_attempt_at, published_at
+FROM outbox_events e
+WHERE published_at IS NULL
+  AND next_attempt_at <= clock_timestamp()
+  AND NOT EXISTS (
+      SELECT 1 FROM outbox_events earlier
+      WHERE earlier.user_id = e.user_id
+        AND earlier.published_at IS NULL
+        AND earlier.id < e.id)
+ORDER BY id
+LIMIT sqlc.arg('max_results');
+
+-- name: GetOutboxEvent :one
+SELECT id, event_type, user_id, payload, created_at, attempts, last_error, next_attempt_at, published_at
+FROM outbox_events
+WHERE id = $1;
+
+-- name: ListOutboxEventsAfter :many
+-- Lists events by id, published or not, for catching up after missed
+-- notifications
+SELECT id, event_type, user_id, payload, created_at, attempts, last_error, next_attempt_at, published_at
+FROM outbox_events
+WHERE id > sqlc.arg('after_id')
+ORDER BY id
+LIMIT sqlc.arg('max_results');
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox_events
+SET published_at = clock_timestamp(), attempts = attempts + 1, last_error = NULL
+WHERE id = $1;
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE outbox_events
+SET attempts = attempts + 1,
+    last_error = sqlc.arg('last_error'),
+    next_attempt_at = clock_timestamp() + make_interval(secs => sqlc.arg('retry_seconds')::float8)
+WHERE id = sqlc.arg('id');
+
+-- name: DeletePublishedOutboxEvents :execrows
+DELETE FROM outbox_events
+WHERE published_at < clock_timestamp() - make_interval(secs => sqlc.arg('retention_seconds')::float8);
+
+-- name: TryLockOutbox :one
+-- Makes the calling session the only relay publishing events, until
+-- UnlockOutbox or the session ends
+SELECT pg_try_advisory_lock(hashtext('outbox_events'));
+
+-- name: UnlockOutbox :exec
+SELECT pg_advisory_unlock(hashtext('outbox_events'));
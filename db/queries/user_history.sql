@@ -0,0 +1,33 @@
+-- name: CreateUserHistory :exec
+INSERT INTO user_history (user_id, action, version, actor, request_id, changes, snapshot)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListUserHistory :many
+SELECT id, user_id, action, version, actor, request_id, changes, snapshot, changed_at
+FROM user_history
+WHERE user_id = sqlc.arg('user_id')
+  AND (sqlc.narg('before_version')::int IS NULL OR version < sqlc.narg('before_version')::int)
+ORDER BY version DESC
+LIMIT sqlc.arg('max_results');
+
+-- name: GetUserHistoryAt :one
+SELECT id, user_id, action, version, actor, request_id, changes, snapshot, changed_at
+FROM user_history
+WHERE user_id = sqlc.arg('user_id')
+  AND changed_at <= sqlc.arg('as_of')
+ORDER BY version DESC
+LIMIT 1;
+
+-- name: RecordInsertedUsersHistory :execrows
+-- Records the creation of the given users, those bulk-loaded with
+-- CopyUsers
+INSERT INTO user_history (user_id, action, version, actor, request_id, changes, snapshot)
+SELECT id, 'create', version, sqlc.arg('actor'), sqlc.arg('request_id'),
+    jsonb_build_array(
+        jsonb_build_object('field', 'name', 'from', NULL, 'to', name),
+        jsonb_build_object('field', 'dob', 'from', NULL, 'to', dob))
+    || CASE WHEN timezone IS NULL THEN '[]'::jsonb
+            ELSE jsonb_build_array(jsonb_build_object('field', 'timezone', 'from', NULL, 'to', timezone)) END,
+    jsonb_strip_nulls(jsonb_build_object('name', name, 'dob', dob, 'timezone', timezone))
+FROM users
+WHERE id = ANY(sqlc.arg('ids')::int[]);
@@ -1,28 +1,128 @@
 -- name: CreateUser :one
-INSERT INTO users (name, dob)
-VALUES ($1, $2)
-RETURNING id, name, dob, created_at, updated_at;
+INSERT INTO users (name, dob, timezone)
+VALUES ($1, $2, $3)
+RETURNING id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into;
 
 -- name: GetUserByID :one
-SELECT id, name, dob, created_at, updated_at
+SELECT id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into
 FROM users
-WHERE id = $1;
-
--- name: ListUsers :many
-SELECT id, name, dob, created_at, updated_at
-FROM users
-ORDER BY id
-LIMIT $1 OFFSET $2;
+WHERE id = sqlc.arg('id')
+  AND (deleted_at IS NULL OR sqlc.arg('include_deleted')::bool);
 
 -- name: UpdateUser :one
 UPDATE users
-SET name = $1, dob = $2, updated_at = CURRENT_TIMESTAMP
-WHERE id = $3
-RETURNING id, name, dob, created_at, updated_at;
+SET name = sqlc.arg('name'), dob = sqlc.arg('dob'), timezone = sqlc.narg('timezone'), updated_at = CURRENT_TIMESTAMP, version = version + 1
+WHERE id = sqlc.arg('id')
+  AND deleted_at IS NULL
+  AND (sqlc.narg('expected_version')::int IS NULL OR version = sqlc.narg('expected_version')::int)
+RETURNING id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into;
+
+-- name: PatchUser :one
+UPDATE users
+SET name = COALESCE(sqlc.narg('name'), name),
+    dob = COALESCE(sqlc.narg('dob'), dob),
+    timezone = CASE WHEN sqlc.arg('set_timezone')::bool THEN sqlc.narg('timezone') ELSE timezone END,
+    updated_at = CURRENT_TIMESTAMP,
+    version = version + 1
+WHERE id = sqlc.arg('id')
+  AND deleted_at IS NULL
+  AND (sqlc.narg('expected_version')::int IS NULL OR version = sqlc.narg('expected_version')::int)
+RETURNING id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into;
 
--- name: DeleteUser :exec
+-- name: SoftDeleteUser :execrows
+UPDATE users
+SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP, version = version + 1
+WHERE id = sqlc.arg('id')
+  AND deleted_at IS NULL
+  AND (sqlc.narg('expected_version')::int IS NULL OR version = sqlc.narg('expected_version')::int);
+
+-- name: RestoreUser :one
+UPDATE users
+SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP, version = version + 1
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+  AND merged_into IS NULL
+RETURNING id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into;
+
+-- name: PurgeDeletedUsers :execrows
 DELETE FROM users
-WHERE id = $1;
+WHERE deleted_at IS NOT NULL
+  AND merged_into IS NULL
+  AND deleted_at < CURRENT_TIMESTAMP - make_interval(secs => sqlc.arg('retention_seconds')::float8);
+
+-- name: SetSimilarityThreshold :exec
+SELECT set_config('pg_trgm.similarity_threshold', sqlc.arg('threshold')::text, true);
+
+-- name: SearchUsers :many
+SELECT id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into,
+    (ts_rank_cd(to_tsvector('simple', name), websearch_to_tsquery('simple', sqlc.arg('query')))
+        + similarity(name, sqlc.arg('query')))::float8 AS rank
+FROM users
+WHERE deleted_at IS NULL
+  AND (to_tsvector('simple', name) @@ websearch_to_tsquery('simple', sqlc.arg('query'))
+       OR name % sqlc.arg('query'))
+ORDER BY rank DESC, id
+LIMIT sqlc.arg('max_results');
+
+-- name: FindUsersByNames :many
+SELECT id, lower(name)::text AS name_key, dob
+FROM users
+WHERE deleted_at IS NULL
+  AND lower(name) = ANY(sqlc.arg('names')::text[]);
 
--- name: CountUsers :one
-SELECT COUNT(*) FROM users;
+-- name: ListUsersByBirthdays :many
+SELECT id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into
+FROM users
+WHERE deleted_at IS NULL
+  AND (EXTRACT(MONTH FROM dob)::int * 100 + EXTRACT(DAY FROM dob)::int) = ANY(sqlc.arg('birthdays')::int[])
+ORDER BY id;
+
+-- name: LockUserIdentity :exec
+SELECT pg_advisory_xact_lock(hashtextextended(
+    lower(regexp_replace(btrim(sqlc.arg('name')::text), '\s+', ' ', 'g')) || '/' || sqlc.arg('dob')::date::text, 0));
+
+-- name: FindDuplicateUsers :many
+SELECT id, name, similarity(name, sqlc.arg('name'))::float8 AS similarity
+FROM users
+WHERE deleted_at IS NULL
+  AND dob = sqlc.arg('dob')
+  AND (lower(regexp_replace(btrim(name), '\s+', ' ', 'g')) = lower(regexp_replace(btrim(sqlc.arg('name')), '\s+', ' ', 'g'))
+       OR (sqlc.arg('fuzzy')::bool AND similarity(name, sqlc.arg('name')) >= sqlc.arg('min_similarity')::float8))
+ORDER BY similarity DESC, id
+LIMIT 10;
+
+-- name: ListDuplicatePairs :many
+SELECT a.id, a.name, a.dob, b.id AS duplicate_id, b.name AS duplicate_name,
+    similarity(a.name, b.name)::float8 AS similarity
+FROM users a
+JOIN users b ON b.dob = a.dob AND b.id > a.id AND b.deleted_at IS NULL
+WHERE a.deleted_at IS NULL
+  AND (lower(regexp_replace(btrim(a.name), '\s+', ' ', 'g')) = lower(regexp_replace(btrim(b.name), '\s+', ' ', 'g'))
+       OR (sqlc.arg('fuzzy')::bool AND similarity(a.name, b.name) >= sqlc.arg('min_similarity')::float8))
+ORDER BY a.id, b.id;
+
+-- name: MergeUser :execrows
+UPDATE users
+SET merged_into = sqlc.arg('merged_into'), deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP, version = version + 1
+WHERE id = sqlc.arg('id')
+  AND deleted_at IS NULL
+  AND version = sqlc.arg('expected_version');
+
+-- name: RepointMergedUsers :many
+UPDATE users
+SET merged_into = sqlc.arg('merged_into'), updated_at = CURRENT_TIMESTAMP, version = version + 1
+WHERE merged_into = sqlc.arg('id')
+RETURNING id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into;
+
+-- name: GetMergedInto :one
+SELECT merged_into::int AS merged_into
+FROM users
+WHERE id = $1
+  AND merged_into IS NOT NULL;
+
+-- name: LockUser :one
+SELECT id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into
+FROM users
+WHERE id = sqlc.arg('id')
+  AND (deleted_at IS NULL OR sqlc.arg('include_deleted')::bool)
+FOR UPDATE;
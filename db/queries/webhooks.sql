@@ -0,0 +1,105 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (url, event_types, secret, description, active)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, url, event_types, secret, description, active, created_at, updated_at;
+
+-- name: GetWebhookSubscription :one
+SELECT id, url, event_types, secret, description, active, created_at, updated_at
+FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: ListWebhookSubscriptions :many
+SELECT id, url, event_types, secret, description, active, created_at, updated_at
+FROM webhook_subscriptions
+ORDER BY id;
+
+-- name: UpdateWebhookSubscription :one
+-- Replaces a subscription, keeping its secret when secret is null
+UPDATE webhook_subscriptions
+SET url = sqlc.arg('url'),
+    event_types = sqlc.arg('event_types'),
+    secret = COALESCE(sqlc.narg('secret'), secret),
+    description = sqlc.arg('description'),
+    active = sqlc.arg('active'),
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = sqlc.arg('id')
+RETURNING id, url, event_types, secret, description, active, created_at, updated_at;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: EnqueueWebhookDeliveries :execrows
+-- Queues a delivery of an event to each active subscription to its type.
+-- An event enqueued again is not delivered twice.
+INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+SELECT id, sqlc.arg('event_id'), sqlc.arg('event_type'), sqlc.arg('payload')
+FROM webhook_subscriptions
+WHERE active
+  AND (cardinality(event_types) = 0 OR sqlc.arg('event_type') = ANY(event_types))
+ON CONFLICT (subscription_id, event_id) DO NOTHING;
+
+-- name: ClaimWebhookDeliveries :many
+-- Takes up to max_results due deliveries to active subscriptions and hides
+-- them from other dispatchers for lease_seconds. Deliveries to inactive
+-- subscriptions are skipped before the limit so they never fill a batch.
+UPDATE webhook_deliveries d
+SET next_attempt_at = clock_timestamp() + make_interval(secs => sqlc.arg('lease_seconds')::float8)
+FROM webhook_subscriptions s
+WHERE d.id IN (
+      SELECT due.id FROM webhook_deliveries due
+      JOIN webhook_subscriptions due_s ON due_s.id = due.subscription_id
+      WHERE due.status = 'pending'
+        AND due.next_attempt_at <= clock_timestamp()
+        AND due_s.active
+      ORDER BY due.next_attempt_at, due.id
+      LIMIT sqlc.arg('max_results')
+      FOR UPDATE OF due SKIP LOCKED)
+  AND s.id = d.subscription_id
+  AND s.active
+RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.attempts, s.url, s.secret;
+
+-- name: RecordWebhookAttempt :exec
+-- Logs an attempt to deliver and moves the delivery to status: delivered,
+-- dead, or pending again after retry_seconds
+WITH attempt AS (
+    INSERT INTO webhook_delivery_attempts (delivery_id, status_code, error, duration_ms)
+    VALUES (sqlc.arg('id'), sqlc.narg('status_code'), sqlc.narg('error'), sqlc.arg('duration_ms'))
+)
+UPDATE webhook_deliveries
+SET status = sqlc.arg('status'),
+    attempts = attempts + 1,
+    last_status_code = sqlc.narg('status_code'),
+    last_error = sqlc.narg('error'),
+    next_attempt_at = clock_timestamp() + make_interval(secs => sqlc.arg('retry_seconds')::float8),
+    delivered_at = CASE WHEN sqlc.arg('status') = 'delivered' THEN clock_timestamp() END
+WHERE id = sqlc.arg('id');
+
+-- name: ListWebhookDeliveries :many
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at
+FROM webhook_deliveries
+WHERE (sqlc.narg('subscription_id')::int IS NULL OR subscription_id = sqlc.narg('subscription_id')::int)
+  AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status')::text)
+  AND (sqlc.narg('before_id')::bigint IS NULL OR id < sqlc.narg('before_id')::bigint)
+ORDER BY id DESC
+LIMIT sqlc.arg('max_results');
+
+-- name: GetWebhookDelivery :one
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at
+FROM webhook_deliveries
+WHERE id = $1
+  AND subscription_id = $2;
+
+-- name: ListWebhookDeliveryAttempts :many
+SELECT id, delivery_id, attempted_at, status_code, error, duration_ms
+FROM webhook_delivery_attempts
+WHERE delivery_id = $1
+ORDER BY id;
+
+-- name: RedeliverWebhookDelivery :one
+-- Queues a delivery again with a fresh set of retries, whatever its status
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = clock_timestamp()
+WHERE id = $1
+  AND subscription_id = $2
+RETURNING id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at;
@@ -6,13 +6,87 @@ package sqlc
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
+type IdempotencyKey struct {
+	Key         string          `json:"key"`
+	Fingerprint string          `json:"fingerprint"`
+	Status      sql.NullInt32   `json:"status"`
+	Headers     json.RawMessage `json:"headers"`
+	Body        []byte          `json:"body"`
+	CreatedAt   time.Time       `json:"created_at"`
+	ExpiresAt   time.Time       `json:"expires_at"`
+}
+
+type OutboxEvent struct {
+	ID            int64           `json:"id"`
+	EventType     string          `json:"event_type"`
+	UserID        int32           `json:"user_id"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Attempts      int32           `json:"attempts"`
+	LastError     sql.NullString  `json:"last_error"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	PublishedAt   sql.NullTime    `json:"published_at"`
+}
+
+type UserHistory struct {
+	ID        int64           `json:"id"`
+	UserID    int32           `json:"user_id"`
+	Action    string          `json:"action"`
+	Version   int32           `json:"version"`
+	Actor     string          `json:"actor"`
+	RequestID string          `json:"request_id"`
+	Changes   json.RawMessage `json:"changes"`
+	Snapshot  json.RawMessage `json:"snapshot"`
+	ChangedAt time.Time       `json:"changed_at"`
+}
+
 type User struct {
-	ID        int32        `json:"id"`
-	Name      string       `json:"name"`
-	Dob       time.Time    `json:"dob"`
-	CreatedAt sql.NullTime `json:"created_at"`
-	UpdatedAt sql.NullTime `json:"updated_at"`
+	ID         int32          `json:"id"`
+	Name       string         `json:"name"`
+	Dob        time.Time      `json:"dob"`
+	CreatedAt  sql.NullTime   `json:"created_at"`
+	UpdatedAt  sql.NullTime   `json:"updated_at"`
+	Version    int32          `json:"version"`
+	DeletedAt  sql.NullTime   `json:"deleted_at"`
+	Timezone   sql.NullString `json:"timezone"`
+	MergedInto sql.NullInt32  `json:"merged_into"`
+}
+
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int32           `json:"subscription_id"`
+	EventID        int64           `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastStatusCode sql.NullInt32   `json:"last_status_code"`
+	LastError      sql.NullString  `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    sql.NullTime    `json:"delivered_at"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID          int64          `json:"id"`
+	DeliveryID  int64          `json:"delivery_id"`
+	AttemptedAt time.Time      `json:"attempted_at"`
+	StatusCode  sql.NullInt32  `json:"status_code"`
+	Error       sql.NullString `json:"error"`
+	DurationMs  int32          `json:"duration_ms"`
+}
+
+type WebhookSubscription struct {
+	ID          int32     `json:"id"`
+	Url         string    `json:"url"`
+	EventTypes  []string  `json:"event_types"`
+	Secret      string    `json:"secret"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -7,33 +7,26 @@ package sqlc
 
 import (
 	"context"
+	"database/sql"
 	"time"
-)
-
-const countUsers = `-- name: CountUsers :one
-SELECT COUNT(*) FROM users
-`
 
-func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countUsers)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
-}
+	"github.com/lib/pq"
+)
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (name, dob)
-VALUES ($1, $2)
-RETURNING id, name, dob, created_at, updated_at
+INSERT INTO users (name, dob, timezone)
+VALUES ($1, $2, $3)
+RETURNING id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into
 `
 
 type CreateUserParams struct {
-	Name string    `json:"name"`
-	Dob  time.Time `json:"dob"`
+	Name     string         `json:"name"`
+	Dob      time.Time      `json:"dob"`
+	Timezone sql.NullString `json:"timezone"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.Name, arg.Dob)
+	row := q.db.QueryRowContext(ctx, createUser, arg.Name, arg.Dob, arg.Timezone)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -41,28 +34,130 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Dob,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Timezone,
+		&i.MergedInto,
 	)
 	return i, err
 }
 
-const deleteUser = `-- name: DeleteUser :exec
-DELETE FROM users
+const findDuplicateUsers = `-- name: FindDuplicateUsers :many
+SELECT id, name, similarity(name, $1)::float8 AS similarity
+FROM users
+WHERE deleted_at IS NULL
+  AND dob = $2
+  AND (lower(regexp_replace(btrim(name), '\s+', ' ', 'g')) = lower(regexp_replace(btrim($1), '\s+', ' ', 'g'))
+       OR ($3::bool AND similarity(name, $1) >= $4::float8))
+ORDER BY similarity DESC, id
+LIMIT 10
+`
+
+type FindDuplicateUsersParams struct {
+	Name          string    `json:"name"`
+	Dob           time.Time `json:"dob"`
+	Fuzzy         bool      `json:"fuzzy"`
+	MinSimilarity float64   `json:"min_similarity"`
+}
+
+type FindDuplicateUsersRow struct {
+	ID         int32   `json:"id"`
+	Name       string  `json:"name"`
+	Similarity float64 `json:"similarity"`
+}
+
+func (q *Queries) FindDuplicateUsers(ctx context.Context, arg FindDuplicateUsersParams) ([]FindDuplicateUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, findDuplicateUsers,
+		arg.Name,
+		arg.Dob,
+		arg.Fuzzy,
+		arg.MinSimilarity,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FindDuplicateUsersRow{}
+	for rows.Next() {
+		var i FindDuplicateUsersRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.Similarity); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const findUsersByNames = `-- name: FindUsersByNames :many
+SELECT id, lower(name)::text AS name_key, dob
+FROM users
+WHERE deleted_at IS NULL
+  AND lower(name) = ANY($1::text[])
+`
+
+type FindUsersByNamesRow struct {
+	ID      int32     `json:"id"`
+	NameKey string    `json:"name_key"`
+	Dob     time.Time `json:"dob"`
+}
+
+func (q *Queries) FindUsersByNames(ctx context.Context, names []string) ([]FindUsersByNamesRow, error) {
+	rows, err := q.db.QueryContext(ctx, findUsersByNames, pq.Array(names))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []FindUsersByNamesRow{}
+	for rows.Next() {
+		var i FindUsersByNamesRow
+		if err := rows.Scan(&i.ID, &i.NameKey, &i.Dob); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getMergedInto = `-- name: GetMergedInto :one
+SELECT merged_into::int AS merged_into
+FROM users
 WHERE id = $1
+  AND merged_into IS NOT NULL
 `
 
-func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
-	_, err := q.db.ExecContext(ctx, deleteUser, id)
-	return err
+func (q *Queries) GetMergedInto(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, getMergedInto, id)
+	var merged_into int32
+	err := row.Scan(&merged_into)
+	return merged_into, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, name, dob, created_at, updated_at
+SELECT id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into
 FROM users
 WHERE id = $1
+  AND (deleted_at IS NULL OR $2::bool)
 `
 
-func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserByID, id)
+type GetUserByIDParams struct {
+	ID             int32 `json:"id"`
+	IncludeDeleted bool  `json:"include_deleted"`
+}
+
+func (q *Queries) GetUserByID(ctx context.Context, arg GetUserByIDParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, arg.ID, arg.IncludeDeleted)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -70,24 +165,252 @@ func (q *Queries) GetUserByID(ctx context.Context, id int32) (User, error) {
 		&i.Dob,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Timezone,
+		&i.MergedInto,
 	)
 	return i, err
 }
 
-const listUsers = `-- name: ListUsers :many
-SELECT id, name, dob, created_at, updated_at
+const listDuplicatePairs = `-- name: ListDuplicatePairs :many
+SELECT a.id, a.name, a.dob, b.id AS duplicate_id, b.name AS duplicate_name,
+    similarity(a.name, b.name)::float8 AS similarity
+FROM users a
+JOIN users b ON b.dob = a.dob AND b.id > a.id AND b.deleted_at IS NULL
+WHERE a.deleted_at IS NULL
+  AND (lower(regexp_replace(btrim(a.name), '\s+', ' ', 'g')) = lower(regexp_replace(btrim(b.name), '\s+', ' ', 'g'))
+       OR ($1::bool AND similarity(a.name, b.name) >= $2::float8))
+ORDER BY a.id, b.id
+`
+
+type ListDuplicatePairsParams struct {
+	Fuzzy         bool    `json:"fuzzy"`
+	MinSimilarity float64 `json:"min_similarity"`
+}
+
+type ListDuplicatePairsRow struct {
+	ID            int32     `json:"id"`
+	Name          string    `json:"name"`
+	Dob           time.Time `json:"dob"`
+	DuplicateID   int32     `json:"duplicate_id"`
+	DuplicateName string    `json:"duplicate_name"`
+	Similarity    float64   `json:"similarity"`
+}
+
+func (q *Queries) ListDuplicatePairs(ctx context.Context, arg ListDuplicatePairsParams) ([]ListDuplicatePairsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDuplicatePairs, arg.Fuzzy, arg.MinSimilarity)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListDuplicatePairsRow{}
+	for rows.Next() {
+		var i ListDuplicatePairsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Dob,
+			&i.DuplicateID,
+			&i.DuplicateName,
+			&i.Similarity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersByBirthdays = `-- name: ListUsersByBirthdays :many
+SELECT id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into
 FROM users
+WHERE deleted_at IS NULL
+  AND (EXTRACT(MONTH FROM dob)::int * 100 + EXTRACT(DAY FROM dob)::int) = ANY($1::int[])
 ORDER BY id
-LIMIT $1 OFFSET $2
 `
 
-type ListUsersParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+func (q *Queries) ListUsersByBirthdays(ctx context.Context, birthdays []int32) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersByBirthdays, pq.Array(birthdays))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []User{}
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Dob,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			&i.Timezone,
+			&i.MergedInto,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockUser = `-- name: LockUser :one
+SELECT id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into
+FROM users
+WHERE id = $1
+  AND (deleted_at IS NULL OR $2::bool)
+FOR UPDATE
+`
+
+type LockUserParams struct {
+	ID             int32 `json:"id"`
+	IncludeDeleted bool  `json:"include_deleted"`
+}
+
+func (q *Queries) LockUser(ctx context.Context, arg LockUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, lockUser, arg.ID, arg.IncludeDeleted)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Dob,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Timezone,
+		&i.MergedInto,
+	)
+	return i, err
+}
+
+const lockUserIdentity = `-- name: LockUserIdentity :exec
+SELECT pg_advisory_xact_lock(hashtextextended(
+    lower(regexp_replace(btrim($1::text), '\s+', ' ', 'g')) || '/' || $2::date::text, 0))
+`
+
+type LockUserIdentityParams struct {
+	Name string    `json:"name"`
+	Dob  time.Time `json:"dob"`
 }
 
-func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
-	rows, err := q.db.QueryContext(ctx, listUsers, arg.Limit, arg.Offset)
+func (q *Queries) LockUserIdentity(ctx context.Context, arg LockUserIdentityParams) error {
+	_, err := q.db.ExecContext(ctx, lockUserIdentity, arg.Name, arg.Dob)
+	return err
+}
+
+const mergeUser = `-- name: MergeUser :execrows
+UPDATE users
+SET merged_into = $1, deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP, version = version + 1
+WHERE id = $2
+  AND deleted_at IS NULL
+  AND version = $3
+`
+
+type MergeUserParams struct {
+	MergedInto      sql.NullInt32 `json:"merged_into"`
+	ID              int32         `json:"id"`
+	ExpectedVersion int32         `json:"expected_version"`
+}
+
+func (q *Queries) MergeUser(ctx context.Context, arg MergeUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, mergeUser, arg.MergedInto, arg.ID, arg.ExpectedVersion)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const patchUser = `-- name: PatchUser :one
+UPDATE users
+SET name = COALESCE($1, name),
+    dob = COALESCE($2, dob),
+    timezone = CASE WHEN $3::bool THEN $4 ELSE timezone END,
+    updated_at = CURRENT_TIMESTAMP,
+    version = version + 1
+WHERE id = $5
+  AND deleted_at IS NULL
+  AND ($6::int IS NULL OR version = $6::int)
+RETURNING id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into
+`
+
+type PatchUserParams struct {
+	Name            sql.NullString `json:"name"`
+	Dob             sql.NullTime   `json:"dob"`
+	SetTimezone     bool           `json:"set_timezone"`
+	Timezone        sql.NullString `json:"timezone"`
+	ID              int32          `json:"id"`
+	ExpectedVersion sql.NullInt32  `json:"expected_version"`
+}
+
+func (q *Queries) PatchUser(ctx context.Context, arg PatchUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, patchUser,
+		arg.Name,
+		arg.Dob,
+		arg.SetTimezone,
+		arg.Timezone,
+		arg.ID,
+		arg.ExpectedVersion,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Dob,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Timezone,
+		&i.MergedInto,
+	)
+	return i, err
+}
+
+const purgeDeletedUsers = `-- name: PurgeDeletedUsers :execrows
+DELETE FROM users
+WHERE deleted_at IS NOT NULL
+  AND merged_into IS NULL
+  AND deleted_at < CURRENT_TIMESTAMP - make_interval(secs => $1::float8)
+`
+
+func (q *Queries) PurgeDeletedUsers(ctx context.Context, retentionSeconds float64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedUsers, retentionSeconds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const repointMergedUsers = `-- name: RepointMergedUsers :many
+UPDATE users
+SET merged_into = $1, updated_at = CURRENT_TIMESTAMP, version = version + 1
+WHERE merged_into = $2
+RETURNING id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into
+`
+
+type RepointMergedUsersParams struct {
+	MergedInto sql.NullInt32 `json:"merged_into"`
+	ID         sql.NullInt32 `json:"id"`
+}
+
+func (q *Queries) RepointMergedUsers(ctx context.Context, arg RepointMergedUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, repointMergedUsers, arg.MergedInto, arg.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -101,6 +424,100 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.Dob,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			&i.Timezone,
+			&i.MergedInto,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreUser = `-- name: RestoreUser :one
+UPDATE users
+SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP, version = version + 1
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+  AND merged_into IS NULL
+RETURNING id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into
+`
+
+func (q *Queries) RestoreUser(ctx context.Context, id int32) (User, error) {
+	row := q.db.QueryRowContext(ctx, restoreUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Dob,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Timezone,
+		&i.MergedInto,
+	)
+	return i, err
+}
+
+const searchUsers = `-- name: SearchUsers :many
+SELECT id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into,
+    (ts_rank_cd(to_tsvector('simple', name), websearch_to_tsquery('simple', $1))
+        + similarity(name, $1))::float8 AS rank
+FROM users
+WHERE deleted_at IS NULL
+  AND (to_tsvector('simple', name) @@ websearch_to_tsquery('simple', $1)
+       OR name % $1)
+ORDER BY rank DESC, id
+LIMIT $2
+`
+
+type SearchUsersParams struct {
+	Query      string `json:"query"`
+	MaxResults int32  `json:"max_results"`
+}
+
+type SearchUsersRow struct {
+	ID         int32          `json:"id"`
+	Name       string         `json:"name"`
+	Dob        time.Time      `json:"dob"`
+	CreatedAt  sql.NullTime   `json:"created_at"`
+	UpdatedAt  sql.NullTime   `json:"updated_at"`
+	Version    int32          `json:"version"`
+	DeletedAt  sql.NullTime   `json:"deleted_at"`
+	Timezone   sql.NullString `json:"timezone"`
+	MergedInto sql.NullInt32  `json:"merged_into"`
+	Rank       float64        `json:"rank"`
+}
+
+func (q *Queries) SearchUsers(ctx context.Context, arg SearchUsersParams) ([]SearchUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchUsers, arg.Query, arg.MaxResults)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchUsersRow{}
+	for rows.Next() {
+		var i SearchUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Dob,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Version,
+			&i.DeletedAt,
+			&i.Timezone,
+			&i.MergedInto,
+			&i.Rank,
 		); err != nil {
 			return nil, err
 		}
@@ -115,21 +532,61 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return items, nil
 }
 
+const setSimilarityThreshold = `-- name: SetSimilarityThreshold :exec
+SELECT set_config('pg_trgm.similarity_threshold', $1::text, true)
+`
+
+func (q *Queries) SetSimilarityThreshold(ctx context.Context, threshold string) error {
+	_, err := q.db.ExecContext(ctx, setSimilarityThreshold, threshold)
+	return err
+}
+
+const softDeleteUser = `-- name: SoftDeleteUser :execrows
+UPDATE users
+SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP, version = version + 1
+WHERE id = $1
+  AND deleted_at IS NULL
+  AND ($2::int IS NULL OR version = $2::int)
+`
+
+type SoftDeleteUserParams struct {
+	ID              int32         `json:"id"`
+	ExpectedVersion sql.NullInt32 `json:"expected_version"`
+}
+
+func (q *Queries) SoftDeleteUser(ctx context.Context, arg SoftDeleteUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, softDeleteUser, arg.ID, arg.ExpectedVersion)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
-SET name = $1, dob = $2, updated_at = CURRENT_TIMESTAMP
-WHERE id = $3
-RETURNING id, name, dob, created_at, updated_at
+SET name = $1, dob = $2, timezone = $3, updated_at = CURRENT_TIMESTAMP, version = version + 1
+WHERE id = $4
+  AND deleted_at IS NULL
+  AND ($5::int IS NULL OR version = $5::int)
+RETURNING id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into
 `
 
 type UpdateUserParams struct {
-	Name string    `json:"name"`
-	Dob  time.Time `json:"dob"`
-	ID   int32     `json:"id"`
+	Name            string         `json:"name"`
+	Dob             time.Time      `json:"dob"`
+	Timezone        sql.NullString `json:"timezone"`
+	ID              int32          `json:"id"`
+	ExpectedVersion sql.NullInt32  `json:"expected_version"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUser, arg.Name, arg.Dob, arg.ID)
+	row := q.db.QueryRowContext(ctx, updateUser,
+		arg.Name,
+		arg.Dob,
+		arg.Timezone,
+		arg.ID,
+		arg.ExpectedVersion,
+	)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -137,6 +594,10 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Dob,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
+		&i.DeletedAt,
+		&i.Timezone,
+		&i.MergedInto,
 	)
 	return i, err
 }
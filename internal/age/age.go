@@ -0,0 +1,136 @@
+// internal/age/age.go
+package age
+
+import (
+	"fmt"
+	"time"
+)
+
+// Dates are compared by calendar date only. A date of birth whose day does
+// not exist in some month (the 29th-31st) has its anniversary on the first
+// day of the following month there, so a Feb 29 birthday falls on Mar 1 in
+// common years. This matches models.CalculateAgeAt, which only counts a
+// year once the month and day have been reached.
+
+// Detail is everything derived from a date of birth on a given day
+type Detail struct {
+	Years  int
+	Months int
+	Days   int
+
+	// NextBirthday is today when the birthday is today
+	NextBirthday      time.Time
+	DaysUntilBirthday int
+
+	ZodiacSign string
+	// BirthWeek is the ISO 8601 week of the date of birth, e.g. 1990-W19
+	BirthWeek string
+}
+
+// Compute derives the Detail of dob on the calendar date of now in now's
+// location
+func Compute(dob, now time.Time) Detail {
+	today := date(now)
+	years, months, days := Between(dob, today)
+	next := NextBirthday(dob, today)
+	return Detail{
+		Years:             years,
+		Months:            months,
+		Days:              days,
+		NextBirthday:      next,
+		DaysUntilBirthday: daysBetween(today, next),
+		ZodiacSign:        ZodiacSign(dob),
+		BirthWeek:         BirthWeek(dob),
+	}
+}
+
+// Between returns the whole years, months and days elapsed from dob to the
+// calendar date of now. It returns zeros when now is before dob.
+func Between(dob, now time.Time) (years, months, days int) {
+	dob, today := date(dob), date(now)
+	if today.Before(dob) {
+		return 0, 0, 0
+	}
+
+	total := (today.Year()-dob.Year())*12 + int(today.Month()-dob.Month())
+	if anniversary(dob, total).After(today) {
+		total--
+	}
+	return total / 12, total % 12, daysBetween(anniversary(dob, total), today)
+}
+
+// Birthday returns the day dob's birthday is observed on in year
+func Birthday(dob time.Time, year int) time.Time {
+	return anniversary(date(dob), (year-dob.Year())*12)
+}
+
+// NextBirthday returns the first birthday on or after the calendar date of
+// now
+func NextBirthday(dob, now time.Time) time.Time {
+	today := date(now)
+	next := Birthday(dob, today.Year())
+	if next.Before(today) {
+		next = Birthday(dob, today.Year()+1)
+	}
+	return next
+}
+
+// BirthWeek formats the ISO 8601 week of dob
+func BirthWeek(dob time.Time) string {
+	year, week := dob.ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", year, week)
+}
+
+// signs lists each western zodiac sign with the last day it covers, in
+// calendar order from Capricorn's end in January
+var signs = []struct {
+	month time.Month
+	day   int
+	name  string
+}{
+	{time.January, 19, "capricorn"},
+	{time.February, 18, "aquarius"},
+	{time.March, 20, "pisces"},
+	{time.April, 19, "aries"},
+	{time.May, 20, "taurus"},
+	{time.June, 20, "gemini"},
+	{time.July, 22, "cancer"},
+	{time.August, 22, "leo"},
+	{time.September, 22, "virgo"},
+	{time.October, 22, "libra"},
+	{time.November, 21, "scorpio"},
+	{time.December, 21, "sagittarius"},
+}
+
+// ZodiacSign returns the lowercase western zodiac sign of dob
+func ZodiacSign(dob time.Time) string {
+	for _, s := range signs {
+		if dob.Month() < s.month || (dob.Month() == s.month && dob.Day() <= s.day) {
+			return s.name
+		}
+	}
+	return "capricorn"
+}
+
+// anniversary returns the date months after dob, moved to the first of the
+// next month when that month is too short for dob's day
+func anniversary(dob time.Time, months int) time.Time {
+	first := time.Date(dob.Year(), dob.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	if dob.Day() > daysIn(first) {
+		return first.AddDate(0, 1, 0)
+	}
+	return first.AddDate(0, 0, dob.Day()-1)
+}
+
+func daysIn(first time.Time) int {
+	return first.AddDate(0, 1, -1).Day()
+}
+
+// date drops the time of day and location of t, keeping its calendar date
+func date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
@@ -0,0 +1,121 @@
+// internal/age/age_test.go
+package age
+
+import (
+	"testing"
+	"time"
+)
+
+func mustDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name                string
+		dob, today          string
+		years, months, days int
+	}{
+		{"birthday today", "1990-05-10", "2024-05-10", 34, 0, 0},
+		{"day before birthday", "1990-05-10", "2024-05-09", 33, 11, 29},
+		{"mid month", "1990-05-10", "2024-08-25", 34, 3, 15},
+		{"newborn", "2024-05-10", "2024-05-10", 0, 0, 0},
+		{"not yet born", "2024-05-10", "2024-05-09", 0, 0, 0},
+		{"leap day in common year before Mar 1", "2000-02-29", "2001-02-28", 0, 11, 30},
+		{"leap day in common year on Mar 1", "2000-02-29", "2001-03-01", 1, 0, 0},
+		{"leap day in leap year", "2000-02-29", "2004-02-29", 4, 0, 0},
+		{"month end into short month", "1990-01-31", "1990-03-01", 0, 1, 0},
+		{"month end day before", "1990-01-31", "1990-02-28", 0, 0, 28},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			y, m, d := Between(mustDate(t, tt.dob), mustDate(t, tt.today))
+			if y != tt.years || m != tt.months || d != tt.days {
+				t.Errorf("Between() = %dy %dm %dd, want %dy %dm %dd", y, m, d, tt.years, tt.months, tt.days)
+			}
+		})
+	}
+}
+
+func TestNextBirthday(t *testing.T) {
+	tests := []struct {
+		name      string
+		dob       string
+		today     string
+		want      string
+		wantUntil int
+	}{
+		{"later this year", "1990-05-10", "2024-05-01", "2024-05-10", 9},
+		{"today", "1990-05-10", "2024-05-10", "2024-05-10", 0},
+		{"next year", "1990-05-10", "2024-05-11", "2025-05-10", 364},
+		{"leap day in leap year", "2000-02-29", "2024-02-01", "2024-02-29", 28},
+		{"leap day in common year", "2000-02-29", "2025-02-01", "2025-03-01", 28},
+		{"leap day after Mar 1", "2000-02-29", "2023-03-02", "2024-02-29", 364},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Compute(mustDate(t, tt.dob), mustDate(t, tt.today))
+			if got := d.NextBirthday.Format("2006-01-02"); got != tt.want {
+				t.Errorf("NextBirthday = %s, want %s", got, tt.want)
+			}
+			if d.DaysUntilBirthday != tt.wantUntil {
+				t.Errorf("DaysUntilBirthday = %d, want %d", d.DaysUntilBirthday, tt.wantUntil)
+			}
+		})
+	}
+}
+
+func TestCompute_UsesCalendarDateOfNow(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	dob := mustDate(t, "1990-05-10")
+	instant := time.Date(2024, 5, 9, 20, 0, 0, 0, time.UTC)
+
+	if d := Compute(dob, instant); d.Years != 33 || d.DaysUntilBirthday != 1 {
+		t.Errorf("UTC: Years = %d, DaysUntilBirthday = %d, want 33, 1", d.Years, d.DaysUntilBirthday)
+	}
+	if d := Compute(dob, instant.In(kolkata)); d.Years != 34 || d.DaysUntilBirthday != 0 {
+		t.Errorf("Asia/Kolkata: Years = %d, DaysUntilBirthday = %d, want 34, 0", d.Years, d.DaysUntilBirthday)
+	}
+}
+
+func TestZodiacSign(t *testing.T) {
+	tests := map[string]string{
+		"1990-01-01": "capricorn",
+		"1990-01-19": "capricorn",
+		"1990-01-20": "aquarius",
+		"2000-02-29": "pisces",
+		"1990-03-21": "aries",
+		"1990-05-10": "taurus",
+		"1990-12-21": "sagittarius",
+		"1990-12-22": "capricorn",
+	}
+	for dob, want := range tests {
+		if got := ZodiacSign(mustDate(t, dob)); got != want {
+			t.Errorf("ZodiacSign(%s) = %s, want %s", dob, got, want)
+		}
+	}
+}
+
+func TestBirthWeek(t *testing.T) {
+	tests := map[string]string{
+		"1990-05-10": "1990-W19",
+		// Jan 1 2021 is a Friday, so it belongs to the last week of 2020
+		"2021-01-01": "2020-W53",
+		"2024-12-30": "2025-W01",
+	}
+	for dob, want := range tests {
+		if got := BirthWeek(mustDate(t, dob)); got != want {
+			t.Errorf("BirthWeek(%s) = %s, want %s", dob, got, want)
+		}
+	}
+}
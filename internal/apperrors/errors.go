@@ -0,0 +1,88 @@
+// internal/apperrors/errors.go
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel kinds; match them with errors.Is
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrValidation         = errors.New("validation failed")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrUnavailable        = errors.New("service unavailable")
+	ErrAborted            = errors.New("aborted")
+	ErrMoved              = errors.New("moved permanently")
+)
+
+// FieldError describes a single invalid input field
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Error is a domain error carrying its kind, a client-safe message and the
+// underlying cause, if any. Details are extra client-safe members, such as
+// the id of a conflicting resource.
+type Error struct {
+	Kind    error
+	Message string
+	Fields  []FieldError
+	Details map[string]any
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// With adds a detail member to e and returns it
+func (e *Error) With(key string, value any) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]any)
+	}
+	e.Details[key] = value
+	return e
+}
+
+func NotFound(format string, args ...any) *Error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+func Conflict(format string, args ...any) *Error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+func Validation(message string, fields ...FieldError) *Error {
+	return &Error{Kind: ErrValidation, Message: message, Fields: fields}
+}
+
+func PreconditionFailed(format string, args ...any) *Error {
+	return &Error{Kind: ErrPreconditionFailed, Message: fmt.Sprintf(format, args...)}
+}
+
+func Unavailable(err error, format string, args ...any) *Error {
+	return &Error{Kind: ErrUnavailable, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+func Aborted(format string, args ...any) *Error {
+	return &Error{Kind: ErrAborted, Message: fmt.Sprintf(format, args...)}
+}
+
+func Moved(format string, args ...any) *Error {
+	return &Error{Kind: ErrMoved, Message: fmt.Sprintf(format, args...)}
+}
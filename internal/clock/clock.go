@@ -0,0 +1,68 @@
+// internal/clock/clock.go
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the current time. Code that depends on "now", such as age
+// calculation, takes a Clock so tests can pin the date.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+// System returns the wall clock
+func System() Clock {
+	return systemClock{}
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+type fixedClock time.Time
+
+// Fixed returns a Clock stopped at t for good, for computing values as of a
+// past time
+func Fixed(t time.Time) Clock {
+	return fixedClock(t)
+}
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// Fake is a Clock that only moves when told to. It is safe for concurrent
+// use.
+type Fake struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFake returns a Fake clock stopped at now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Set moves the clock to now
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+// Advance moves the clock forward by d
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
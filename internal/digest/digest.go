@@ -0,0 +1,122 @@
+// internal/digest/digest.go
+package digest
+
+import (
+	"context"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/clock"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// Digest lists the users whose birthday is on Date or in the Within days
+// after it, soonest first
+type Digest struct {
+	Date   string                `json:"date"`
+	Within int                   `json:"within"`
+	Users  []models.UserResponse `json:"users"`
+}
+
+// Notifier delivers a digest somewhere people will read it
+type Notifier interface {
+	Notify(ctx context.Context, d Digest) error
+}
+
+// Source finds upcoming birthdays; service.UserService is one
+type Source interface {
+	UpcomingBirthdays(ctx context.Context, within int) ([]models.UserResponse, error)
+}
+
+// Config holds when and what the digest job sends
+type Config struct {
+	// At is the time of day, as an offset from midnight in Location, the
+	// digest is sent at
+	At       time.Duration
+	Location *time.Location
+
+	// Within is how many days after today the digest looks ahead; 0 lists
+	// only today's birthdays
+	Within int
+
+	// Clock tells the time; the system clock when nil
+	Clock clock.Clock
+}
+
+// Job sends a birthday digest once a day. Days without birthdays are
+// skipped, and a day whose send time passed while the process was down is
+// not caught up on.
+type Job struct {
+	source   Source
+	notifier Notifier
+	config   Config
+	logger   *zap.Logger
+}
+
+func NewJob(source Source, notifier Notifier, config Config, logger *zap.Logger) *Job {
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	if config.Clock == nil {
+		config.Clock = clock.System()
+	}
+	return &Job{
+		source:   source,
+		notifier: notifier,
+		config:   config,
+		logger:   logger,
+	}
+}
+
+// Run sends a digest every day at the configured time until ctx is done
+func (j *Job) Run(ctx context.Context) {
+	for {
+		now := j.config.Clock.Now().In(j.config.Location)
+		next := nextRun(now, j.config.At)
+		j.logger.Info("Next birthday digest scheduled", zap.Time("at", next))
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if err := j.RunOnce(ctx); err != nil {
+			j.logger.Error("Failed to send birthday digest", zap.Error(err))
+		}
+	}
+}
+
+// RunOnce sends the digest for the current day in the configured location
+func (j *Job) RunOnce(ctx context.Context) error {
+	ctx = service.WithLocation(ctx, j.config.Location)
+	users, err := j.source.UpcomingBirthdays(ctx, j.config.Within)
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	return j.notifier.Notify(ctx, Digest{
+		Date:   j.config.Clock.Now().In(j.config.Location).Format("2006-01-02"),
+		Within: j.config.Within,
+		Users:  users,
+	})
+}
+
+// nextRun returns the first time after now whose wall clock time in now's
+// location is the offset at after midnight. Days are stepped by date rather
+// than 24 hours so the job keeps its wall clock time across DST changes.
+func nextRun(now time.Time, at time.Duration) time.Time {
+	for day := 0; ; day++ {
+		run := time.Date(now.Year(), now.Month(), now.Day()+day,
+			int(at/time.Hour), int(at%time.Hour/time.Minute), 0, 0, now.Location())
+		if run.After(now) {
+			return run
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// internal/digest/digest_test.go
+package digest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/clock"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestNextRun(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	at := 8 * time.Hour
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{
+			name: "Later today",
+			now:  time.Date(2024, 5, 9, 7, 59, 0, 0, time.UTC),
+			want: time.Date(2024, 5, 9, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Exactly at the send time",
+			now:  time.Date(2024, 5, 9, 8, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 5, 10, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Across the end of a month",
+			now:  time.Date(2024, 5, 31, 9, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "Keeps the wall clock time across DST",
+			now:  time.Date(2024, 3, 9, 9, 0, 0, 0, newYork),
+			want: time.Date(2024, 3, 10, 8, 0, 0, 0, newYork),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextRun(tt.now, at); !got.Equal(tt.want) {
+				t.Errorf("nextRun() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeSource struct {
+	users  []models.UserResponse
+	within int
+}
+
+func (s *fakeSource) UpcomingBirthdays(_ context.Context, within int) ([]models.UserResponse, error) {
+	s.within = within
+	return s.users, nil
+}
+
+type recordingNotifier struct {
+	digests []Digest
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, d Digest) error {
+	n.digests = append(n.digests, d)
+	return nil
+}
+
+func TestJob_RunOnce(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	// Still the 9th in UTC, already the 10th in Tokyo
+	now := clock.NewFake(time.Date(2024, 5, 9, 16, 0, 0, 0, time.UTC))
+
+	source := &fakeSource{users: []models.UserResponse{{ID: 1, Name: "Alice"}}}
+	notifier := &recordingNotifier{}
+	job := NewJob(source, notifier, Config{Location: tokyo, Within: 3, Clock: now}, zap.NewNop())
+
+	if err := job.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if source.within != 3 {
+		t.Errorf("within = %d, want 3", source.within)
+	}
+	if len(notifier.digests) != 1 {
+		t.Fatalf("sent %d digests, want 1", len(notifier.digests))
+	}
+	if d := notifier.digests[0]; d.Date != "2024-05-10" || d.Within != 3 || len(d.Users) != 1 {
+		t.Errorf("digest = %+v, want 2024-05-10 within 3 with Alice", d)
+	}
+
+	source.users = nil
+	if err := job.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if len(notifier.digests) != 1 {
+		t.Errorf("sent a digest without birthdays")
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got Digest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		if got.Date == "fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	n := NewWebhookNotifier(server.URL, server.Client())
+	d := Digest{Date: "2024-05-10", Users: []models.UserResponse{{ID: 1, Name: "Alice"}}}
+	if err := n.Notify(context.Background(), d); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if got.Date != d.Date || len(got.Users) != 1 || got.Users[0].Name != "Alice" {
+		t.Errorf("webhook received %+v, want %+v", got, d)
+	}
+
+	if err := n.Notify(context.Background(), Digest{Date: "fail"}); err == nil {
+		t.Error("Notify() error = nil for a 502 response")
+	}
+}
+
+// smtpServer accepts one message over a minimal SMTP dialogue and sends
+// its DATA on the returned channel
+func smtpServer(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	messages := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ready")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case cmd == "DATA":
+				reply("354 end with .")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				messages <- data.String()
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().String(), messages
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	addr, messages := smtpServer(t)
+	today, later, next := 0, 5, "2024-05-15"
+
+	n := NewSMTPNotifier(addr, "hr@example.com", []string{"team@example.com"})
+	err := n.Notify(context.Background(), Digest{Date: "2024-05-10", Within: 7, Users: []models.UserResponse{
+		{ID: 1, Name: "Alice", DaysUntilBirthday: &today},
+		{ID: 2, Name: "Bob", DaysUntilBirthday: &later, NextBirthday: &next},
+	}})
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	select {
+	case msg := <-messages:
+		for _, want := range []string{"Subject: Birthdays for 2024-05-10", "To: team@example.com", "Alice - today", "Bob - 2024-05-15, in 5 days"} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("message missing %q:\n%s", want, msg)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+}
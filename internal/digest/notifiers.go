@@ -0,0 +1,110 @@
+// internal/digest/notifiers.go
+package digest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+type logNotifier struct {
+	logger *zap.Logger
+}
+
+// NewLogNotifier writes digests to the application log
+func NewLogNotifier(logger *zap.Logger) Notifier {
+	return &logNotifier{logger: logger}
+}
+
+func (n *logNotifier) Notify(_ context.Context, d Digest) error {
+	names := make([]string, 0, len(d.Users))
+	for _, u := range d.Users {
+		names = append(names, u.Name)
+	}
+	n.logger.Info("Birthday digest",
+		zap.String("date", d.Date),
+		zap.Int("within", d.Within),
+		zap.Strings("users", names),
+	)
+	return nil
+}
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier POSTs digests as JSON to url. Any response other than
+// 2xx is an error.
+func NewWebhookNotifier(url string, client *http.Client) Notifier {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &webhookNotifier{url: url, client: client}
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, d Digest) error {
+	body, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("birthday digest webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+type smtpNotifier struct {
+	addr string
+	from string
+	to   []string
+}
+
+// NewSMTPNotifier mails digests as plain text through the SMTP server at
+// addr, without authentication, which suits a local relay or test server
+// such as MailHog
+func NewSMTPNotifier(addr, from string, to []string) Notifier {
+	return &smtpNotifier{addr: addr, from: from, to: to}
+}
+
+func (n *smtpNotifier) Notify(_ context.Context, d Digest) error {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", n.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&msg, "Subject: Birthdays for %s\r\n", d.Date)
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+
+	for _, u := range d.Users {
+		switch {
+		case u.DaysUntilBirthday == nil:
+			fmt.Fprintf(&msg, "%s\r\n", u.Name)
+		case *u.DaysUntilBirthday == 0:
+			fmt.Fprintf(&msg, "%s - today\r\n", u.Name)
+		case *u.DaysUntilBirthday == 1:
+			fmt.Fprintf(&msg, "%s - tomorrow\r\n", u.Name)
+		default:
+			fmt.Fprintf(&msg, "%s - %s, in %d days\r\n", u.Name, *u.NextBirthday, *u.DaysUntilBirthday)
+		}
+	}
+
+	return smtp.SendMail(n.addr, nil, n.from, n.to, []byte(msg.String()))
+}
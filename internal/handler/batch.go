@@ -0,0 +1,76 @@
+// internal/handler/batch.go
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/problem"
+	"go.uber.org/zap"
+)
+
+// batchResponse is the multi-status body of POST /users/batch
+type batchResponse struct {
+	Mode      models.BatchMode `json:"mode"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []batchItem      `json:"results"`
+}
+
+// batchItem reports one operation with the status it would have had as a
+// standalone request
+type batchItem struct {
+	Index  int                  `json:"index"`
+	Op     models.BatchOp       `json:"op"`
+	Status int                  `json:"status"`
+	ID     int32                `json:"id,omitempty"`
+	User   *models.UserResponse `json:"user,omitempty"`
+	Error  *problem.Details     `json:"error,omitempty"`
+}
+
+func (h *userHandler) BatchUsers(c *fiber.Ctx) error {
+	var req models.BatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Error("Failed to parse request body", zap.Error(err))
+		return WriteError(c, invalidBody(err))
+	}
+	if err := req.Validate(); err != nil {
+		return WriteError(c, err)
+	}
+	if req.Mode == "" {
+		req.Mode = models.BatchAtomic
+	}
+
+	ctx, err := requestContext(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	results, err := h.service.ExecuteBatch(ctx, req)
+	if err != nil {
+		h.logger.Error("Failed to execute batch", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	response := batchResponse{Mode: req.Mode, Results: make([]batchItem, 0, len(results))}
+	for _, r := range results {
+		item := batchItem{Index: r.Index, Op: r.Op, ID: r.ID, User: r.User}
+		switch {
+		case r.Err != nil:
+			item.Error = problemFor(c, r.Err)
+			item.Status = item.Error.Status
+			response.Failed++
+		case r.Op == models.BatchCreate:
+			item.Status = fiber.StatusCreated
+		case r.Op == models.BatchDelete:
+			item.Status = fiber.StatusNoContent
+		default:
+			item.Status = fiber.StatusOK
+		}
+		if r.Err == nil {
+			response.Succeeded++
+		}
+		response.Results = append(response.Results, item)
+	}
+
+	return c.Status(fiber.StatusMultiStatus).JSON(response)
+}
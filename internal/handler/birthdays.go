@@ -0,0 +1,51 @@
+// internal/handler/birthdays.go
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"go.uber.org/zap"
+)
+
+// UpcomingBirthdays lists the users with a birthday in the next ?within=
+// days (7d by default), counted in ?tz= or UTC
+func (h *userHandler) UpcomingBirthdays(c *fiber.Ctx) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	within, err := parseWithin(c.Query("within", "7d"))
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	ctx, err := requestContext(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	users, err := h.service.UpcomingBirthdays(ctx, within)
+	if err != nil {
+		h.logger.Error("Failed to list upcoming birthdays", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	return respond(c, cd, fiber.StatusOK, users)
+}
+
+// parseWithin parses a number of days, written as 7d or just 7
+func parseWithin(value string) (int, error) {
+	days, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), "d"))
+	if err != nil {
+		return 0, apperrors.Validation("Invalid within", apperrors.FieldError{
+			Field:   "within",
+			Rule:    "days",
+			Message: "within must be a number of days such as 7d",
+		})
+	}
+	return days, nil
+}
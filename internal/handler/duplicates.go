@@ -0,0 +1,35 @@
+// internal/handler/duplicates.go
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"go.uber.org/zap"
+)
+
+// FindDuplicates reports clusters of live users that are probably the same
+// person, matched on normalized name and dob or, with ?min_similarity=, on
+// similar names born the same day
+func (h *userHandler) FindDuplicates(c *fiber.Ctx) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	var query models.DuplicatesQuery
+	if err := c.QueryParser(&query); err != nil {
+		return WriteError(c, &apperrors.Error{Kind: apperrors.ErrValidation, Message: "Invalid query parameters", Err: err})
+	}
+	if err := query.Validate(); err != nil {
+		return WriteError(c, err)
+	}
+
+	clusters, err := h.service.DuplicateClusters(c.Context(), query)
+	if err != nil {
+		h.logger.Error("Failed to find duplicate users", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	return respond(c, cd, fiber.StatusOK, clusters)
+}
@@ -0,0 +1,137 @@
+// internal/handler/errors.go
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/middleware"
+	"github.com/shravanirajulu2004/go-user-api/internal/problem"
+	"github.com/shravanirajulu2004/go-user-api/internal/service"
+)
+
+// StatusCode maps a domain or Fiber error to its HTTP status code
+func StatusCode(err error) int {
+	var fiberErr *fiber.Error
+	switch {
+	case errors.As(err, &fiberErr):
+		return fiberErr.Code
+	case errors.Is(err, apperrors.ErrValidation):
+		return fiber.StatusBadRequest
+	case errors.Is(err, apperrors.ErrNotFound):
+		return fiber.StatusNotFound
+	case errors.Is(err, apperrors.ErrConflict):
+		return fiber.StatusConflict
+	case errors.Is(err, apperrors.ErrPreconditionFailed):
+		return fiber.StatusPreconditionFailed
+	case errors.Is(err, apperrors.ErrUnavailable):
+		return fiber.StatusServiceUnavailable
+	case errors.Is(err, apperrors.ErrAborted):
+		return fiber.StatusFailedDependency
+	case errors.Is(err, apperrors.ErrMoved):
+		return fiber.StatusPermanentRedirect
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+// problemTypes gives each domain error kind a stable problem type URI
+var problemTypes = map[int]string{
+	fiber.StatusBadRequest:         "/problems/validation-error",
+	fiber.StatusNotFound:           "/problems/not-found",
+	fiber.StatusConflict:           "/problems/conflict",
+	fiber.StatusPreconditionFailed: "/problems/precondition-failed",
+	fiber.StatusServiceUnavailable: "/problems/unavailable",
+	fiber.StatusFailedDependency:   "/problems/aborted",
+	fiber.StatusMovedPermanently:   "/problems/merged",
+	fiber.StatusPermanentRedirect:  "/problems/merged",
+}
+
+// WriteError renders err as an RFC 9457 problem response. A user merged
+// into another redirects to the same URL on the survivor.
+func WriteError(c *fiber.Ctx, err error) error {
+	p := problemFor(c, err)
+	if into, ok := p.Extensions["merged_into"]; ok && errors.Is(err, apperrors.ErrMoved) {
+		c.Location(mergedLocation(c, into))
+	}
+	return p.Write(c)
+}
+
+// mergedLocation is the request URL with the user id swapped for into
+func mergedLocation(c *fiber.Ctx, into any) string {
+	location := strings.Replace(c.Path(), "/users/"+c.Params("id"), fmt.Sprintf("/users/%v", into), 1)
+	if query := c.Request().URI().QueryString(); len(query) > 0 {
+		location += "?" + string(query)
+	}
+	return location
+}
+
+// problemFor describes err as a problem. Messages of unrecognised errors
+// are never exposed to the client.
+func problemFor(c *fiber.Ctx, err error) *problem.Details {
+	status := StatusCode(err)
+	// 308 makes clients repeat writes on the new URL; reads use the more
+	// widely cached 301
+	if status == fiber.StatusPermanentRedirect && (c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead) {
+		status = fiber.StatusMovedPermanently
+	}
+
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return problem.New(c, status, "", fiberErr.Message)
+	}
+
+	var appErr *apperrors.Error
+	if !errors.As(err, &appErr) {
+		return problem.New(c, status, "", "Internal server error")
+	}
+
+	p := problem.New(c, status, problemTypes[status], appErr.Message)
+	p.Errors = appErr.Fields
+	p.Extend(appErr.Details)
+	return p
+}
+
+func parseUserID(c *fiber.Ctx) (int32, error) {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 32)
+	if err != nil || id < 1 {
+		return 0, apperrors.Validation("Invalid user ID", apperrors.FieldError{
+			Field:   "id",
+			Rule:    "numeric",
+			Message: "id must be a positive integer",
+		})
+	}
+	return int32(id), nil
+}
+
+// requestContext returns the context for service calls, carrying who made
+// the request and the time zone requested with ?tz= for computing ages
+func requestContext(c *fiber.Ctx) (context.Context, error) {
+	requestID, _ := c.Locals("requestID").(string)
+	ctx := service.WithActor(c.Context(), service.Actor{
+		Name:      middleware.Actor(c),
+		RequestID: requestID,
+	})
+	if tz := c.Query("tz"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil || tz == "Local" {
+			return nil, apperrors.Validation("Invalid time zone", apperrors.FieldError{
+				Field:   "tz",
+				Rule:    "timezone",
+				Message: "tz must be an IANA time zone such as Asia/Kolkata",
+			})
+		}
+		ctx = service.WithLocation(ctx, loc)
+	}
+	return ctx, nil
+}
+
+func invalidBody(err error) error {
+	return &apperrors.Error{Kind: apperrors.ErrValidation, Message: "Invalid request body", Err: err}
+}
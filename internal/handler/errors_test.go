@@ -0,0 +1,158 @@
+// internal/handler/errors_test.go
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/problem"
+)
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"Not found", apperrors.NotFound("user 1 not found"), fiber.StatusNotFound},
+		{"Wrapped not found", fmt.Errorf("lookup: %w", apperrors.NotFound("gone")), fiber.StatusNotFound},
+		{"Validation", apperrors.Validation("bad"), fiber.StatusBadRequest},
+		{"Conflict", apperrors.Conflict("exists"), fiber.StatusConflict},
+		{"Precondition failed", apperrors.PreconditionFailed("stale"), fiber.StatusPreconditionFailed},
+		{"Unavailable", apperrors.Unavailable(errors.New("dial"), "db down"), fiber.StatusServiceUnavailable},
+		{"Fiber error", fiber.ErrMethodNotAllowed, fiber.StatusMethodNotAllowed},
+		{"Unknown", errors.New("boom"), fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StatusCode(tt.err); got != tt.want {
+				t.Errorf("StatusCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteError_ProblemDetails(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		c.Locals("requestID", "req-123")
+		req := models.CreateUserRequest{DOB: "10-05-1990"}
+		return WriteError(c, req.Validate())
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+
+	if resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != problem.ContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, problem.ContentType)
+	}
+
+	var got struct {
+		problem.Details
+		RequestID string `json:"request_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if got.Instance != "urn:request:req-123" || got.RequestID != "req-123" {
+		t.Errorf("instance = %q, request_id = %q; want %q, %q", got.Instance, got.RequestID, "urn:request:req-123", "req-123")
+	}
+
+	rules := make(map[string]string)
+	for _, fe := range got.Errors {
+		rules[fe.Field] = fe.Rule
+	}
+	if rules["name"] != "required" || rules["dob"] != "datetime" {
+		t.Errorf("errors = %+v, want name/required and dob/datetime", got.Errors)
+	}
+}
+
+func TestWriteError_EscapesRequestIDInInstance(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		c.Locals("requestID", "retry 2/100%")
+		return WriteError(c, apperrors.NotFound("user 1 not found"))
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+	var got struct {
+		problem.Details
+		RequestID string `json:"request_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if want := "urn:request:retry%202%2F100%25"; got.Instance != want || got.RequestID != "retry 2/100%" {
+		t.Errorf("instance = %q, request_id = %q; want %q, %q", got.Instance, got.RequestID, want, "retry 2/100%")
+	}
+}
+
+func TestWriteError_Extensions(t *testing.T) {
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		return WriteError(c, apperrors.Conflict("duplicate").With("conflicting_id", int32(7)))
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/", nil))
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+	if resp.StatusCode != fiber.StatusConflict {
+		t.Errorf("status = %d, want %d", resp.StatusCode, fiber.StatusConflict)
+	}
+
+	var got map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if got["conflicting_id"] != float64(7) || got["status"] != float64(fiber.StatusConflict) {
+		t.Errorf("body = %v, want conflicting_id 7 alongside the problem members", got)
+	}
+}
+
+func TestWriteError_MergedUserRedirects(t *testing.T) {
+	app := fiber.New()
+	moved := func(c *fiber.Ctx) error {
+		return WriteError(c, apperrors.Moved("user 2 was merged into user 1").With("merged_into", int32(1)))
+	}
+	app.Get("/users/:id", moved)
+	app.Put("/users/:id", moved)
+
+	tests := []struct {
+		method, target string
+		wantStatus     int
+		wantLocation   string
+	}{
+		{"GET", "/users/2?fields=name", fiber.StatusMovedPermanently, "/users/1?fields=name"},
+		{"PUT", "/users/2", fiber.StatusPermanentRedirect, "/users/1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.target, nil))
+			if err != nil {
+				t.Fatalf("app.Test() error = %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+		})
+	}
+}
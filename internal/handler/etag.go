@@ -0,0 +1,116 @@
+// internal/handler/etag.go
+package handler
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+)
+
+// userETag returns the strong entity tag of a user representation. The
+// id and version identify the stored state; a computed age is appended so
+// cached GETs are revalidated on birthdays, and a digest of any age detail
+// fields, which change daily. Only the version is compared for If-Match.
+func userETag(u *models.UserResponse) string {
+	tag := fmt.Sprintf("%d-%d", u.ID, u.Version)
+	if u.Age != nil {
+		tag += fmt.Sprintf("-%d", *u.Age)
+	}
+	if detail := ageDetailDigest(u); detail != "" {
+		tag += "-" + detail
+	}
+	return `"` + tag + `"`
+}
+
+// ageDetailDigest hashes the computed age detail fields of u, or returns ""
+// when none were selected
+func ageDetailDigest(u *models.UserResponse) string {
+	fields := []any{u.AgeYears, u.AgeMonths, u.AgeDays, u.NextBirthday,
+		u.DaysUntilBirthday, u.ZodiacSign, u.BirthWeek, u.IsMinor}
+
+	h := sha256.New()
+	selected := false
+	for _, f := range fields {
+		v := reflect.ValueOf(f)
+		if v.IsNil() {
+			io.WriteString(h, "-")
+			continue
+		}
+		selected = true
+		fmt.Fprintf(h, "%v;", v.Elem().Interface())
+	}
+	if !selected {
+		return ""
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)[:4])
+}
+
+// fieldsETag distinguishes the entity tag of a representation limited by
+// ?fields= or extended by ?expand=, like representationETag does for media
+// types
+func fieldsETag(etag string, fields models.UserFields) string {
+	if fields == models.DefaultFields {
+		return etag
+	}
+	return strings.TrimSuffix(etag, `"`) + fmt.Sprintf("-f%x", uint16(fields)) + `"`
+}
+
+// listETag returns a strong entity tag covering every user in a list
+func listETag(users []models.UserResponse) string {
+	h := sha256.New()
+	for i := range users {
+		io.WriteString(h, userETag(&users[i]))
+	}
+	return fmt.Sprintf(`"%x"`, h.Sum(nil)[:16])
+}
+
+// notModified reports whether If-None-Match matches etag, using the weak
+// comparison RFC 9110 requires for this header
+func notModified(c *fiber.Ctx, etag string) bool {
+	header := c.Get(fiber.HeaderIfNoneMatch)
+	if header == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion returns the user version required by If-Match, or nil when
+// the header is absent or "*"
+func ifMatchVersion(c *fiber.Ctx, id int32) (*int32, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+	if strings.Contains(header, ",") {
+		return nil, apperrors.Validation("If-Match must contain a single entity tag")
+	}
+	if strings.HasPrefix(header, "W/") {
+		return nil, apperrors.PreconditionFailed("weak entity tags cannot be used with If-Match")
+	}
+
+	parts := strings.Split(strings.Trim(header, `"`), "-")
+	if len(parts) < 2 || parts[0] != strconv.Itoa(int(id)) {
+		return nil, apperrors.PreconditionFailed("If-Match does not identify user %d", id)
+	}
+	version, err := strconv.ParseInt(parts[1], 10, 32)
+	if err != nil {
+		return nil, apperrors.PreconditionFailed("If-Match does not identify user %d", id)
+	}
+
+	v := int32(version)
+	return &v, nil
+}
@@ -0,0 +1,112 @@
+// internal/handler/etag_test.go
+package handler
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int32
+		wantNil bool
+		wantErr error
+	}{
+		{name: "Absent", wantNil: true},
+		{name: "Wildcard", header: "*", wantNil: true},
+		{name: "Matching user", header: `"7-3"`, want: 3},
+		{name: "Tag from GET with age", header: `"7-3-34"`, want: 3},
+		{name: "Other user", header: `"8-3"`, wantErr: apperrors.ErrPreconditionFailed},
+		{name: "Weak tag", header: `W/"7-3"`, wantErr: apperrors.ErrPreconditionFailed},
+		{name: "Garbage", header: `"abc"`, wantErr: apperrors.ErrPreconditionFailed},
+		{name: "List", header: `"7-3", "7-4"`, wantErr: apperrors.ErrValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Put("/", func(c *fiber.Ctx) error {
+				got, err := ifMatchVersion(c, 7)
+				switch {
+				case tt.wantErr != nil:
+					if !errors.Is(err, tt.wantErr) {
+						t.Errorf("ifMatchVersion() error = %v, want %v", err, tt.wantErr)
+					}
+				case err != nil:
+					t.Errorf("ifMatchVersion() error = %v", err)
+				case tt.wantNil:
+					if got != nil {
+						t.Errorf("ifMatchVersion() = %d, want nil", *got)
+					}
+				case got == nil || *got != tt.want:
+					t.Errorf("ifMatchVersion() = %v, want %d", got, tt.want)
+				}
+				return nil
+			})
+
+			req := httptest.NewRequest("PUT", "/", nil)
+			if tt.header != "" {
+				req.Header.Set("If-Match", tt.header)
+			}
+			if _, err := app.Test(req); err != nil {
+				t.Fatalf("app.Test() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestUserETag_ChangesWithAge(t *testing.T) {
+	age := 33
+	user := models.UserResponse{ID: 7, Version: 3, Age: &age}
+	before := userETag(&user)
+
+	age = 34
+	if after := userETag(&user); after == before {
+		t.Errorf("userETag() = %s both before and after a birthday", after)
+	}
+}
+
+func TestUserETag_ChangesWithAgeDetail(t *testing.T) {
+	age, days := 33, 10
+	user := models.UserResponse{ID: 7, Version: 3, Age: &age}
+	plain := userETag(&user)
+
+	user.DaysUntilBirthday = &days
+	before := userETag(&user)
+	if before == plain {
+		t.Errorf("userETag() = %s with and without age detail", before)
+	}
+
+	days = 9
+	if after := userETag(&user); after == before {
+		t.Errorf("userETag() = %s on consecutive days", after)
+	}
+	if v, err := strconv.ParseInt(strings.Split(strings.Trim(before, `"`), "-")[1], 10, 32); err != nil || v != 3 {
+		t.Errorf("userETag() = %s, want version 3 second", before)
+	}
+}
+
+func TestFieldsETag(t *testing.T) {
+	etag := `"7-3-34"`
+	if got := fieldsETag(etag, models.DefaultFields); got != etag {
+		t.Errorf("fieldsETag() = %s for the default fields, want %s", got, etag)
+	}
+
+	idName := fieldsETag(etag, models.FieldID|models.FieldName)
+	idDOB := fieldsETag(etag, models.FieldID|models.FieldDOB)
+	if idName == etag || idName == idDOB {
+		t.Errorf("fieldsETag() = %s, %s, want distinct tags per fieldset", idName, idDOB)
+	}
+	if !strings.HasPrefix(idName, `"7-3-`) {
+		t.Errorf("fieldsETag() = %s, want the id and version first", idName)
+	}
+}
@@ -0,0 +1,229 @@
+// internal/handler/export.go
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"go.uber.org/zap"
+)
+
+// exportFlushEvery is how many rows are buffered before flushing to the
+// client; a failed flush means the client has gone away
+const exportFlushEvery = 100
+
+// exportFormat is a representation GET /users/export can stream
+type exportFormat struct {
+	name        string
+	contentType string
+	newEncoder  func(w io.Writer) exportEncoder
+}
+
+// exportFormats are listed in order of preference for Accept: */*
+var exportFormats = []exportFormat{
+	{name: "json", contentType: fiber.MIMEApplicationJSON, newEncoder: newJSONArrayEncoder},
+	{name: "ndjson", contentType: "application/x-ndjson", newEncoder: newNDJSONEncoder},
+	{name: "csv", contentType: "text/csv", newEncoder: newCSVEncoder},
+}
+
+// exportEncoder writes users one at a time; Close completes the document
+type exportEncoder interface {
+	Encode(user *models.UserResponse) error
+	Close() error
+}
+
+func (h *userHandler) ExportUsers(c *fiber.Ctx) error {
+	includeDeleted, err := includeDeleted(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	var filter models.UserFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return WriteError(c, &apperrors.Error{Kind: apperrors.ErrValidation, Message: "Invalid query parameters", Err: err})
+	}
+	if err := filter.Validate(); err != nil {
+		return WriteError(c, err)
+	}
+
+	format, ok := negotiateExportFormat(c)
+	if !ok {
+		return WriteError(c, fiber.NewError(fiber.StatusNotAcceptable,
+			"Export is available as application/json, application/x-ndjson or text/csv"))
+	}
+
+	ctx, err := requestContext(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+	export, err := h.service.ExportUsers(ctx, filter, includeDeleted)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, format.contentType)
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="users.`+format.name+`"`)
+
+	// The body is written after the handler returns; headers are already
+	// sent by then, so failures can only be logged and end the stream early
+	reqCtx := c.Context()
+	logger := h.logger
+	reqCtx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithCancel(reqCtx)
+		defer cancel()
+
+		enc := format.newEncoder(w)
+		rows := 0
+		err := export(ctx, func(user *models.UserResponse) error {
+			if err := enc.Encode(user); err != nil {
+				cancel()
+				return err
+			}
+			if rows++; rows%exportFlushEvery == 0 {
+				if err := w.Flush(); err != nil {
+					cancel()
+					return err
+				}
+			}
+			return nil
+		})
+		if err == nil {
+			err = enc.Close()
+		}
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			logger.Warn("User export ended early", zap.Error(err), zap.Int("rows", rows))
+		}
+	})
+	return nil
+}
+
+// negotiateExportFormat picks the format from ?format=, falling back to the
+// Accept header
+func negotiateExportFormat(c *fiber.Ctx) (exportFormat, bool) {
+	if name := c.Query("format"); name != "" {
+		for _, f := range exportFormats {
+			if f.name == name {
+				return f, true
+			}
+		}
+		return exportFormat{}, false
+	}
+
+	offers := make([]string, 0, len(exportFormats))
+	for _, f := range exportFormats {
+		offers = append(offers, f.contentType)
+	}
+	accepted := c.Accepts(offers...)
+	for _, f := range exportFormats {
+		if f.contentType == accepted {
+			return f, true
+		}
+	}
+	return exportFormat{}, false
+}
+
+type jsonArrayEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONArrayEncoder(w io.Writer) exportEncoder {
+	return &jsonArrayEncoder{w: w}
+}
+
+func (e *jsonArrayEncoder) Encode(user *models.UserResponse) error {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+	sep := ","
+	if e.count == 0 {
+		sep = "["
+	}
+	e.count++
+	if _, err := io.WriteString(e.w, sep); err != nil {
+		return err
+	}
+	_, err = e.w.Write(data)
+	return err
+}
+
+func (e *jsonArrayEncoder) Close() error {
+	end := "]"
+	if e.count == 0 {
+		end = "[]"
+	}
+	_, err := io.WriteString(e.w, end)
+	return err
+}
+
+type ndjsonEncoder struct {
+	enc *json.Encoder
+}
+
+func newNDJSONEncoder(w io.Writer) exportEncoder {
+	return &ndjsonEncoder{enc: json.NewEncoder(w)}
+}
+
+func (e *ndjsonEncoder) Encode(user *models.UserResponse) error {
+	return e.enc.Encode(user)
+}
+
+func (e *ndjsonEncoder) Close() error {
+	return nil
+}
+
+type csvEncoder struct {
+	w      *csv.Writer
+	header bool
+}
+
+func newCSVEncoder(w io.Writer) exportEncoder {
+	return &csvEncoder{w: csv.NewWriter(w)}
+}
+
+func (e *csvEncoder) Encode(user *models.UserResponse) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	age, deletedAt := "", ""
+	if user.Age != nil {
+		age = strconv.Itoa(*user.Age)
+	}
+	if user.DeletedAt != nil {
+		deletedAt = *user.DeletedAt
+	}
+	if err := e.w.Write([]string{strconv.Itoa(int(user.ID)), user.Name, user.DOB, age, deletedAt}); err != nil {
+		return err
+	}
+	// Push the row into the underlying writer so its flushes see it
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvEncoder) writeHeader() error {
+	if e.header {
+		return nil
+	}
+	e.header = true
+	return e.w.Write([]string{"id", "name", "dob", "age", "deleted_at"})
+}
@@ -0,0 +1,113 @@
+// internal/handler/export_test.go
+package handler
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+)
+
+func TestNegotiateExportFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		accept string
+		want   string
+		wantOK bool
+	}{
+		{name: "Default", want: "json", wantOK: true},
+		{name: "Query wins", query: "?format=csv", accept: "application/json", want: "csv", wantOK: true},
+		{name: "Accept NDJSON", accept: "application/x-ndjson", want: "ndjson", wantOK: true},
+		{name: "Accept with quality", accept: "application/json;q=0.5, text/csv", want: "csv", wantOK: true},
+		{name: "Unknown query", query: "?format=parquet"},
+		{name: "Unacceptable", accept: "application/xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				got, ok := negotiateExportFormat(c)
+				if ok != tt.wantOK || got.name != tt.want {
+					t.Errorf("negotiateExportFormat() = %q, %v; want %q, %v", got.name, ok, tt.want, tt.wantOK)
+				}
+				return nil
+			})
+
+			req := httptest.NewRequest("GET", "/"+tt.query, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			if _, err := app.Test(req); err != nil {
+				t.Fatalf("app.Test() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestExportEncoders(t *testing.T) {
+	age := 34
+	deletedAt := "2024-01-02T03:04:05Z"
+	users := []models.UserResponse{
+		{ID: 1, Name: "Alice", DOB: "1990-05-10", Age: &age},
+		{ID: 2, Name: "Smith, Bob", DOB: "1985-01-02", Age: &age, DeletedAt: &deletedAt},
+	}
+
+	tests := []struct {
+		format    string
+		want      string
+		wantEmpty string
+	}{
+		{
+			format:    "json",
+			want:      `[{"id":1,"name":"Alice","dob":"1990-05-10","age":34},{"id":2,"name":"Smith, Bob","dob":"1985-01-02","age":34,"deleted_at":"2024-01-02T03:04:05Z"}]`,
+			wantEmpty: "[]",
+		},
+		{
+			format: "ndjson",
+			want: `{"id":1,"name":"Alice","dob":"1990-05-10","age":34}` + "\n" +
+				`{"id":2,"name":"Smith, Bob","dob":"1985-01-02","age":34,"deleted_at":"2024-01-02T03:04:05Z"}` + "\n",
+		},
+		{
+			format:    "csv",
+			want:      "id,name,dob,age,deleted_at\n1,Alice,1990-05-10,34,\n2,\"Smith, Bob\",1985-01-02,34,2024-01-02T03:04:05Z\n",
+			wantEmpty: "id,name,dob,age,deleted_at\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var format exportFormat
+			for _, f := range exportFormats {
+				if f.name == tt.format {
+					format = f
+				}
+			}
+
+			var b strings.Builder
+			enc := format.newEncoder(&b)
+			for i := range users {
+				if err := enc.Encode(&users[i]); err != nil {
+					t.Fatalf("Encode() error = %v", err)
+				}
+			}
+			if err := enc.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("output = %q, want %q", b.String(), tt.want)
+			}
+
+			b.Reset()
+			if err := format.newEncoder(&b).Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if b.String() != tt.wantEmpty {
+				t.Errorf("empty output = %q, want %q", b.String(), tt.wantEmpty)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// internal/handler/history.go
+package handler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"go.uber.org/zap"
+)
+
+// UserHistory lists the changes made to a user, newest first, ?limit= at a
+// time (20 by default). The next page is linked with a cursor.
+func (h *userHandler) UserHistory(c *fiber.Ctx) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	id, err := parseUserID(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	var query models.HistoryQuery
+	if err := c.QueryParser(&query); err != nil {
+		return WriteError(c, &apperrors.Error{Kind: apperrors.ErrValidation, Message: "Invalid query parameters", Err: err})
+	}
+	if err := query.Validate(); err != nil {
+		return WriteError(c, err)
+	}
+
+	page, err := h.service.UserHistory(c.Context(), id, query)
+	if err != nil {
+		h.logger.Error("Failed to get user history", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	if page.NextCursor != "" {
+		c.Set(fiber.HeaderLink, fmt.Sprintf(`<%s>; rel="next"`, pageURL(c, map[string]string{"cursor": page.NextCursor})))
+	}
+	return respond(c, cd, fiber.StatusOK, page.Entries)
+}
+
+// parseAsOf parses ?as_of=, returning the zero time when it is absent
+func parseAsOf(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	asOf, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, apperrors.Validation("Invalid as_of", apperrors.FieldError{
+			Field:   "as_of",
+			Rule:    "datetime",
+			Message: "as_of must be an RFC 3339 timestamp such as 2024-05-10T12:00:00Z",
+		})
+	}
+	return asOf, nil
+}
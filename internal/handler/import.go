@@ -0,0 +1,70 @@
+// internal/handler/import.go
+package handler
+
+import (
+	"bytes"
+	"io"
+	"maps"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/importer"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"go.uber.org/zap"
+)
+
+func (h *userHandler) ImportUsers(c *fiber.Ctx) error {
+	var opts models.ImportOptions
+	if err := c.QueryParser(&opts); err != nil {
+		return WriteError(c, &apperrors.Error{Kind: apperrors.ErrValidation, Message: "Invalid query parameters", Err: err})
+	}
+	if err := opts.Validate(); err != nil {
+		return WriteError(c, err)
+	}
+
+	// ?format= wins over Content-Type for clients that cannot set it
+	formatName := c.Query("format", c.Get(fiber.HeaderContentType))
+	format, ok := importer.ParseFormat(formatName)
+	if !ok {
+		return WriteError(c, fiber.NewError(fiber.StatusUnsupportedMediaType,
+			"Import must be text/csv or application/x-ndjson, or set ?format=csv|ndjson"))
+	}
+
+	var body io.Reader = bytes.NewReader(c.Body())
+	if stream := c.Context().RequestBodyStream(); stream != nil {
+		body = stream
+	}
+
+	reader, err := importer.NewReader(body, format)
+	if err != nil {
+		return WriteError(c, &apperrors.Error{Kind: apperrors.ErrValidation, Message: err.Error(), Err: err})
+	}
+
+	ctx, err := requestContext(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	report, err := h.service.ImportUsers(ctx, reader, opts)
+	if err != nil {
+		h.logger.Error("Failed to import users", zap.Error(err))
+		if report != nil {
+			return writeImportError(c, err, report)
+		}
+		return WriteError(c, err)
+	}
+
+	return c.JSON(report)
+}
+
+// writeImportError renders err with the report of the chunks committed
+// before it in a report member, since they stay imported
+func writeImportError(c *fiber.Ctx, err error, report *models.ImportReport) error {
+	p := problemFor(c, err)
+	p.Extensions = maps.Clone(p.Extensions)
+	if p.Extensions == nil {
+		p.Extensions = map[string]any{}
+	}
+	p.Extensions["report"] = report
+	return p.Write(c)
+}
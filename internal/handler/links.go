@@ -0,0 +1,67 @@
+// internal/handler/links.go
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+)
+
+// setPaginationHeaders exposes a page's neighbours as an RFC 8288 Link
+// header and its total, when known, as X-Total-Count
+func setPaginationHeaders(c *fiber.Ctx, page *models.UserPage) {
+	if page.Total != nil {
+		c.Set("X-Total-Count", strconv.FormatInt(*page.Total, 10))
+	}
+
+	var links []string
+	add := func(rel string, set map[string]string, drop ...string) {
+		links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, pageURL(c, set, drop...), rel))
+	}
+
+	if page.Page == 0 {
+		// Cursor mode
+		if page.NextCursor != "" {
+			add("next", map[string]string{"cursor": page.NextCursor})
+		}
+		if page.PrevCursor != "" {
+			add("prev", map[string]string{"cursor": page.PrevCursor})
+		}
+	} else if page.Total != nil {
+		size := strconv.Itoa(page.PageSize)
+		lastPage := int((*page.Total + int64(page.PageSize) - 1) / int64(page.PageSize))
+		if lastPage < 1 {
+			lastPage = 1
+		}
+
+		add("first", map[string]string{"page": "1", "page_size": size})
+		if page.Page > 1 {
+			add("prev", map[string]string{"page": strconv.Itoa(page.Page - 1), "page_size": size})
+		}
+		if page.Page < lastPage {
+			add("next", map[string]string{"page": strconv.Itoa(page.Page + 1), "page_size": size})
+		}
+		add("last", map[string]string{"page": strconv.Itoa(lastPage), "page_size": size})
+	}
+
+	if len(links) > 0 {
+		c.Set(fiber.HeaderLink, strings.Join(links, ", "))
+	}
+}
+
+// pageURL returns the current request path with its query parameters
+// updated by set
+func pageURL(c *fiber.Ctx, set map[string]string, drop ...string) string {
+	query, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+	for _, key := range drop {
+		query.Del(key)
+	}
+	for key, value := range set {
+		query.Set(key, value)
+	}
+	return c.Path() + "?" + query.Encode()
+}
@@ -0,0 +1,50 @@
+// internal/handler/merge.go
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"go.uber.org/zap"
+)
+
+// MergeUsers merges the user in the body's source_id into :id, which
+// survives. If-Match guards the survivor.
+func (h *userHandler) MergeUsers(c *fiber.Ctx) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	id, err := parseUserID(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	var req models.MergeUsersRequest
+	if err := decodeBody(c, &req); err != nil {
+		h.logger.Error("Failed to parse request body", zap.Error(err))
+		return WriteError(c, err)
+	}
+	if err := req.Validate(); err != nil {
+		return WriteError(c, err)
+	}
+
+	expectedVersion, err := ifMatchVersion(c, id)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	ctx, err := requestContext(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	user, err := h.service.MergeUsers(ctx, id, req, expectedVersion)
+	if err != nil {
+		h.logger.Error("Failed to merge users", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	c.Set(fiber.HeaderETag, representationETag(userETag(user), cd))
+	return respond(c, cd, fiber.StatusOK, user)
+}
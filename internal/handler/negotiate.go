@@ -0,0 +1,161 @@
+// internal/handler/negotiate.go
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"reflect"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/gofiber/fiber/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// codec encodes and decodes one representation of user resources. Models
+// carry json and xml tags; MessagePack and CBOR reuse the json ones.
+type codec struct {
+	name      string
+	mediaType string
+	aliases   []string
+	marshal   func(v any) ([]byte, error)
+	unmarshal func(data []byte, v any) error
+}
+
+// codecs are listed in order of preference for Accept: */*
+var codecs = []*codec{
+	{
+		name:      "json",
+		mediaType: fiber.MIMEApplicationJSON,
+		marshal:   json.Marshal,
+		unmarshal: json.Unmarshal,
+	},
+	{
+		name:      "xml",
+		mediaType: fiber.MIMEApplicationXML,
+		aliases:   []string{fiber.MIMETextXML},
+		marshal:   marshalXML,
+		unmarshal: xml.Unmarshal,
+	},
+	{
+		name:      "msgpack",
+		mediaType: "application/msgpack",
+		aliases:   []string{"application/x-msgpack", "application/vnd.msgpack"},
+		marshal:   marshalMsgpack,
+		unmarshal: unmarshalMsgpack,
+	},
+	{
+		name:      "cbor",
+		mediaType: "application/cbor",
+		marshal:   cbor.Marshal,
+		unmarshal: cbor.Unmarshal,
+	},
+}
+
+// negotiate picks the response codec from the Accept header, defaulting to
+// JSON. Call it before doing any work so unacceptable requests fail early.
+func negotiate(c *fiber.Ctx) (*codec, error) {
+	c.Vary(fiber.HeaderAccept)
+
+	var offers []string
+	for _, cd := range codecs {
+		offers = append(offers, cd.mediaType)
+		offers = append(offers, cd.aliases...)
+	}
+
+	if accepted := c.Accepts(offers...); accepted != "" {
+		if cd := codecFor(accepted); cd != nil {
+			return cd, nil
+		}
+	}
+	return nil, fiber.NewError(fiber.StatusNotAcceptable,
+		"Supported media types are "+strings.Join(offers, ", "))
+}
+
+// decodeBody decodes the request body into v according to Content-Type. A
+// missing Content-Type is read as JSON.
+func decodeBody(c *fiber.Ctx, v any) error {
+	cd := codecs[0]
+	if header := c.Get(fiber.HeaderContentType); header != "" {
+		mediaType, _, _ := mime.ParseMediaType(header)
+		if cd = codecFor(mediaType); cd == nil {
+			return fiber.NewError(fiber.StatusUnsupportedMediaType,
+				"Content-Type must be application/json, application/xml, application/msgpack or application/cbor")
+		}
+	}
+
+	if err := cd.unmarshal(c.Body(), v); err != nil {
+		return invalidBody(err)
+	}
+	return nil
+}
+
+// respond encodes v with cd
+func respond(c *fiber.Ctx, cd *codec, status int, v any) error {
+	data, err := cd.marshal(v)
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, cd.mediaType)
+	return c.Status(status).Send(data)
+}
+
+// representationETag distinguishes the entity tag of a non-JSON
+// representation, as strong tags must differ between representations. The
+// suffix comes after the id and version, so If-Match still parses.
+func representationETag(etag string, cd *codec) string {
+	if cd == codecs[0] {
+		return etag
+	}
+	return strings.TrimSuffix(etag, `"`) + "-" + cd.name + `"`
+}
+
+func codecFor(mediaType string) *codec {
+	mediaType = strings.ToLower(mediaType)
+	for _, cd := range codecs {
+		if cd.mediaType == mediaType {
+			return cd
+		}
+		for _, alias := range cd.aliases {
+			if alias == mediaType {
+				return cd
+			}
+		}
+	}
+	return nil
+}
+
+// xmlList wraps a slice so that it encodes as a single XML document
+type xmlList struct {
+	XMLName xml.Name `xml:"users"`
+	Items   any
+}
+
+func marshalXML(v any) ([]byte, error) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+		v = xmlList{Items: v}
+	}
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+func marshalMsgpack(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func unmarshalMsgpack(data []byte, v any) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
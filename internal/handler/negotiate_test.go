@@ -0,0 +1,164 @@
+// internal/handler/negotiate_test.go
+package handler
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+)
+
+func TestCodecsRoundTrip(t *testing.T) {
+	age := 34
+	deletedAt := "2024-01-02T03:04:05Z"
+	user := models.UserResponse{ID: 7, Name: "Alice <Smith>", DOB: "1990-05-10", Age: &age, DeletedAt: &deletedAt}
+
+	values := []struct {
+		name string
+		in   any
+		out  func() any
+	}{
+		{"UserResponse", &user, func() any { return &models.UserResponse{} }},
+		{"UserResponse without age", &models.UserResponse{ID: 1, Name: "Bob", DOB: "1985-01-02"}, func() any { return &models.UserResponse{} }},
+		{"CreateUserRequest", &models.CreateUserRequest{Name: "Alice", DOB: "1990-05-10"}, func() any { return &models.CreateUserRequest{} }},
+		{"UpdateUserRequest", &models.UpdateUserRequest{Name: "Alice", DOB: "1990-05-10"}, func() any { return &models.UpdateUserRequest{} }},
+		{"UserSearchResult", &models.UserSearchResult{UserResponse: user, Rank: 0.5, Highlight: "<mark>Alice</mark>"}, func() any { return &models.UserSearchResult{} }},
+	}
+
+	for _, cd := range codecs {
+		for _, v := range values {
+			t.Run(cd.name+"/"+v.name, func(t *testing.T) {
+				data, err := cd.marshal(v.in)
+				if err != nil {
+					t.Fatalf("marshal() error = %v", err)
+				}
+				got := v.out()
+				if err := cd.unmarshal(data, got); err != nil {
+					t.Fatalf("unmarshal(%q) error = %v", data, err)
+				}
+				clearXMLNames(got)
+				if !reflect.DeepEqual(got, v.in) {
+					t.Errorf("round trip = %+v, want %+v", got, v.in)
+				}
+			})
+		}
+	}
+}
+
+// clearXMLNames zeroes the XMLName fields that xml.Unmarshal fills in
+func clearXMLNames(v any) {
+	switch v := v.(type) {
+	case *models.UserResponse:
+		v.XMLName.Local = ""
+	case *models.CreateUserRequest:
+		v.XMLName.Local = ""
+	case *models.UpdateUserRequest:
+		v.XMLName.Local = ""
+	case *models.UserSearchResult:
+		v.XMLName.Local = ""
+	}
+}
+
+func TestMarshalXMLList(t *testing.T) {
+	data, err := marshalXML([]models.UserResponse{{ID: 1, Name: "A", DOB: "2000-01-01"}, {ID: 2, Name: "B", DOB: "2000-01-02"}})
+	if err != nil {
+		t.Fatalf("marshalXML() error = %v", err)
+	}
+	want := "<users><user><id>1</id><name>A</name><dob>2000-01-01</dob></user><user><id>2</id><name>B</name><dob>2000-01-02</dob></user></users>"
+	if !strings.HasSuffix(string(data), want) {
+		t.Errorf("marshalXML() = %s, want suffix %s", data, want)
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept     string
+		wantType   string
+		wantStatus int
+	}{
+		{accept: "", wantType: "application/json", wantStatus: 200},
+		{accept: "*/*", wantType: "application/json", wantStatus: 200},
+		{accept: "text/xml", wantType: "application/xml", wantStatus: 200},
+		{accept: "application/cbor, application/json;q=0.5", wantType: "application/cbor", wantStatus: 200},
+		{accept: "application/x-msgpack", wantType: "application/msgpack", wantStatus: 200},
+		{accept: "text/html", wantStatus: 406},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				cd, err := negotiate(c)
+				if err != nil {
+					return WriteError(c, err)
+				}
+				return respond(c, cd, fiber.StatusOK, &models.UserResponse{ID: 1})
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("app.Test() error = %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantType != "" && resp.Header.Get("Content-Type") != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", resp.Header.Get("Content-Type"), tt.wantType)
+			}
+			if resp.Header.Get("Vary") != "Accept" {
+				t.Errorf("Vary = %q, want Accept", resp.Header.Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestDecodeBody(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        []byte
+		wantStatus  int
+	}{
+		{contentType: "", body: []byte(`{"name":"Alice","dob":"1990-05-10"}`), wantStatus: 204},
+		{contentType: "application/json; charset=utf-8", body: []byte(`{"name":"Alice","dob":"1990-05-10"}`), wantStatus: 204},
+		{contentType: "application/xml", body: []byte(`<user><name>Alice</name><dob>1990-05-10</dob></user>`), wantStatus: 204},
+		{contentType: "application/cbor", body: []byte("\xa2\x64name\x65Alice\x63dob\x6a1990-05-10"), wantStatus: 204},
+		{contentType: "application/xml", body: []byte(`{"name":"Alice"}`), wantStatus: 400},
+		{contentType: "text/plain", body: []byte("Alice"), wantStatus: 415},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			app := fiber.New()
+			app.Post("/", func(c *fiber.Ctx) error {
+				var req models.CreateUserRequest
+				if err := decodeBody(c, &req); err != nil {
+					return WriteError(c, err)
+				}
+				if req.Name != "Alice" || req.DOB != "1990-05-10" {
+					t.Errorf("decoded %+v", req)
+				}
+				return c.SendStatus(fiber.StatusNoContent)
+			})
+
+			req := httptest.NewRequest("POST", "/", bytes.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("app.Test() error = %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
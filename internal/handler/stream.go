@@ -0,0 +1,112 @@
+// internal/handler/stream.go
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/stream"
+	"go.uber.org/zap"
+)
+
+type StreamHandler interface {
+	StreamUsers(c *fiber.Ctx) error
+}
+
+type streamHandler struct {
+	hub       *stream.Hub
+	heartbeat time.Duration
+	logger    *zap.Logger
+}
+
+// NewStreamHandler returns the handler of the user event stream, which
+// sends a comment every heartbeat to keep idle connections open and notice
+// closed ones
+func NewStreamHandler(hub *stream.Hub, heartbeat time.Duration, logger *zap.Logger) StreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &streamHandler{
+		hub:       hub,
+		heartbeat: heartbeat,
+		logger:    logger,
+	}
+}
+
+// StreamUsers streams user events as Server-Sent Events, optionally only
+// those of the users in ?id= and the types in ?type=. A client resuming with
+// Last-Event-ID (or ?last_event_id=) first gets the events it missed, or a
+// reset event when they are no longer logged.
+func (h *streamHandler) StreamUsers(c *fiber.Ctx) error {
+	userIDs, types, err := models.ParseStreamFilter(c.Query("id"), c.Query("type"))
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	lastID := c.Get("Last-Event-ID", c.Query("last_event_id"))
+	sub, err := h.hub.Subscribe(stream.Filter{UserIDs: userIDs, Types: types}, lastID)
+	if err != nil {
+		return WriteError(c, apperrors.Unavailable(err, "Server is shutting down"))
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	// Stops nginx from buffering the stream
+	c.Set("X-Accel-Buffering", "no")
+
+	requestID, _ := c.Locals("requestID").(string)
+	h.logger.Info("Stream opened", zap.String("request_id", requestID), zap.Int("subscribers", h.hub.Subscribers()))
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer func() {
+			sub.Close()
+			h.logger.Info("Stream closed", zap.String("request_id", requestID))
+		}()
+
+		fmt.Fprint(w, "retry: 3000\n\n")
+		if sub.Reset {
+			fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+		}
+		for _, entry := range sub.Backlog {
+			writeStreamEvent(w, entry)
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case entry, ok := <-sub.C:
+				if !ok {
+					// Shutting down, or this client fell behind; it resumes
+					// from its last event when it reconnects
+					return
+				}
+				writeStreamEvent(w, entry)
+			case <-ticker.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+			}
+			// Fails once the client has gone
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+func writeStreamEvent(w *bufio.Writer, entry stream.Entry) {
+	data, err := json.Marshal(entry.Event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", entry.ID, entry.Event.Type, data)
+}
@@ -0,0 +1,65 @@
+// internal/handler/stream_test.go
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/outbox"
+	"github.com/shravanirajulu2004/go-user-api/internal/stream"
+	"go.uber.org/zap"
+)
+
+func TestStreamUsers(t *testing.T) {
+	hub := stream.NewHub(stream.Config{})
+	sub, _ := hub.Subscribe(stream.Filter{}, "")
+	for i, e := range []struct {
+		userID    int32
+		eventType string
+	}{{1, outbox.UserCreated}, {2, outbox.UserCreated}, {1, outbox.UserDeleted}} {
+		_ = hub.Publish(context.Background(), outbox.Event{ID: int64(i + 1), Type: e.eventType, UserID: e.userID, Data: json.RawMessage(`{}`)})
+	}
+	var ids []string
+	for range 3 {
+		ids = append(ids, (<-sub.C).ID)
+	}
+	sub.Close()
+
+	app := fiber.New()
+	app.Get("/users/stream", NewStreamHandler(hub, time.Hour, zap.NewNop()).StreamUsers)
+
+	// The stream only ends when the hub closes
+	go func() {
+		for hub.Subscribers() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		hub.Close()
+	}()
+
+	req := httptest.NewRequest("GET", "/users/stream?id=1", nil)
+	req.Header.Set("Last-Event-ID", ids[0])
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("app.Test() error = %v", err)
+	}
+	if ct := resp.Header.Get(fiber.HeaderContentType); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	want := "retry: 3000\n\nid: " + ids[2] + "\nevent: UserDeleted\n" +
+		`data: {"id":3,"type":"UserDeleted","user_id":1,"occurred_at":"0001-01-01T00:00:00Z","data":{}}` + "\n\n"
+	if string(body) != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+
+	req = httptest.NewRequest("GET", "/users/stream?type=UserMoved", nil)
+	if resp, _ := app.Test(req); resp.StatusCode != fiber.StatusBadRequest {
+		t.Errorf("unknown type status = %d, want %d", resp.StatusCode, fiber.StatusBadRequest)
+	}
+}
@@ -2,9 +2,13 @@
 package handler
 
 import (
-	"strconv"
+	"mime"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/middleware"
 	"github.com/shravanirajulu2004/go-user-api/internal/models"
 	"github.com/shravanirajulu2004/go-user-api/internal/service"
 	"go.uber.org/zap"
@@ -15,7 +19,18 @@ type UserHandler interface {
 	GetUserByID(c *fiber.Ctx) error
 	ListUsers(c *fiber.Ctx) error
 	UpdateUser(c *fiber.Ctx) error
+	PatchUser(c *fiber.Ctx) error
 	DeleteUser(c *fiber.Ctx) error
+	RestoreUser(c *fiber.Ctx) error
+	PurgeDeletedUsers(c *fiber.Ctx) error
+	SearchUsers(c *fiber.Ctx) error
+	BatchUsers(c *fiber.Ctx) error
+	ImportUsers(c *fiber.Ctx) error
+	ExportUsers(c *fiber.Ctx) error
+	UpcomingBirthdays(c *fiber.Ctx) error
+	FindDuplicates(c *fiber.Ctx) error
+	MergeUsers(c *fiber.Ctx) error
+	UserHistory(c *fiber.Ctx) error
 }
 
 type userHandler struct {
@@ -31,135 +46,338 @@ func NewUserHandler(service service.UserService, logger *zap.Logger) UserHandler
 }
 
 func (h *userHandler) CreateUser(c *fiber.Ctx) error {
-	var req models.CreateUserRequest
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
 
-	if err := c.BodyParser(&req); err != nil {
+	var req models.CreateUserRequest
+	if err := decodeBody(c, &req); err != nil {
 		h.logger.Error("Failed to parse request body", zap.Error(err))
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return WriteError(c, err)
 	}
 
 	if err := req.Validate(); err != nil {
 		h.logger.Error("Validation failed", zap.Error(err))
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Validation failed: " + err.Error(),
-		})
+		return WriteError(c, err)
 	}
 
-	user, err := h.service.CreateUser(c.Context(), req)
+	ctx, err := requestContext(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	user, err := h.service.CreateUser(ctx, req)
 	if err != nil {
 		h.logger.Error("Failed to create user", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create user",
-		})
+		return WriteError(c, err)
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(user)
+	return respond(c, cd, fiber.StatusCreated, user)
 }
 
 func (h *userHandler) GetUserByID(c *fiber.Ctx) error {
-	idParam := c.Params("id")
-	id, err := strconv.ParseInt(idParam, 10, 32)
+	cd, err := negotiate(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return WriteError(c, err)
 	}
 
-	user, err := h.service.GetUserByID(c.Context(), int32(id))
+	id, err := parseUserID(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	includeDeleted, err := includeDeleted(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	fields, err := models.ParseUserFields(c.Query("fields"), c.Query("expand"))
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	asOf, err := parseAsOf(c.Query("as_of"))
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	ctx, err := requestContext(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	var user *models.UserResponse
+	if asOf.IsZero() {
+		user, err = h.service.GetUserByID(ctx, id, includeDeleted, fields)
+	} else {
+		user, err = h.service.GetUserAsOf(ctx, id, asOf, includeDeleted, fields)
+	}
 	if err != nil {
-		if err.Error() == "user not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "User not found",
-			})
-		}
 		h.logger.Error("Failed to get user", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to get user",
-		})
+		return WriteError(c, err)
+	}
+
+	etag := representationETag(fieldsETag(userETag(user), fields), cd)
+	c.Set(fiber.HeaderETag, etag)
+	if notModified(c, etag) {
+		return c.SendStatus(fiber.StatusNotModified)
 	}
 
-	return c.JSON(user)
+	return respond(c, cd, fiber.StatusOK, user)
 }
 
 func (h *userHandler) ListUsers(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	pageSize := c.QueryInt("page_size", 10)
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	includeDeleted, err := includeDeleted(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	// ?cursor= or ?limit= switch to keyset pagination; page/page_size stay
+	// the default for existing clients
+	query := models.ListUsersQuery{
+		Page:           c.QueryInt("page", 1),
+		PageSize:       c.QueryInt("page_size", 10),
+		IncludeDeleted: includeDeleted,
+	}
+	if err := c.QueryParser(&query.Filter); err != nil {
+		return WriteError(c, &apperrors.Error{Kind: apperrors.ErrValidation, Message: "Invalid query parameters", Err: err})
+	}
+	if err := query.Filter.Validate(); err != nil {
+		return WriteError(c, err)
+	}
+	if query.Fields, err = models.ParseUserFields(c.Query("fields"), c.Query("expand")); err != nil {
+		return WriteError(c, err)
+	}
+	if c.Query("cursor") != "" || c.Query("limit") != "" {
+		query.UseCursor = true
+		query.Cursor = c.Query("cursor")
+		query.PageSize = c.QueryInt("limit", 10)
+		query.WithTotal = c.QueryBool("count")
+	}
 
-	responses, _, err := h.service.ListUsers(c.Context(), page, pageSize)
+	ctx, err := requestContext(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	page, err := h.service.ListUsers(ctx, query)
 	if err != nil {
 		h.logger.Error("Failed to list users", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to list users",
-		})
+		return WriteError(c, err)
+	}
+
+	setPaginationHeaders(c, page)
+
+	etag := representationETag(fieldsETag(listETag(page.Users), query.Fields), cd)
+	c.Set(fiber.HeaderETag, etag)
+	if notModified(c, etag) {
+		return c.SendStatus(fiber.StatusNotModified)
 	}
 
 	// Return simple array as per task specification
-	return c.JSON(responses)
+	return respond(c, cd, fiber.StatusOK, page.Users)
+}
+
+func (h *userHandler) SearchUsers(c *fiber.Ctx) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	var query models.SearchUsersQuery
+	if err := c.QueryParser(&query); err != nil {
+		return WriteError(c, &apperrors.Error{Kind: apperrors.ErrValidation, Message: "Invalid query parameters", Err: err})
+	}
+	query.Q = strings.TrimSpace(query.Q)
+	if err := query.Validate(); err != nil {
+		return WriteError(c, err)
+	}
+
+	ctx, err := requestContext(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	results, err := h.service.SearchUsers(ctx, query)
+	if err != nil {
+		h.logger.Error("Failed to search users", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	return respond(c, cd, fiber.StatusOK, results)
 }
 
 func (h *userHandler) UpdateUser(c *fiber.Ctx) error {
-	idParam := c.Params("id")
-	id, err := strconv.ParseInt(idParam, 10, 32)
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	id, err := parseUserID(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return WriteError(c, err)
 	}
 
 	var req models.UpdateUserRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := decodeBody(c, &req); err != nil {
 		h.logger.Error("Failed to parse request body", zap.Error(err))
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+		return WriteError(c, err)
 	}
 
 	if err := req.Validate(); err != nil {
 		h.logger.Error("Validation failed", zap.Error(err))
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Validation failed: " + err.Error(),
-		})
+		return WriteError(c, err)
 	}
 
-	user, err := h.service.UpdateUser(c.Context(), int32(id), req)
+	expectedVersion, err := ifMatchVersion(c, id)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	ctx, err := requestContext(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	user, err := h.service.UpdateUser(ctx, id, req, expectedVersion)
 	if err != nil {
-		if err.Error() == "user not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "User not found",
-			})
-		}
 		h.logger.Error("Failed to update user", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to update user",
-		})
+		return WriteError(c, err)
+	}
+
+	c.Set(fiber.HeaderETag, representationETag(userETag(user), cd))
+	return respond(c, cd, fiber.StatusOK, user)
+}
+
+func (h *userHandler) PatchUser(c *fiber.Ctx) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	id, err := parseUserID(c)
+	if err != nil {
+		return WriteError(c, err)
 	}
 
-	return c.JSON(user)
+	mediaType, _, _ := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	format := models.PatchFormat(mediaType)
+	if format != models.MergePatch && format != models.JSONPatch {
+		c.Set("Accept-Patch", string(models.MergePatch)+", "+string(models.JSONPatch))
+		return WriteError(c, fiber.NewError(fiber.StatusUnsupportedMediaType,
+			"Content-Type must be application/merge-patch+json or application/json-patch+json"))
+	}
+
+	expectedVersion, err := ifMatchVersion(c, id)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	ctx, err := requestContext(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	user, err := h.service.PatchUser(ctx, id, models.PatchUserRequest{
+		Format: format,
+		Patch:  c.Body(),
+	}, expectedVersion)
+	if err != nil {
+		h.logger.Error("Failed to patch user", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	c.Set(fiber.HeaderETag, representationETag(userETag(user), cd))
+	return respond(c, cd, fiber.StatusOK, user)
 }
 
 func (h *userHandler) DeleteUser(c *fiber.Ctx) error {
-	idParam := c.Params("id")
-	id, err := strconv.ParseInt(idParam, 10, 32)
+	id, err := parseUserID(c)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid user ID",
-		})
+		return WriteError(c, err)
 	}
 
-	err = h.service.DeleteUser(c.Context(), int32(id))
+	expectedVersion, err := ifMatchVersion(c, id)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	ctx, err := requestContext(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	err = h.service.DeleteUser(ctx, id, expectedVersion)
 	if err != nil {
-		if err.Error() == "user not found" {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "User not found",
-			})
-		}
 		h.logger.Error("Failed to delete user", zap.Error(err))
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to delete user",
-		})
+		return WriteError(c, err)
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
-}
\ No newline at end of file
+}
+
+func (h *userHandler) RestoreUser(c *fiber.Ctx) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	id, err := parseUserID(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	ctx, err := requestContext(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	user, err := h.service.RestoreUser(ctx, id)
+	if err != nil {
+		h.logger.Error("Failed to restore user", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	c.Set(fiber.HeaderETag, representationETag(userETag(user), cd))
+	return respond(c, cd, fiber.StatusOK, user)
+}
+
+func (h *userHandler) PurgeDeletedUsers(c *fiber.Ctx) error {
+	var retention time.Duration
+	if value := c.Query("retention"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return WriteError(c, apperrors.Validation("Invalid retention", apperrors.FieldError{
+				Field:   "retention",
+				Rule:    "duration",
+				Message: "retention must be a positive duration such as 720h",
+			}))
+		}
+		retention = d
+	}
+
+	purged, err := h.service.PurgeDeletedUsers(c.Context(), retention)
+	if err != nil {
+		h.logger.Error("Failed to purge deleted users", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"purged": purged})
+}
+
+// includeDeleted reads ?include_deleted, which only admins may set
+func includeDeleted(c *fiber.Ctx) (bool, error) {
+	if !c.QueryBool("include_deleted") {
+		return false, nil
+	}
+	if !middleware.IsAdmin(c) {
+		return false, fiber.NewError(fiber.StatusForbidden, "include_deleted requires admin access")
+	}
+	return true, nil
+}
@@ -0,0 +1,259 @@
+// internal/handler/webhook_handler.go
+package handler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/service"
+	"github.com/shravanirajulu2004/go-user-api/internal/webhook"
+	"go.uber.org/zap"
+)
+
+type WebhookHandler interface {
+	CreateWebhook(c *fiber.Ctx) error
+	ListWebhooks(c *fiber.Ctx) error
+	GetWebhook(c *fiber.Ctx) error
+	UpdateWebhook(c *fiber.Ctx) error
+	DeleteWebhook(c *fiber.Ctx) error
+	ListDeliveries(c *fiber.Ctx) error
+	DeadLetters(c *fiber.Ctx) error
+	GetDelivery(c *fiber.Ctx) error
+	Redeliver(c *fiber.Ctx) error
+}
+
+type webhookHandler struct {
+	service service.WebhookService
+	logger  *zap.Logger
+}
+
+func NewWebhookHandler(service service.WebhookService, logger *zap.Logger) WebhookHandler {
+	return &webhookHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (h *webhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	var req models.WebhookRequest
+	if err := decodeBody(c, &req); err != nil {
+		h.logger.Error("Failed to parse request body", zap.Error(err))
+		return WriteError(c, err)
+	}
+	if err := req.Validate(); err != nil {
+		return WriteError(c, err)
+	}
+
+	hook, err := h.service.CreateWebhook(c.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to create webhook", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	return respond(c, cd, fiber.StatusCreated, hook)
+}
+
+func (h *webhookHandler) ListWebhooks(c *fiber.Ctx) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	hooks, err := h.service.ListWebhooks(c.Context())
+	if err != nil {
+		h.logger.Error("Failed to list webhooks", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	return respond(c, cd, fiber.StatusOK, hooks)
+}
+
+func (h *webhookHandler) GetWebhook(c *fiber.Ctx) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	id, err := parseWebhookID(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	hook, err := h.service.GetWebhook(c.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get webhook", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	return respond(c, cd, fiber.StatusOK, hook)
+}
+
+func (h *webhookHandler) UpdateWebhook(c *fiber.Ctx) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	id, err := parseWebhookID(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	var req models.WebhookRequest
+	if err := decodeBody(c, &req); err != nil {
+		h.logger.Error("Failed to parse request body", zap.Error(err))
+		return WriteError(c, err)
+	}
+	if err := req.Validate(); err != nil {
+		return WriteError(c, err)
+	}
+
+	hook, err := h.service.UpdateWebhook(c.Context(), id, req)
+	if err != nil {
+		h.logger.Error("Failed to update webhook", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	return respond(c, cd, fiber.StatusOK, hook)
+}
+
+func (h *webhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := parseWebhookID(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	if err := h.service.DeleteWebhook(c.Context(), id); err != nil {
+		h.logger.Error("Failed to delete webhook", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListDeliveries lists the deliveries to a webhook, newest first, optionally
+// filtered by ?status=. The next page is linked with a cursor.
+func (h *webhookHandler) ListDeliveries(c *fiber.Ctx) error {
+	id, err := parseWebhookID(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+	return h.listDeliveries(c, id, "")
+}
+
+// DeadLetters lists the deliveries to any webhook that ran out of retries,
+// newest first
+func (h *webhookHandler) DeadLetters(c *fiber.Ctx) error {
+	return h.listDeliveries(c, 0, webhook.StatusDead)
+}
+
+// listDeliveries lists the deliveries to webhook id, or to every webhook
+// when it is 0. A non-empty status overrides ?status=.
+func (h *webhookHandler) listDeliveries(c *fiber.Ctx, id int32, status string) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	var query models.WebhookDeliveriesQuery
+	if err := c.QueryParser(&query); err != nil {
+		return WriteError(c, &apperrors.Error{Kind: apperrors.ErrValidation, Message: "Invalid query parameters", Err: err})
+	}
+	if status != "" {
+		query.Status = status
+	}
+	if err := query.Validate(); err != nil {
+		return WriteError(c, err)
+	}
+
+	page, err := h.service.ListDeliveries(c.Context(), id, query)
+	if err != nil {
+		h.logger.Error("Failed to list webhook deliveries", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	if page.NextCursor != "" {
+		c.Set(fiber.HeaderLink, fmt.Sprintf(`<%s>; rel="next"`, pageURL(c, map[string]string{"cursor": page.NextCursor})))
+	}
+	return respond(c, cd, fiber.StatusOK, page.Deliveries)
+}
+
+// GetDelivery returns a delivery with its payload and the log of its attempts
+func (h *webhookHandler) GetDelivery(c *fiber.Ctx) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	id, deliveryID, err := parseDeliveryID(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	delivery, err := h.service.GetDelivery(c.Context(), id, deliveryID)
+	if err != nil {
+		h.logger.Error("Failed to get webhook delivery", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	return respond(c, cd, fiber.StatusOK, delivery)
+}
+
+// Redeliver queues a delivery to be sent again right away, whatever its
+// status, with a fresh set of retries
+func (h *webhookHandler) Redeliver(c *fiber.Ctx) error {
+	cd, err := negotiate(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	id, deliveryID, err := parseDeliveryID(c)
+	if err != nil {
+		return WriteError(c, err)
+	}
+
+	delivery, err := h.service.Redeliver(c.Context(), id, deliveryID)
+	if err != nil {
+		h.logger.Error("Failed to redeliver webhook delivery", zap.Error(err))
+		return WriteError(c, err)
+	}
+
+	return respond(c, cd, fiber.StatusAccepted, delivery)
+}
+
+func parseWebhookID(c *fiber.Ctx) (int32, error) {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 32)
+	if err != nil || id < 1 {
+		return 0, apperrors.Validation("Invalid webhook ID", apperrors.FieldError{
+			Field:   "id",
+			Rule:    "numeric",
+			Message: "id must be a positive integer",
+		})
+	}
+	return int32(id), nil
+}
+
+// parseDeliveryID returns the webhook and delivery IDs of a delivery route
+func parseDeliveryID(c *fiber.Ctx) (int32, int64, error) {
+	id, err := parseWebhookID(c)
+	if err != nil {
+		return 0, 0, err
+	}
+	deliveryID, err := strconv.ParseInt(c.Params("deliveryId"), 10, 64)
+	if err != nil || deliveryID < 1 {
+		return 0, 0, apperrors.Validation("Invalid delivery ID", apperrors.FieldError{
+			Field:   "deliveryId",
+			Rule:    "numeric",
+			Message: "deliveryId must be a positive integer",
+		})
+	}
+	return id, deliveryID, nil
+}
@@ -0,0 +1,224 @@
+// internal/handler/websocket.go
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/live"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/service"
+	"go.uber.org/zap"
+)
+
+type WebSocketHandler interface {
+	// RequireUpgrade rejects requests that are not WebSocket upgrades
+	RequireUpgrade(c *fiber.Ctx) error
+	Serve(conn *websocket.Conn)
+}
+
+// WebSocketConfig holds the limits of /ws connections
+type WebSocketConfig struct {
+	// MaxMessageSize is the largest message a client may send, in bytes
+	MaxMessageSize int64
+	// PingInterval is how often clients are pinged; one that has sent
+	// nothing for two intervals is disconnected
+	PingInterval time.Duration
+	// WriteTimeout bounds writing each message
+	WriteTimeout time.Duration
+}
+
+type webSocketHandler struct {
+	service service.UserService
+	hub     *live.Hub
+	config  WebSocketConfig
+	logger  *zap.Logger
+}
+
+func NewWebSocketHandler(service service.UserService, hub *live.Hub, config WebSocketConfig, logger *zap.Logger) WebSocketHandler {
+	if config.MaxMessageSize <= 0 {
+		config.MaxMessageSize = 4096
+	}
+	if config.PingInterval <= 0 {
+		config.PingInterval = 30 * time.Second
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = 10 * time.Second
+	}
+	return &webSocketHandler{
+		service: service,
+		hub:     hub,
+		config:  config,
+		logger:  logger,
+	}
+}
+
+func (h *webSocketHandler) RequireUpgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return WriteError(c, fiber.ErrUpgradeRequired)
+	}
+	return c.Next()
+}
+
+// Serve answers a client's requests and pushes it the changes to the users
+// it subscribes to, until either side closes the connection
+func (h *webSocketHandler) Serve(conn *websocket.Conn) {
+	requestID, _ := conn.Locals("requestID").(string)
+	client, err := h.hub.Register()
+	if err != nil {
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "server is shutting down"), time.Now().Add(h.config.WriteTimeout))
+		return
+	}
+	h.logger.Info("WebSocket opened", zap.String("request_id", requestID), zap.Int("clients", h.hub.Clients()))
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		h.write(conn, client)
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h.read(ctx, conn, client)
+	cancel()
+	client.Close()
+	<-written
+
+	h.logger.Info("WebSocket closed", zap.String("request_id", requestID), zap.NamedError("reason", client.Err()))
+}
+
+// read handles the client's requests, queueing a reply to each, until the
+// connection fails or the client is disconnected
+func (h *webSocketHandler) read(ctx context.Context, conn *websocket.Conn, client *live.Client) {
+	timeout := 2 * h.config.PingInterval
+	conn.SetReadLimit(h.config.MaxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(timeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(timeout))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(timeout))
+		if !client.Send(h.handle(ctx, client, data)) {
+			return
+		}
+	}
+}
+
+// write sends the client its queued messages and pings until it is
+// disconnected, then closes the connection saying why
+func (h *webSocketHandler) write(conn *websocket.Conn, client *live.Client) {
+	defer conn.Close()
+	ticker := time.NewTicker(h.config.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-client.Messages():
+			_ = conn.SetWriteDeadline(time.Now().Add(h.config.WriteTimeout))
+			if err := conn.WriteJSON(msg); err != nil {
+				client.Close()
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.config.WriteTimeout)); err != nil {
+				client.Close()
+				return
+			}
+		case <-client.Done():
+			code, text := websocket.CloseNormalClosure, ""
+			switch client.Err() {
+			case live.ErrSlowClient:
+				code, text = websocket.CloseTryAgainLater, "too many unread messages"
+			case live.ErrClosed:
+				code, text = websocket.CloseGoingAway, "server is shutting down"
+			}
+			_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(h.config.WriteTimeout))
+			return
+		}
+	}
+}
+
+// handle runs one request and returns the reply
+func (h *webSocketHandler) handle(ctx context.Context, client *live.Client, data []byte) models.LiveMessage {
+	var req models.LiveRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return h.liveError("", &apperrors.Error{Kind: apperrors.ErrValidation, Message: "Invalid JSON message", Err: err})
+	}
+	if err := req.Validate(); err != nil {
+		return h.liveError(req.ID, err)
+	}
+
+	var (
+		result any
+		err    error
+	)
+	switch req.Type {
+	case "subscribe":
+		var ids []int32
+		if ids, err = client.Subscribe(req.UserIDs); errors.Is(err, live.ErrTooManySubscriptions) {
+			err = apperrors.Validation("Too many subscriptions", apperrors.FieldError{
+				Field:   "user_ids",
+				Rule:    "max",
+				Message: "user_ids would watch more users than a connection may",
+			})
+		}
+		result = models.LiveSubscriptions{UserIDs: ids}
+	case "unsubscribe":
+		result = models.LiveSubscriptions{UserIDs: client.Unsubscribe(req.UserIDs)}
+	case "get":
+		result, err = h.service.GetUserByID(ctx, req.UserID, false, models.DefaultFields)
+	case "list":
+		result, err = h.list(ctx, req)
+	}
+	if err != nil {
+		return h.liveError(req.ID, err)
+	}
+	return models.LiveMessage{ID: req.ID, Type: models.LiveResult, Result: result}
+}
+
+// list returns a page of users as GET /users?limit= does
+func (h *webSocketHandler) list(ctx context.Context, req models.LiveRequest) (*models.LiveUserList, error) {
+	if err := req.Filter.Validate(); err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = 10
+	}
+
+	page, err := h.service.ListUsers(ctx, models.ListUsersQuery{
+		Filter:    req.Filter,
+		Fields:    models.DefaultFields,
+		PageSize:  limit,
+		UseCursor: true,
+		Cursor:    req.Cursor,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.LiveUserList{Users: page.Users, NextCursor: page.NextCursor}, nil
+}
+
+// liveError describes a failed request as the matching HTTP request would.
+// Messages of unrecognised errors are never exposed to the client.
+func (h *webSocketHandler) liveError(id string, err error) models.LiveMessage {
+	detail := &models.LiveErrorDetail{Status: StatusCode(err), Message: "Internal server error"}
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		detail.Message = appErr.Message
+		detail.Errors = appErr.Fields
+	}
+	if detail.Status >= fiber.StatusInternalServerError {
+		h.logger.Error("WebSocket request failed", zap.Error(err))
+	}
+	return models.LiveMessage{ID: id, Type: models.LiveError, Error: detail}
+}
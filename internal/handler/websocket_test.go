@@ -0,0 +1,102 @@
+// internal/handler/websocket_test.go
+package handler
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	fastws "github.com/fasthttp/websocket"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/live"
+	"github.com/shravanirajulu2004/go-user-api/internal/middleware"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/outbox"
+	"github.com/shravanirajulu2004/go-user-api/internal/service"
+	"go.uber.org/zap"
+)
+
+// liveUsers has one user, Alice
+type liveUsers struct {
+	service.UserService
+}
+
+func (liveUsers) GetUserByID(_ context.Context, id int32, _ bool, _ models.UserFields) (*models.UserResponse, error) {
+	if id != 1 {
+		return nil, apperrors.NotFound("user %d not found", id)
+	}
+	return &models.UserResponse{ID: 1, Name: "Alice", DOB: "1990-05-10"}, nil
+}
+
+func TestWebSocket(t *testing.T) {
+	hub := live.NewHub(live.Config{})
+	h := NewWebSocketHandler(liveUsers{}, hub, WebSocketConfig{}, zap.NewNop())
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Use(middleware.AdminMiddleware("secret"))
+	app.Get("/ws", middleware.RequireAdmin(), h.RequireUpgrade, websocket.New(h.Serve))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	go app.Listener(ln)
+	defer app.Shutdown()
+	url := "ws://" + ln.Addr().String() + "/ws"
+
+	if _, resp, err := fastws.DefaultDialer.Dial(url, nil); err == nil || resp.StatusCode != fiber.StatusForbidden {
+		t.Fatalf("Dial() without the admin key = %v, want 403", err)
+	}
+
+	conn, _, err := fastws.DefaultDialer.Dial(url, http.Header{"X-Admin-Key": {"secret"}})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	roundTrip := func(req string) models.LiveMessage {
+		t.Helper()
+		if err := conn.WriteMessage(fastws.TextMessage, []byte(req)); err != nil {
+			t.Fatalf("WriteMessage() error = %v", err)
+		}
+		var msg models.LiveMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("ReadJSON() error = %v", err)
+		}
+		return msg
+	}
+
+	if msg := roundTrip(`{"id":"1","type":"get","user_id":1}`); msg.ID != "1" || msg.Type != models.LiveResult {
+		t.Errorf("get reply = %+v", msg)
+	}
+	if msg := roundTrip(`{"id":"2","type":"get","user_id":2}`); msg.Type != models.LiveError || msg.Error.Status != fiber.StatusNotFound {
+		t.Errorf("get of a missing user reply = %+v, want a 404 error", msg)
+	}
+	if msg := roundTrip(`{"id":"3","type":"rename"}`); msg.Type != models.LiveError || msg.Error.Status != fiber.StatusBadRequest {
+		t.Errorf("unknown type reply = %+v, want a 400 error", msg)
+	}
+	if msg := roundTrip(`{"id":"4","type":"subscribe","user_ids":[1]}`); msg.Type != models.LiveResult {
+		t.Errorf("subscribe reply = %+v", msg)
+	}
+
+	hub.Publish(outbox.UserUpdated, 2, nil)
+	hub.Publish(outbox.UserDeleted, 1, nil)
+	var event models.LiveMessage
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	if event.Type != models.LiveEvent || event.Event != outbox.UserDeleted || event.UserID != 1 {
+		t.Errorf("event = %+v, want UserDeleted of user 1", event)
+	}
+
+	hub.Close()
+	_, _, err = conn.ReadMessage()
+	if !fastws.IsCloseError(err, fastws.CloseGoingAway) {
+		t.Errorf("ReadMessage() after shutdown error = %v, want close 1001", err)
+	}
+}
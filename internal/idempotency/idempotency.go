@@ -0,0 +1,62 @@
+// internal/idempotency/idempotency.go
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Response is a completed response stored for replay
+type Response struct {
+	Status  int
+	Headers map[string]string
+	Body    []byte
+}
+
+// Record is the live state of a key. Response is nil while the request that
+// claimed the key is still being handled.
+type Record struct {
+	Fingerprint string
+	Response    *Response
+}
+
+// Store keeps idempotency keys until they expire
+type Store interface {
+	// Claim reserves key for a request identified by fingerprint for ttl.
+	// It returns nil when the key was free or expired and is now held by the
+	// caller, and the record holding it otherwise.
+	Claim(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error)
+	// Complete stores the response to replay for a claimed key
+	Complete(ctx context.Context, key string, resp Response) error
+	// Release frees a claimed key that has no response, so the request can
+	// be retried
+	Release(ctx context.Context, key string) error
+	// DeleteExpired removes expired keys and returns how many there were
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// RunCleanup deletes expired keys from store every interval until ctx is
+// done. Expired keys are already ignored, so this only reclaims space.
+func RunCleanup(ctx context.Context, store Store, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		deleted, err := store.DeleteExpired(ctx)
+		if err != nil {
+			logger.Error("Failed to delete expired idempotency keys", zap.Error(err))
+			continue
+		}
+		if deleted > 0 {
+			logger.Info("Deleted expired idempotency keys", zap.Int64("deleted", deleted))
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// internal/idempotency/memory.go
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/clock"
+)
+
+type memoryEntry struct {
+	record    Record
+	expiresAt time.Time
+}
+
+type memoryStore struct {
+	mu      sync.Mutex
+	clock   clock.Clock
+	entries map[string]*memoryEntry
+}
+
+// NewMemoryStore keeps keys in process memory, for tests and single
+// instance deployments. A nil clock uses the system clock.
+func NewMemoryStore(c clock.Clock) Store {
+	if c == nil {
+		c = clock.System()
+	}
+	return &memoryStore{clock: c, entries: make(map[string]*memoryEntry)}
+}
+
+func (s *memoryStore) Claim(_ context.Context, key, fingerprint string, ttl time.Duration) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	if e, ok := s.entries[key]; ok && now.Before(e.expiresAt) {
+		record := e.record
+		return &record, nil
+	}
+	s.entries[key] = &memoryEntry{
+		record:    Record{Fingerprint: fingerprint},
+		expiresAt: now.Add(ttl),
+	}
+	return nil, nil
+}
+
+func (s *memoryStore) Complete(_ context.Context, key string, resp Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok {
+		e.record.Response = &resp
+	}
+	return nil
+}
+
+func (s *memoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.entries[key]; ok && e.record.Response == nil {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+func (s *memoryStore) DeleteExpired(_ context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	var deleted int64
+	for key, e := range s.entries {
+		if !now.Before(e.expiresAt) {
+			delete(s.entries, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
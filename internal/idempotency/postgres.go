@@ -0,0 +1,86 @@
+// internal/idempotency/postgres.go
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+)
+
+type postgresStore struct {
+	queries *sqlc.Queries
+}
+
+// NewPostgresStore keeps keys in the idempotency_keys table, so they are
+// shared by every replica. Expiry uses the database clock.
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{queries: sqlc.New(db)}
+}
+
+func (s *postgresStore) Claim(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error) {
+	// A key released between the failed claim and the read is free again,
+	// so try once more before reporting it as in flight
+	for attempt := 0; attempt < 2; attempt++ {
+		claimed, err := s.queries.ClaimIdempotencyKey(ctx, sqlc.ClaimIdempotencyKeyParams{
+			Key:         key,
+			Fingerprint: fingerprint,
+			TtlSeconds:  ttl.Seconds(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if claimed > 0 {
+			return nil, nil
+		}
+
+		row, err := s.queries.GetIdempotencyKey(ctx, key)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return toRecord(row)
+	}
+	// Still contended: report it as in flight and let the client retry
+	return &Record{Fingerprint: fingerprint}, nil
+}
+
+func (s *postgresStore) Complete(ctx context.Context, key string, resp Response) error {
+	headers, err := json.Marshal(resp.Headers)
+	if err != nil {
+		return err
+	}
+	return s.queries.CompleteIdempotencyKey(ctx, sqlc.CompleteIdempotencyKeyParams{
+		Key:     key,
+		Status:  sql.NullInt32{Int32: int32(resp.Status), Valid: true},
+		Headers: headers,
+		Body:    resp.Body,
+	})
+}
+
+func (s *postgresStore) Release(ctx context.Context, key string) error {
+	return s.queries.ReleaseIdempotencyKey(ctx, key)
+}
+
+func (s *postgresStore) DeleteExpired(ctx context.Context) (int64, error) {
+	return s.queries.DeleteExpiredIdempotencyKeys(ctx)
+}
+
+func toRecord(row sqlc.IdempotencyKey) (*Record, error) {
+	record := &Record{Fingerprint: row.Fingerprint}
+	if !row.Status.Valid {
+		return record, nil
+	}
+
+	resp := &Response{Status: int(row.Status.Int32), Body: row.Body}
+	if err := json.Unmarshal(row.Headers, &resp.Headers); err != nil {
+		return nil, err
+	}
+	record.Response = resp
+	return record, nil
+}
@@ -0,0 +1,164 @@
+// internal/importer/importer.go
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+)
+
+// Format is the encoding of an import file
+type Format string
+
+const (
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+)
+
+// maxLineSize bounds a single NDJSON line
+const maxLineSize = 1024 * 1024
+
+// Row is one record of an import file. Err is set when the record could not
+// be decoded; reading can continue with the next record.
+type Row struct {
+	Line int
+	User models.CreateUserRequest
+	Err  error
+}
+
+// Reader streams the records of an import file. Next returns io.EOF after
+// the last record.
+type Reader interface {
+	Next() (Row, error)
+}
+
+// ParseFormat accepts a format name or a media type such as text/csv
+func ParseFormat(s string) (Format, bool) {
+	if mediaType, _, err := mime.ParseMediaType(s); err == nil {
+		s = mediaType
+	}
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "csv", "text/csv", "application/csv":
+		return CSV, true
+	case "ndjson", "jsonl", "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return NDJSON, true
+	default:
+		return "", false
+	}
+}
+
+// NewReader returns a Reader for r. CSV files need a header row naming the
+// name and dob columns and may have a timezone column; other columns are
+// ignored.
+func NewReader(r io.Reader, format Format) (Reader, error) {
+	switch format {
+	case CSV:
+		return newCSVReader(r)
+	case NDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+		return &ndjsonReader{scanner: scanner}, nil
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+}
+
+type csvReader struct {
+	reader      *csv.Reader
+	nameCol     int
+	dobCol      int
+	timezoneCol int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("CSV file is empty")
+		}
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	cr := &csvReader{reader: reader, nameCol: -1, dobCol: -1, timezoneCol: -1}
+	for i, column := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))) {
+		case "name":
+			cr.nameCol = i
+		case "dob":
+			cr.dobCol = i
+		case "timezone":
+			cr.timezoneCol = i
+		}
+	}
+	if cr.nameCol < 0 || cr.dobCol < 0 {
+		return nil, errors.New("CSV header must contain name and dob columns")
+	}
+	return cr, nil
+}
+
+func (r *csvReader) Next() (Row, error) {
+	record, err := r.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return Row{}, io.EOF
+	}
+
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return Row{Line: parseErr.StartLine, Err: parseErr.Err}, nil
+	}
+	if err != nil {
+		return Row{}, err
+	}
+
+	line, _ := r.reader.FieldPos(0)
+	row := Row{Line: line}
+	if r.nameCol < len(record) {
+		row.User.Name = strings.TrimSpace(record[r.nameCol])
+	}
+	if r.dobCol < len(record) {
+		row.User.DOB = strings.TrimSpace(record[r.dobCol])
+	}
+	if r.timezoneCol >= 0 && r.timezoneCol < len(record) {
+		if tz := strings.TrimSpace(record[r.timezoneCol]); tz != "" {
+			row.User.Timezone = &tz
+		}
+	}
+	return row, nil
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *ndjsonReader) Next() (Row, error) {
+	for r.scanner.Scan() {
+		r.line++
+		text := strings.TrimSpace(r.scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		row := Row{Line: r.line}
+		if err := json.Unmarshal([]byte(text), &row.User); err != nil {
+			row.Err = err
+		}
+		row.User.Name = strings.TrimSpace(row.User.Name)
+		return row, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Row{}, err
+	}
+	return Row{}, io.EOF
+}
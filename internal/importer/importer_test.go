@@ -0,0 +1,95 @@
+// internal/importer/importer_test.go
+package importer
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func readAll(t *testing.T, r Reader) []Row {
+	t.Helper()
+	var rows []Row
+	for {
+		row, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	input := "\ufeffid,DOB,Name\n" +
+		"1,1990-05-10, Alice \n" +
+		"2,1985-01-02\n" +
+		"3,\"bad,quote\n"
+
+	r, err := NewReader(strings.NewReader(input), CSV)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	rows := readAll(t, r)
+
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+	if rows[0].Line != 2 || rows[0].User.Name != "Alice" || rows[0].User.DOB != "1990-05-10" {
+		t.Errorf("rows[0] = %+v", rows[0])
+	}
+	if rows[1].Line != 3 || rows[1].User.Name != "" || rows[1].User.DOB != "1985-01-02" {
+		t.Errorf("rows[1] = %+v", rows[1])
+	}
+	if rows[2].Line != 4 || rows[2].Err == nil {
+		t.Errorf("rows[2] = %+v, want a parse error on line 4", rows[2])
+	}
+}
+
+func TestCSVReader_MissingColumns(t *testing.T) {
+	if _, err := NewReader(strings.NewReader("name,birthday\n"), CSV); err == nil {
+		t.Error("NewReader() should reject a header without dob")
+	}
+}
+
+func TestNDJSONReader(t *testing.T) {
+	input := `{"name":"Alice","dob":"1990-05-10","email":"a@example.com"}
+
+{"name":"Bob",`
+
+	r, err := NewReader(strings.NewReader(input), NDJSON)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	rows := readAll(t, r)
+
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+	if rows[0].Line != 1 || rows[0].Err != nil || rows[0].User.Name != "Alice" {
+		t.Errorf("rows[0] = %+v", rows[0])
+	}
+	if rows[1].Line != 3 || rows[1].Err == nil {
+		t.Errorf("rows[1] = %+v, want a decode error on line 3", rows[1])
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := map[string]Format{
+		"csv":                     CSV,
+		"text/csv; charset=utf-8": CSV,
+		"NDJSON":                  NDJSON,
+		"application/x-ndjson":    NDJSON,
+		"application/json":        "",
+		"":                        "",
+	}
+	for input, want := range tests {
+		got, ok := ParseFormat(input)
+		if got != want || ok != (want != "") {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", input, got, ok, want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+// internal/live/live.go
+package live
+
+import (
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+)
+
+var (
+	// ErrClosed is returned by Register once the hub has shut down
+	ErrClosed = errors.New("live hub is closed")
+	// ErrTooManySubscriptions is returned by Subscribe when a client would
+	// watch more users than it may
+	ErrTooManySubscriptions = errors.New("too many subscriptions")
+	// ErrSlowClient is why a client whose queue filled up was dropped
+	ErrSlowClient = errors.New("client is not reading its messages")
+)
+
+// Config holds the per-client limits of a hub
+type Config struct {
+	// BufferSize is how many messages may wait to be written to a client
+	// before it is disconnected as too slow
+	BufferSize int
+	// MaxSubscriptions is how many users a client may watch at once
+	MaxSubscriptions int
+}
+
+// Hub pushes changes to the users each connected client has subscribed to
+type Hub struct {
+	mu      sync.Mutex
+	clients map[*Client]struct{}
+	// watchers holds the clients subscribed to each user
+	watchers map[int32]map[*Client]struct{}
+	closed   bool
+	config   Config
+}
+
+func NewHub(config Config) *Hub {
+	if config.BufferSize <= 0 {
+		config.BufferSize = 64
+	}
+	if config.MaxSubscriptions <= 0 {
+		config.MaxSubscriptions = 100
+	}
+	return &Hub{
+		clients:  map[*Client]struct{}{},
+		watchers: map[int32]map[*Client]struct{}{},
+		config:   config,
+	}
+}
+
+// Client is one connection's queue of outgoing messages and its
+// subscriptions. Messages are queued without blocking; a client whose queue
+// is full is disconnected rather than waited for.
+type Client struct {
+	hub  *Hub
+	send chan models.LiveMessage
+	done chan struct{}
+	ids  map[int32]struct{}
+	// reason is why done was closed
+	reason error
+}
+
+// Register connects a new client
+func (h *Hub) Register() (*Client, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, ErrClosed
+	}
+	c := &Client{
+		hub:  h,
+		send: make(chan models.LiveMessage, h.config.BufferSize),
+		done: make(chan struct{}),
+		ids:  map[int32]struct{}{},
+	}
+	h.clients[c] = struct{}{}
+	return c, nil
+}
+
+// Messages returns the client's queue of messages to write
+func (c *Client) Messages() <-chan models.LiveMessage {
+	return c.send
+}
+
+// Done is closed when the client is disconnected, by Close, by the hub
+// shutting down or for falling behind
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the client was disconnected, nil when it closed itself
+func (c *Client) Err() error {
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	return c.reason
+}
+
+// Send queues a message, disconnecting the client when its queue is full.
+// It reports whether the message was queued.
+func (c *Client) Send(msg models.LiveMessage) bool {
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	return c.hub.deliver(c, msg)
+}
+
+// Subscribe adds users to those the client watches and returns them all
+func (c *Client) Subscribe(ids []int32) ([]int32, error) {
+	h := c.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	added := 0
+	for _, id := range ids {
+		if _, ok := c.ids[id]; !ok {
+			added++
+		}
+	}
+	if len(c.ids)+added > h.config.MaxSubscriptions {
+		return nil, ErrTooManySubscriptions
+	}
+
+	for _, id := range ids {
+		c.ids[id] = struct{}{}
+		if h.watchers[id] == nil {
+			h.watchers[id] = map[*Client]struct{}{}
+		}
+		h.watchers[id][c] = struct{}{}
+	}
+	return c.subscriptions(), nil
+}
+
+// Unsubscribe removes users from those the client watches and returns the
+// rest
+func (c *Client) Unsubscribe(ids []int32) []int32 {
+	h := c.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, id := range ids {
+		h.unwatch(c, id)
+	}
+	return c.subscriptions()
+}
+
+func (c *Client) subscriptions() []int32 {
+	ids := make([]int32, 0, len(c.ids))
+	for id := range c.ids {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Close disconnects the client. It is safe to call more than once.
+func (c *Client) Close() {
+	c.hub.mu.Lock()
+	defer c.hub.mu.Unlock()
+	c.hub.drop(c, nil)
+}
+
+// Publish pushes a change to every client watching the user
+func (h *Hub) Publish(event string, userID int32, user *models.UserResponse) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	msg := models.LiveMessage{Type: models.LiveEvent, Event: event, UserID: userID, User: user}
+	for c := range h.watchers[userID] {
+		h.deliver(c, msg)
+	}
+}
+
+// Clients returns how many clients are connected
+func (h *Hub) Clients() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+// Close disconnects every client and refuses new ones
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for c := range h.clients {
+		h.drop(c, ErrClosed)
+	}
+}
+
+// deliver queues msg for c, dropping c when its queue is full; h.mu must be
+// held
+func (h *Hub) deliver(c *Client, msg models.LiveMessage) bool {
+	if _, ok := h.clients[c]; !ok {
+		return false
+	}
+	select {
+	case c.send <- msg:
+		return true
+	default:
+		h.drop(c, ErrSlowClient)
+		return false
+	}
+}
+
+// drop disconnects c for reason; h.mu must be held
+func (h *Hub) drop(c *Client, reason error) {
+	if _, ok := h.clients[c]; !ok {
+		return
+	}
+	delete(h.clients, c)
+	for id := range c.ids {
+		h.unwatch(c, id)
+	}
+	c.reason = reason
+	close(c.done)
+}
+
+// unwatch stops c watching a user; h.mu must be held
+func (h *Hub) unwatch(c *Client, id int32) {
+	delete(c.ids, id)
+	if watchers := h.watchers[id]; watchers != nil {
+		delete(watchers, c)
+		if len(watchers) == 0 {
+			delete(h.watchers, id)
+		}
+	}
+}
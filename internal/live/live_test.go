@@ -0,0 +1,147 @@
+// internal/live/live_test.go
+package live
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/outbox"
+	"github.com/shravanirajulu2004/go-user-api/internal/service"
+)
+
+// pushed drains the messages waiting for c and returns the events in them
+// as "<event> <user id>"
+func pushed(c *Client) []string {
+	var events []string
+	for {
+		select {
+		case msg := <-c.Messages():
+			events = append(events, fmt.Sprintf("%s %d", msg.Event, msg.UserID))
+		default:
+			return events
+		}
+	}
+}
+
+func TestHub_PushesToSubscribers(t *testing.T) {
+	h := NewHub(Config{})
+	alice, _ := h.Register()
+	bob, _ := h.Register()
+
+	if ids, err := alice.Subscribe([]int32{2, 1, 2}); err != nil || !slices.Equal(ids, []int32{1, 2}) {
+		t.Fatalf("Subscribe() = %v, %v; want [1 2]", ids, err)
+	}
+	_, _ = bob.Subscribe([]int32{2})
+
+	h.Publish(outbox.UserUpdated, 1, nil)
+	h.Publish(outbox.UserUpdated, 2, nil)
+	h.Publish(outbox.UserUpdated, 3, nil)
+	if got := pushed(alice); !slices.Equal(got, []string{"UserUpdated 1", "UserUpdated 2"}) {
+		t.Errorf("alice got %v", got)
+	}
+	if got := pushed(bob); !slices.Equal(got, []string{"UserUpdated 2"}) {
+		t.Errorf("bob got %v", got)
+	}
+
+	if ids := alice.Unsubscribe([]int32{1}); !slices.Equal(ids, []int32{2}) {
+		t.Errorf("Unsubscribe() = %v, want [2]", ids)
+	}
+	bob.Close()
+	h.Publish(outbox.UserDeleted, 1, nil)
+	h.Publish(outbox.UserDeleted, 2, nil)
+	if got := pushed(alice); !slices.Equal(got, []string{"UserDeleted 2"}) {
+		t.Errorf("alice got %v after unsubscribing from 1", got)
+	}
+	if n := h.Clients(); n != 1 {
+		t.Errorf("Clients() = %d, want 1", n)
+	}
+}
+
+func TestHub_Limits(t *testing.T) {
+	h := NewHub(Config{BufferSize: 2, MaxSubscriptions: 2})
+	c, _ := h.Register()
+
+	if _, err := c.Subscribe([]int32{1, 2, 3}); !errors.Is(err, ErrTooManySubscriptions) {
+		t.Errorf("Subscribe() of 3 users error = %v, want %v", err, ErrTooManySubscriptions)
+	}
+	if _, err := c.Subscribe([]int32{1, 2}); err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	for range 3 {
+		h.Publish(outbox.UserUpdated, 1, nil)
+	}
+	select {
+	case <-c.Done():
+	default:
+		t.Fatal("client with a full queue is still connected")
+	}
+	if err := c.Err(); !errors.Is(err, ErrSlowClient) {
+		t.Errorf("Err() = %v, want %v", err, ErrSlowClient)
+	}
+	if c.Send(models.LiveMessage{Type: models.LiveResult}) {
+		t.Error("Send() to a dropped client queued the message")
+	}
+}
+
+func TestHub_Close(t *testing.T) {
+	h := NewHub(Config{})
+	c, _ := h.Register()
+	h.Close()
+
+	<-c.Done()
+	if err := c.Err(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Err() = %v, want %v", err, ErrClosed)
+	}
+	if _, err := h.Register(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Register() after Close() error = %v, want %v", err, ErrClosed)
+	}
+}
+
+// stubService answers the calls NewService pushes, failing for user 404
+type stubService struct {
+	service.UserService
+}
+
+func (stubService) UpdateUser(_ context.Context, id int32, _ models.UpdateUserRequest, _ *int32) (*models.UserResponse, error) {
+	if id == 404 {
+		return nil, apperrors.NotFound("user %d not found", id)
+	}
+	return &models.UserResponse{ID: id}, nil
+}
+
+func (stubService) ExecuteBatch(context.Context, models.BatchRequest) ([]models.BatchItemResult, error) {
+	return []models.BatchItemResult{
+		{Op: models.BatchCreate, ID: 3, User: &models.UserResponse{ID: 3}},
+		{Op: models.BatchDelete, ID: 1},
+		{Op: models.BatchUpdate, ID: 2, Err: apperrors.Aborted("not applied")},
+	}, nil
+}
+
+func TestNewService_PushesSuccessfulChanges(t *testing.T) {
+	h := NewHub(Config{})
+	c, _ := h.Register()
+	_, _ = c.Subscribe([]int32{1, 2, 3, 404})
+	users := NewService(stubService{}, h)
+	ctx := context.Background()
+
+	if _, err := users.UpdateUser(ctx, 2, models.UpdateUserRequest{}, nil); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if _, err := users.UpdateUser(ctx, 404, models.UpdateUserRequest{}, nil); err == nil {
+		t.Fatal("UpdateUser() of a missing user succeeded")
+	}
+	if _, err := users.ExecuteBatch(ctx, models.BatchRequest{}); err != nil {
+		t.Fatalf("ExecuteBatch() error = %v", err)
+	}
+
+	want := []string{"UserUpdated 2", "UserCreated 3", "UserDeleted 1"}
+	if got := pushed(c); !slices.Equal(got, want) {
+		t.Errorf("pushed %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,90 @@
+// internal/live/service.go
+package live
+
+import (
+	"context"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/outbox"
+	"github.com/shravanirajulu2004/go-user-api/internal/service"
+)
+
+// notifyingService pushes each change made through the wrapped service to
+// the hub once it has succeeded
+type notifyingService struct {
+	service.UserService
+	hub *Hub
+}
+
+// NewService wraps users so that the users it creates, updates and deletes
+// are pushed to the clients of hub watching them. Imports are not pushed.
+func NewService(users service.UserService, hub *Hub) service.UserService {
+	return &notifyingService{UserService: users, hub: hub}
+}
+
+func (s *notifyingService) CreateUser(ctx context.Context, req models.CreateUserRequest) (*models.UserResponse, error) {
+	user, err := s.UserService.CreateUser(ctx, req)
+	if err == nil {
+		s.hub.Publish(outbox.UserCreated, user.ID, user)
+	}
+	return user, err
+}
+
+func (s *notifyingService) UpdateUser(ctx context.Context, id int32, req models.UpdateUserRequest, expectedVersion *int32) (*models.UserResponse, error) {
+	user, err := s.UserService.UpdateUser(ctx, id, req, expectedVersion)
+	if err == nil {
+		s.hub.Publish(outbox.UserUpdated, id, user)
+	}
+	return user, err
+}
+
+func (s *notifyingService) PatchUser(ctx context.Context, id int32, req models.PatchUserRequest, expectedVersion *int32) (*models.UserResponse, error) {
+	user, err := s.UserService.PatchUser(ctx, id, req, expectedVersion)
+	if err == nil {
+		s.hub.Publish(outbox.UserUpdated, id, user)
+	}
+	return user, err
+}
+
+func (s *notifyingService) DeleteUser(ctx context.Context, id int32, expectedVersion *int32) error {
+	err := s.UserService.DeleteUser(ctx, id, expectedVersion)
+	if err == nil {
+		s.hub.Publish(outbox.UserDeleted, id, nil)
+	}
+	return err
+}
+
+func (s *notifyingService) RestoreUser(ctx context.Context, id int32) (*models.UserResponse, error) {
+	user, err := s.UserService.RestoreUser(ctx, id)
+	if err == nil {
+		s.hub.Publish(outbox.UserUpdated, id, user)
+	}
+	return user, err
+}
+
+func (s *notifyingService) MergeUsers(ctx context.Context, id int32, req models.MergeUsersRequest, expectedVersion *int32) (*models.UserResponse, error) {
+	user, err := s.UserService.MergeUsers(ctx, id, req, expectedVersion)
+	if err == nil {
+		s.hub.Publish(outbox.UserUpdated, id, user)
+		s.hub.Publish(outbox.UserDeleted, req.SourceID, nil)
+	}
+	return user, err
+}
+
+func (s *notifyingService) ExecuteBatch(ctx context.Context, req models.BatchRequest) ([]models.BatchItemResult, error) {
+	results, err := s.UserService.ExecuteBatch(ctx, req)
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		switch result.Op {
+		case models.BatchCreate:
+			s.hub.Publish(outbox.UserCreated, result.ID, result.User)
+		case models.BatchUpdate:
+			s.hub.Publish(outbox.UserUpdated, result.ID, result.User)
+		case models.BatchDelete:
+			s.hub.Publish(outbox.UserDeleted, result.ID, nil)
+		}
+	}
+	return results, err
+}
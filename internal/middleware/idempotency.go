@@ -0,0 +1,139 @@
+// internal/middleware/idempotency.go
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/idempotency"
+	"github.com/shravanirajulu2004/go-user-api/internal/problem"
+	"go.uber.org/zap"
+)
+
+const (
+	// HeaderIdempotencyKey names the key a client retries a request with
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed marks a response replayed from the store
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// replayedHeaders are the response headers stored along with the body
+var replayedHeaders = []string{fiber.HeaderContentType, fiber.HeaderLocation, fiber.HeaderETag}
+
+// Idempotency makes requests carrying an Idempotency-Key safe to retry. The
+// first request with a key runs and its response is stored for ttl; repeats
+// get the stored response back without running again. Reusing a key for a
+// different request is rejected with 422, and repeating one that is still
+// running with 409. Server errors are not stored, so they can be retried.
+// Keys are scoped to whoever sent them, so clients cannot replay each
+// other's responses by guessing keys.
+func Idempotency(store idempotency.Store, ttl time.Duration, logger *zap.Logger) fiber.Handler {
+	return func(c *fiber.Ctx) (err error) {
+		key := c.Get(HeaderIdempotencyKey)
+		if key == "" {
+			return c.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return problem.New(c, fiber.StatusBadRequest, "", "Idempotency-Key must be at most 255 characters").Write(c)
+		}
+
+		key = scopedKey(c, key)
+		fingerprint := requestFingerprint(c)
+		record, err := store.Claim(c.Context(), key, fingerprint, ttl)
+		if err != nil {
+			logger.Error("Failed to claim idempotency key", zap.Error(err))
+			return problem.New(c, fiber.StatusServiceUnavailable, "", "Idempotency keys are unavailable").Write(c)
+		}
+		if record != nil {
+			return replay(c, record, fingerprint)
+		}
+
+		// Free the key if the handler panics, then let recovery handle it
+		defer func() {
+			if r := recover(); r != nil {
+				release(c, store, key, logger)
+				panic(r)
+			}
+		}()
+
+		if err := c.Next(); err != nil {
+			// Render the error now so the final response can be stored
+			if err := c.App().Config().ErrorHandler(c, err); err != nil {
+				release(c, store, key, logger)
+				return err
+			}
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			release(c, store, key, logger)
+			return nil
+		}
+
+		resp := idempotency.Response{
+			Status:  status,
+			Headers: make(map[string]string),
+			Body:    append([]byte(nil), c.Response().Body()...),
+		}
+		for _, name := range replayedHeaders {
+			if value := c.GetRespHeader(name); value != "" {
+				resp.Headers[name] = value
+			}
+		}
+		if err := store.Complete(c.Context(), key, resp); err != nil {
+			logger.Error("Failed to store idempotent response", zap.Error(err))
+			release(c, store, key, logger)
+		}
+		return nil
+	}
+}
+
+func replay(c *fiber.Ctx, record *idempotency.Record, fingerprint string) error {
+	if record.Fingerprint != fingerprint {
+		return problem.New(c, fiber.StatusUnprocessableEntity, "/problems/idempotency-key-reused",
+			"Idempotency-Key was already used for a different request").Write(c)
+	}
+	if record.Response == nil {
+		return problem.New(c, fiber.StatusConflict, "/problems/idempotency-key-in-flight",
+			"A request with this Idempotency-Key is still being processed").Write(c)
+	}
+
+	for name, value := range record.Response.Headers {
+		c.Set(name, value)
+	}
+	c.Set(HeaderIdempotentReplayed, "true")
+	return c.Status(record.Response.Status).Send(record.Response.Body)
+}
+
+func release(c *fiber.Ctx, store idempotency.Store, key string, logger *zap.Logger) {
+	if err := store.Release(c.Context(), key); err != nil {
+		logger.Error("Failed to release idempotency key", zap.Error(err))
+	}
+}
+
+// scopedKey is the stored form of a request's key, prefixed with whose
+// keys it is one of: the actor a gateway set in X-Actor, admins, or else
+// the client's IP. Header values cannot contain newlines, so a scope never
+// runs into the key after it.
+func scopedKey(c *fiber.Ctx, key string) string {
+	scope := "ip:" + c.IP()
+	if actor := c.Get("X-Actor"); actor != "" {
+		scope = "actor:" + actor
+	} else if IsAdmin(c) {
+		scope = "admin"
+	}
+	return scope + "\n" + key
+}
+
+// requestFingerprint identifies a request by its method, URL, content type,
+// the representation it accepts and its body
+func requestFingerprint(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method() + "\n" + c.OriginalURL() + "\n" + c.Get(fiber.HeaderContentType) + "\n" + c.Get(fiber.HeaderAccept) + "\n"))
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
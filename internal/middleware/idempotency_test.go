@@ -0,0 +1,206 @@
+// internal/middleware/idempotency_test.go
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/shravanirajulu2004/go-user-api/internal/clock"
+	"github.com/shravanirajulu2004/go-user-api/internal/idempotency"
+	"go.uber.org/zap"
+)
+
+func newIdempotentApp(store idempotency.Store, status *int) (*fiber.App, *int) {
+	calls := 0
+	app := fiber.New()
+	app.Post("/users", Idempotency(store, time.Hour, zap.NewNop()), func(c *fiber.Ctx) error {
+		calls++
+		if *status >= fiber.StatusInternalServerError {
+			return fiber.NewError(*status, "boom")
+		}
+		c.Set(fiber.HeaderLocation, fmt.Sprintf("/users/%d", calls))
+		return c.Status(*status).JSON(fiber.Map{"id": calls})
+	})
+	return app, &calls
+}
+
+func post(t *testing.T, app *fiber.App, key, body string) (int, string, map[string]string) {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodPost, "/users", strings.NewReader(body))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	headers := map[string]string{
+		fiber.HeaderLocation:     resp.Header.Get(fiber.HeaderLocation),
+		HeaderIdempotentReplayed: resp.Header.Get(HeaderIdempotentReplayed),
+		fiber.HeaderContentType:  resp.Header.Get(fiber.HeaderContentType),
+	}
+	return resp.StatusCode, string(data), headers
+}
+
+func TestIdempotency(t *testing.T) {
+	status := fiber.StatusCreated
+	app, calls := newIdempotentApp(idempotency.NewMemoryStore(nil), &status)
+
+	code, body, headers := post(t, app, "k1", `{"name":"Alice"}`)
+	if code != fiber.StatusCreated || body != `{"id":1}` || headers[HeaderIdempotentReplayed] != "" {
+		t.Fatalf("first request = %d %s %v", code, body, headers)
+	}
+
+	code, body, headers = post(t, app, "k1", `{"name":"Alice"}`)
+	if code != fiber.StatusCreated || body != `{"id":1}` {
+		t.Errorf("replay = %d %s, want 201 {\"id\":1}", code, body)
+	}
+	if headers[HeaderIdempotentReplayed] != "true" || headers[fiber.HeaderLocation] != "/users/1" ||
+		!strings.HasPrefix(headers[fiber.HeaderContentType], fiber.MIMEApplicationJSON) {
+		t.Errorf("replay headers = %v", headers)
+	}
+	if *calls != 1 {
+		t.Errorf("handler ran %d times, want 1", *calls)
+	}
+
+	if code, _, _ = post(t, app, "k1", `{"name":"Bob"}`); code != fiber.StatusUnprocessableEntity {
+		t.Errorf("reuse with another body = %d, want 422", code)
+	}
+
+	if code, body, _ = post(t, app, "", `{"name":"Alice"}`); code != fiber.StatusCreated || body != `{"id":2}` {
+		t.Errorf("request without a key = %d %s, want a new user", code, body)
+	}
+	if code, _, _ = post(t, app, strings.Repeat("k", 256), `{}`); code != fiber.StatusBadRequest {
+		t.Errorf("overlong key = %d, want 400", code)
+	}
+}
+
+func TestIdempotency_InFlight(t *testing.T) {
+	store := idempotency.NewMemoryStore(nil)
+	status := fiber.StatusCreated
+	app, _ := newIdempotentApp(store, &status)
+
+	// Claim the key as a concurrent request would, with the same fingerprint
+	probe := fiber.New()
+	var key, fingerprint string
+	probe.Post("/users", func(c *fiber.Ctx) error {
+		key, fingerprint = scopedKey(c, "k1"), requestFingerprint(c)
+		return nil
+	})
+	req := httptest.NewRequest(fiber.MethodPost, "/users", strings.NewReader(`{}`))
+	req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	if _, err := probe.Test(req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Claim(context.Background(), key, fingerprint, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, _, _ := post(t, app, "k1", `{}`); code != fiber.StatusConflict {
+		t.Errorf("request while in flight = %d, want 409", code)
+	}
+}
+
+func TestIdempotency_ServerErrorsAreRetried(t *testing.T) {
+	status := fiber.StatusServiceUnavailable
+	app, calls := newIdempotentApp(idempotency.NewMemoryStore(nil), &status)
+
+	if code, _, _ := post(t, app, "k1", `{}`); code != fiber.StatusServiceUnavailable {
+		t.Fatalf("first request = %d, want 503", code)
+	}
+	status = fiber.StatusCreated
+	if code, body, _ := post(t, app, "k1", `{}`); code != fiber.StatusCreated || body != `{"id":2}` {
+		t.Errorf("retry = %d %s, want the handler to run again", code, body)
+	}
+	if *calls != 2 {
+		t.Errorf("handler ran %d times, want 2", *calls)
+	}
+}
+
+func TestIdempotency_KeysExpire(t *testing.T) {
+	now := clock.NewFake(time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC))
+	store := idempotency.NewMemoryStore(now)
+	status := fiber.StatusCreated
+	app, _ := newIdempotentApp(store, &status)
+
+	post(t, app, "k1", `{}`)
+	now.Advance(time.Hour)
+
+	if _, body, _ := post(t, app, "k1", `{}`); body != `{"id":2}` {
+		t.Errorf("request after expiry = %s, want a new user", body)
+	}
+	now.Advance(time.Hour)
+	if deleted, _ := store.DeleteExpired(context.Background()); deleted != 1 {
+		t.Errorf("DeleteExpired() = %d, want 1", deleted)
+	}
+}
+
+func TestIdempotency_ScopesKeysToTheirSender(t *testing.T) {
+	status := fiber.StatusCreated
+	app, calls := newIdempotentApp(idempotency.NewMemoryStore(nil), &status)
+
+	send := func(actor string) string {
+		t.Helper()
+		req := httptest.NewRequest(fiber.MethodPost, "/users", strings.NewReader(`{"name":"Alice"}`))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set(HeaderIdempotencyKey, "k1")
+		req.Header.Set("X-Actor", actor)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		return resp.Header.Get(HeaderIdempotentReplayed)
+	}
+
+	if replayed := send("alice"); replayed != "" {
+		t.Fatal("first request by alice was replayed")
+	}
+	if replayed := send("bob"); replayed != "" {
+		t.Error("bob got alice's response for the same key")
+	}
+	if replayed := send("alice"); replayed != "true" {
+		t.Error("alice's retry was not replayed")
+	}
+	if *calls != 2 {
+		t.Errorf("handler ran %d times, want 2", *calls)
+	}
+}
+
+func TestIdempotency_FingerprintsAccept(t *testing.T) {
+	status := fiber.StatusCreated
+	app, calls := newIdempotentApp(idempotency.NewMemoryStore(nil), &status)
+
+	send := func(accept string) int {
+		t.Helper()
+		req := httptest.NewRequest(fiber.MethodPost, "/users", strings.NewReader(`{"name":"Alice"}`))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set(fiber.HeaderAccept, accept)
+		req.Header.Set(HeaderIdempotencyKey, "k1")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := send(fiber.MIMEApplicationXML); code != fiber.StatusCreated {
+		t.Fatalf("first request = %d, want 201", code)
+	}
+	if code := send(fiber.MIMEApplicationJSON); code != fiber.StatusUnprocessableEntity {
+		t.Errorf("retry accepting another representation = %d, want 422", code)
+	}
+	if *calls != 1 {
+		t.Errorf("handler ran %d times, want 1", *calls)
+	}
+}
@@ -2,10 +2,12 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/shravanirajulu2004/go-user-api/internal/problem"
 	"go.uber.org/zap"
 )
 
@@ -63,11 +65,49 @@ func RecoveryMiddleware(logger *zap.Logger) fiber.Handler {
 					zap.String("request_id", requestID),
 					zap.Any("panic", r),
 				)
-				c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-					"error": "Internal server error",
-				})
+				problem.New(c, fiber.StatusInternalServerError, "", "Internal server error").Write(c)
 			}
 		}()
 		return c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// AdminMiddleware marks requests sending apiKey in X-Admin-Key as admin. An
+// empty apiKey disables admin access entirely.
+func AdminMiddleware(apiKey string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get("X-Admin-Key")
+		isAdmin := apiKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) == 1
+		c.Locals("isAdmin", isAdmin)
+		return c.Next()
+	}
+}
+
+// RequireAdmin rejects requests not marked as admin by AdminMiddleware
+func RequireAdmin() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !IsAdmin(c) {
+			return problem.New(c, fiber.StatusForbidden, "", "Admin access required").Write(c)
+		}
+		return c.Next()
+	}
+}
+
+// IsAdmin reports whether the request was authenticated as admin
+func IsAdmin(c *fiber.Ctx) bool {
+	isAdmin, _ := c.Locals("isAdmin").(bool)
+	return isAdmin
+}
+
+// Actor names who made the request for audit logs: the X-Actor header when
+// sent, otherwise admin or anonymous. X-Actor is taken on trust, so only
+// rely on it behind a gateway that sets it.
+func Actor(c *fiber.Ctx) string {
+	if actor := c.Get("X-Actor"); actor != "" {
+		return actor
+	}
+	if IsAdmin(c) {
+		return "admin"
+	}
+	return "anonymous"
+}
@@ -0,0 +1,81 @@
+// internal/migrate/migrate_test.go
+package migrate
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/shravanirajulu2004/go-user-api/db/migrations"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_add_email.sql": {Data: []byte("-- +migrate Up\nALTER TABLE users ADD email TEXT;\n\n-- +migrate Down\nALTER TABLE users DROP email;\n")},
+		"001_init.sql":      {Data: []byte("CREATE TABLE users (id INT);\n")},
+		"README.md":         {Data: []byte("ignored")},
+	}
+
+	got, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("Load() returned %d migrations, want 2", len(got))
+	}
+	if got[0].Version != 1 || got[0].Name != "init" {
+		t.Errorf("first migration = %d_%s, want 1_init", got[0].Version, got[0].Name)
+	}
+	if got[0].Up != "CREATE TABLE users (id INT);" || got[0].Down != "" {
+		t.Errorf("unmarked file parsed as up=%q down=%q", got[0].Up, got[0].Down)
+	}
+	if got[1].Up != "ALTER TABLE users ADD email TEXT;" {
+		t.Errorf("up section = %q", got[1].Up)
+	}
+	if got[1].Down != "ALTER TABLE users DROP email;" {
+		t.Errorf("down section = %q", got[1].Down)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{
+			name: "Missing version",
+			fsys: fstest.MapFS{"init.sql": {Data: []byte("SELECT 1;")}},
+		},
+		{
+			name: "Duplicate version",
+			fsys: fstest.MapFS{
+				"001_a.sql": {Data: []byte("SELECT 1;")},
+				"01_b.sql":  {Data: []byte("SELECT 1;")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.fsys); err == nil {
+				t.Error("Load() expected an error")
+			}
+		})
+	}
+}
+
+func TestLoad_Embedded(t *testing.T) {
+	got, err := Load(migrations.FS)
+	if err != nil {
+		t.Fatalf("Load(migrations.FS) error = %v", err)
+	}
+
+	for i, m := range got {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %s has version %d, want %d", m.Name, m.Version, i+1)
+		}
+		if m.Up == "" || m.Down == "" {
+			t.Errorf("migration %d_%s must have both up and down sections", m.Version, m.Name)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// internal/models/batch.go
+package models
+
+import (
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+)
+
+// BatchMode controls how a batch reacts to a failing operation
+type BatchMode string
+
+const (
+	// BatchAtomic applies every operation in one transaction, or none
+	BatchAtomic BatchMode = "atomic"
+	// BatchBestEffort applies each operation on its own
+	BatchBestEffort BatchMode = "best_effort"
+)
+
+// BatchOp is the kind of a batch operation
+type BatchOp string
+
+const (
+	BatchCreate BatchOp = "create"
+	BatchUpdate BatchOp = "update"
+	BatchDelete BatchOp = "delete"
+)
+
+// BatchRequest is the body of POST /users/batch. Mode defaults to atomic and
+// at most 1000 operations are accepted.
+type BatchRequest struct {
+	Mode       BatchMode        `json:"mode" validate:"omitempty,oneof=atomic best_effort"`
+	Operations []BatchOperation `json:"operations" validate:"required,min=1,max=1000"`
+}
+
+// BatchOperation creates, updates or deletes one user. ID is required for
+// update and delete; Version, when set, guards them like If-Match.
+type BatchOperation struct {
+	Op       BatchOp `json:"op"`
+	ID       int32   `json:"id,omitempty"`
+	Name     string  `json:"name,omitempty"`
+	DOB      string  `json:"dob,omitempty"`
+	Timezone *string `json:"timezone,omitempty"`
+	Version  *int32  `json:"version,omitempty"`
+}
+
+// BatchItemResult is the outcome of the operation at Index. Err is nil when
+// the operation was applied.
+type BatchItemResult struct {
+	Index int
+	Op    BatchOp
+	ID    int32
+	User  *UserResponse
+	Err   error
+}
+
+// Validate validates BatchRequest. Operations are validated one by one so
+// that each gets its own result.
+func (r *BatchRequest) Validate() error {
+	return validationError(validate.Struct(r))
+}
+
+// Validate validates BatchOperation against the fields its op needs
+func (o *BatchOperation) Validate() error {
+	switch o.Op {
+	case BatchCreate:
+		return (&CreateUserRequest{Name: o.Name, DOB: o.DOB, Timezone: o.Timezone}).Validate()
+	case BatchUpdate:
+		if err := o.validateID(); err != nil {
+			return err
+		}
+		return (&UpdateUserRequest{Name: o.Name, DOB: o.DOB, Timezone: o.Timezone}).Validate()
+	case BatchDelete:
+		return o.validateID()
+	default:
+		return apperrors.Validation("Validation failed", apperrors.FieldError{
+			Field:   "op",
+			Rule:    "oneof",
+			Message: "op must be one of: create, update, delete",
+		})
+	}
+}
+
+func (o *BatchOperation) validateID() error {
+	if o.ID < 1 {
+		return apperrors.Validation("Validation failed", apperrors.FieldError{
+			Field:   "id",
+			Rule:    "required",
+			Message: "id must be a positive integer",
+		})
+	}
+	return nil
+}
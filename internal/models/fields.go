@@ -0,0 +1,128 @@
+// internal/models/fields.go
+package models
+
+import (
+	"strings"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+)
+
+// UserFields is a set of UserResponse fields to return. The zero value
+// selects DefaultFields.
+type UserFields uint16
+
+const (
+	FieldID UserFields = 1 << iota
+	FieldName
+	FieldDOB
+	FieldAge
+	FieldTimezone
+	FieldDeletedAt
+	FieldMergedInto
+
+	// Computed from dob on request
+	FieldAgeYears
+	FieldAgeMonths
+	FieldAgeDays
+	FieldNextBirthday
+	FieldDaysUntilBirthday
+	FieldZodiacSign
+	FieldBirthWeek
+	FieldIsMinor
+
+	// DefaultFields is what a user is returned with when ?fields= is absent
+	DefaultFields = FieldID | FieldName | FieldDOB | FieldAge | FieldTimezone | FieldDeletedAt | FieldMergedInto
+
+	// AgeDetail is everything ?expand=age_detail adds
+	AgeDetail = FieldAgeYears | FieldAgeMonths | FieldAgeDays | FieldNextBirthday |
+		FieldDaysUntilBirthday | FieldZodiacSign | FieldBirthWeek | FieldIsMinor
+)
+
+// userFieldNames maps ?fields= names to fields, in response order
+var userFieldNames = []struct {
+	name  string
+	field UserFields
+}{
+	{"id", FieldID},
+	{"name", FieldName},
+	{"dob", FieldDOB},
+	{"age", FieldAge},
+	{"timezone", FieldTimezone},
+	{"deleted_at", FieldDeletedAt},
+	{"merged_into", FieldMergedInto},
+	{"age_years", FieldAgeYears},
+	{"age_months", FieldAgeMonths},
+	{"age_days", FieldAgeDays},
+	{"next_birthday", FieldNextBirthday},
+	{"days_until_birthday", FieldDaysUntilBirthday},
+	{"zodiac_sign", FieldZodiacSign},
+	{"birth_week", FieldBirthWeek},
+	{"is_minor", FieldIsMinor},
+}
+
+// Has reports whether f includes every field in field
+func (f UserFields) Has(field UserFields) bool {
+	return f&field == field
+}
+
+// Any reports whether f includes at least one field in fields
+func (f UserFields) Any(fields UserFields) bool {
+	return f&fields != 0
+}
+
+// ParseUserFields parses the comma separated ?fields= and ?expand= query
+// parameters. Without fields users get DefaultFields; with it, exactly the
+// listed fields plus id, which always identifies the user.
+func ParseUserFields(fields, expand string) (UserFields, error) {
+	selected := DefaultFields
+	if names := splitList(fields); len(names) > 0 {
+		selected = FieldID
+		for _, name := range names {
+			field, ok := lookupField(name)
+			if !ok {
+				valid := make([]string, 0, len(userFieldNames))
+				for _, f := range userFieldNames {
+					valid = append(valid, f.name)
+				}
+				return 0, apperrors.Validation("Invalid fields", apperrors.FieldError{
+					Field:   "fields",
+					Rule:    "fields",
+					Message: "fields must list fields from: " + strings.Join(valid, ", "),
+				})
+			}
+			selected |= field
+		}
+	}
+
+	for _, name := range splitList(expand) {
+		if name != "age_detail" {
+			return 0, apperrors.Validation("Invalid expand", apperrors.FieldError{
+				Field:   "expand",
+				Rule:    "oneof",
+				Message: "expand must be one of: age_detail",
+			})
+		}
+		selected |= AgeDetail
+	}
+
+	return selected, nil
+}
+
+func lookupField(name string) (UserFields, bool) {
+	for _, f := range userFieldNames {
+		if f.name == name {
+			return f.field, true
+		}
+	}
+	return 0, false
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
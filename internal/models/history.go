@@ -0,0 +1,43 @@
+// internal/models/history.go
+package models
+
+import "encoding/xml"
+
+// FieldChange is one field of a change set; From is null for a field that was
+// unset, such as every field of a new user
+type FieldChange struct {
+	Field string `json:"field" xml:"field,attr"`
+	From  any    `json:"from" xml:"from,omitempty"`
+	To    any    `json:"to" xml:"to,omitempty"`
+}
+
+// UserHistoryEntry is one change to a user, identified by the version it
+// produced. Action is create, update, delete, restore, merge (the survivor
+// of a merge), merged (the user merged away) or baseline (the state when
+// history began).
+type UserHistoryEntry struct {
+	XMLName   xml.Name      `json:"-" xml:"change"`
+	Version   int32         `json:"version" xml:"version"`
+	Action    string        `json:"action" xml:"action"`
+	Actor     string        `json:"actor,omitempty" xml:"actor,omitempty"`
+	RequestID string        `json:"request_id,omitempty" xml:"request_id,omitempty"`
+	Changes   []FieldChange `json:"changes" xml:"changes>field"`
+	ChangedAt string        `json:"changed_at" xml:"changed_at"`
+}
+
+// HistoryQuery holds the query parameters of GET /users/:id/history
+type HistoryQuery struct {
+	Limit  int    `query:"limit" json:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `query:"cursor" json:"cursor"`
+}
+
+// Validate validates HistoryQuery
+func (q *HistoryQuery) Validate() error {
+	return validationError(validate.Struct(q))
+}
+
+// HistoryPage is a page of a user's history, newest first
+type HistoryPage struct {
+	Entries    []UserHistoryEntry
+	NextCursor string
+}
@@ -0,0 +1,47 @@
+// internal/models/import.go
+package models
+
+import (
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+)
+
+// DedupKey selects which rows of an import count as the same user
+type DedupKey string
+
+const (
+	DedupNone    DedupKey = ""
+	DedupName    DedupKey = "name"
+	DedupNameDOB DedupKey = "name_dob"
+)
+
+// ImportOptions controls an import. Rows are inserted in chunks of
+// ChunkSize, each in its own transaction.
+type ImportOptions struct {
+	DryRun    bool     `query:"dry_run" json:"dry_run"`
+	Dedup     DedupKey `query:"dedup" json:"dedup" validate:"omitempty,oneof=name name_dob"`
+	ChunkSize int      `query:"chunk_size" json:"chunk_size" validate:"omitempty,min=1,max=10000"`
+}
+
+// ImportReport summarises an import. Imported counts the rows that would
+// be imported when DryRun is set.
+type ImportReport struct {
+	DryRun    bool             `json:"dry_run"`
+	Total     int              `json:"total"`
+	Imported  int64            `json:"imported"`
+	Skipped   int              `json:"skipped"`
+	Failed    int              `json:"failed"`
+	Errors    []ImportRowError `json:"errors"`
+	Truncated bool             `json:"errors_truncated,omitempty"`
+}
+
+// ImportRowError explains why the row at Line was not imported
+type ImportRowError struct {
+	Line    int                    `json:"line"`
+	Message string                 `json:"message"`
+	Fields  []apperrors.FieldError `json:"fields,omitempty"`
+}
+
+// Validate validates ImportOptions
+func (o *ImportOptions) Validate() error {
+	return validationError(validate.Struct(o))
+}
@@ -0,0 +1,62 @@
+// internal/models/live.go
+package models
+
+import "github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+
+// Types of the messages sent to /ws clients
+const (
+	LiveResult = "result"
+	LiveError  = "error"
+	LiveEvent  = "event"
+)
+
+// LiveRequest is a message from a /ws client. Type picks the operation:
+// subscribe and unsubscribe take UserIDs, get takes UserID and list takes
+// Filter, Limit and Cursor as GET /users does. ID is echoed in the reply.
+type LiveRequest struct {
+	ID      string     `json:"id" validate:"max=64"`
+	Type    string     `json:"type" validate:"required,oneof=subscribe unsubscribe get list"`
+	UserIDs []int32    `json:"user_ids" validate:"required_if=Type subscribe,required_if=Type unsubscribe,max=100,dive,min=1"`
+	UserID  int32      `json:"user_id" validate:"required_if=Type get,omitempty,min=1"`
+	Filter  UserFilter `json:"filter"`
+	Limit   int        `json:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor  string     `json:"cursor"`
+}
+
+// Validate validates LiveRequest
+func (r *LiveRequest) Validate() error {
+	return validationError(validate.Struct(r))
+}
+
+// LiveMessage is a message to a /ws client: the result of a request, the
+// error it failed with, or an event about a subscribed user. User is
+// missing from events of deleted users.
+type LiveMessage struct {
+	ID     string           `json:"id,omitempty"`
+	Type   string           `json:"type"`
+	Result any              `json:"result,omitempty"`
+	Error  *LiveErrorDetail `json:"error,omitempty"`
+	Event  string           `json:"event,omitempty"`
+	UserID int32            `json:"user_id,omitempty"`
+	User   *UserResponse    `json:"user,omitempty"`
+}
+
+// LiveErrorDetail describes a failed request with the status and message
+// the matching HTTP request would have had
+type LiveErrorDetail struct {
+	Status  int                    `json:"status"`
+	Message string                 `json:"message"`
+	Errors  []apperrors.FieldError `json:"errors,omitempty"`
+}
+
+// LiveSubscriptions is the result of subscribe and unsubscribe: every user
+// the client now watches
+type LiveSubscriptions struct {
+	UserIDs []int32 `json:"user_ids"`
+}
+
+// LiveUserList is the result of list
+type LiveUserList struct {
+	Users      []UserResponse `json:"users"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,49 @@
+// internal/models/merge.go
+package models
+
+import "encoding/xml"
+
+// MergeStrategy picks whose values the surviving user keeps in a merge
+type MergeStrategy string
+
+const (
+	// MergeKeepTarget keeps the survivor's values, taking only a missing
+	// timezone from the merged user
+	MergeKeepTarget MergeStrategy = "keep_target"
+	// MergeKeepSource takes the merged user's values, keeping the
+	// survivor's timezone when the merged user has none
+	MergeKeepSource MergeStrategy = "keep_source"
+	// MergeNewest keeps the values of whichever user was updated last
+	MergeNewest MergeStrategy = "newest"
+)
+
+// MergeSide names one of the two users in a merge
+type MergeSide string
+
+const (
+	MergeTarget MergeSide = "target"
+	MergeSource MergeSide = "source"
+)
+
+// MergeUsersRequest is the body of POST /users/:id/merge, which merges
+// SourceID into the user :id. Strategy defaults to keep_target; Fields
+// overrides it for single fields.
+type MergeUsersRequest struct {
+	XMLName  xml.Name      `json:"-" xml:"merge"`
+	SourceID int32         `json:"source_id" xml:"source_id" validate:"required,min=1"`
+	Strategy MergeStrategy `json:"strategy,omitempty" xml:"strategy,omitempty" validate:"omitempty,oneof=keep_target keep_source newest"`
+	Fields   MergeFields   `json:"fields" xml:"fields"`
+}
+
+// MergeFields names the user each field is taken from, exactly as stored
+// there; empty fields follow the strategy
+type MergeFields struct {
+	Name     MergeSide `json:"name,omitempty" xml:"name,omitempty" validate:"omitempty,oneof=target source"`
+	DOB      MergeSide `json:"dob,omitempty" xml:"dob,omitempty" validate:"omitempty,oneof=target source"`
+	Timezone MergeSide `json:"timezone,omitempty" xml:"timezone,omitempty" validate:"omitempty,oneof=target source"`
+}
+
+// Validate validates MergeUsersRequest
+func (r *MergeUsersRequest) Validate() error {
+	return validationError(validate.Struct(r))
+}
@@ -0,0 +1,51 @@
+// internal/models/stream.go
+package models
+
+import (
+	"strconv"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+)
+
+// eventTypes are the user event types clients can filter by
+var eventTypes = []string{"UserCreated", "UserUpdated", "UserDeleted"}
+
+// ParseStreamFilter parses the comma separated ?id= and ?type= query
+// parameters of GET /users/stream into the user IDs and event types to
+// stream. Empty lists stream everything.
+func ParseStreamFilter(ids, types string) ([]int32, []string, error) {
+	var userIDs []int32
+	for _, s := range splitList(ids) {
+		id, err := strconv.ParseInt(s, 10, 32)
+		if err != nil || id < 1 {
+			return nil, nil, apperrors.Validation("Invalid id", apperrors.FieldError{
+				Field:   "id",
+				Rule:    "numeric",
+				Message: "id must list positive integers",
+			})
+		}
+		userIDs = append(userIDs, int32(id))
+	}
+
+	names := splitList(types)
+	for _, name := range names {
+		if !isEventType(name) {
+			return nil, nil, apperrors.Validation("Invalid type", apperrors.FieldError{
+				Field:   "type",
+				Rule:    "oneof",
+				Message: "type must list types from: UserCreated, UserUpdated, UserDeleted",
+			})
+		}
+	}
+
+	return userIDs, names, nil
+}
+
+func isEventType(name string) bool {
+	for _, t := range eventTypes {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@
 package models
 
 import (
+	"encoding/xml"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -10,35 +11,175 @@ import (
 var validate = validator.New()
 
 type CreateUserRequest struct {
-	Name string `json:"name" validate:"required,min=1,max=255"`
-	DOB  string `json:"dob" validate:"required,datetime=2006-01-02"`
+	XMLName xml.Name `json:"-" xml:"user"`
+	Name    string   `json:"name" xml:"name" validate:"required,min=1,max=255"`
+	DOB     string   `json:"dob" xml:"dob" validate:"required,datetime=2006-01-02"`
+
+	// Timezone is the IANA zone whose midnight the user's age changes at;
+	// UTC when unset
+	Timezone *string `json:"timezone,omitempty" xml:"timezone,omitempty" validate:"omitempty,timezone"`
 }
 
 type UpdateUserRequest struct {
-	Name string `json:"name" validate:"required,min=1,max=255"`
-	DOB  string `json:"dob" validate:"required,datetime=2006-01-02"`
+	XMLName  xml.Name `json:"-" xml:"user"`
+	Name     string   `json:"name" xml:"name" validate:"required,min=1,max=255"`
+	DOB      string   `json:"dob" xml:"dob" validate:"required,datetime=2006-01-02"`
+	Timezone *string  `json:"timezone,omitempty" xml:"timezone,omitempty" validate:"omitempty,timezone"`
 }
 
+// PatchFormat is the media type of a PATCH /users/:id body
+type PatchFormat string
+
+const (
+	MergePatch PatchFormat = "application/merge-patch+json"
+	JSONPatch  PatchFormat = "application/json-patch+json"
+)
+
+// PatchUserRequest carries a raw RFC 7396 merge patch or RFC 6902 JSON patch
+// to apply to a user's name and dob
+type PatchUserRequest struct {
+	Format PatchFormat
+	Patch  []byte
+}
+
+// UserResponse is a user as returned by the API. Everything but ID is left
+// empty when ?fields= leaves it out.
 type UserResponse struct {
-	ID   int32  `json:"id"`
-	Name string `json:"name"`
-	DOB  string `json:"dob"`
-	Age  *int   `json:"age,omitempty"`
+	XMLName  xml.Name `json:"-" xml:"user"`
+	ID       int32    `json:"id" xml:"id"`
+	Name     string   `json:"name,omitempty" xml:"name,omitempty"`
+	DOB      string   `json:"dob,omitempty" xml:"dob,omitempty"`
+	Age      *int     `json:"age,omitempty" xml:"age,omitempty"`
+	Timezone *string  `json:"timezone,omitempty" xml:"timezone,omitempty"`
+
+	// DeletedAt is only set for soft-deleted users, which admins can list
+	DeletedAt *string `json:"deleted_at,omitempty" xml:"deleted_at,omitempty"`
+	// MergedInto is the surviving user a deleted user was merged into
+	MergedInto *int32 `json:"merged_into,omitempty" xml:"merged_into,omitempty"`
+
+	// Computed from dob when selected with ?fields= or ?expand=age_detail
+	AgeYears          *int    `json:"age_years,omitempty" xml:"age_years,omitempty"`
+	AgeMonths         *int    `json:"age_months,omitempty" xml:"age_months,omitempty"`
+	AgeDays           *int    `json:"age_days,omitempty" xml:"age_days,omitempty"`
+	NextBirthday      *string `json:"next_birthday,omitempty" xml:"next_birthday,omitempty"`
+	DaysUntilBirthday *int    `json:"days_until_birthday,omitempty" xml:"days_until_birthday,omitempty"`
+	ZodiacSign        *string `json:"zodiac_sign,omitempty" xml:"zodiac_sign,omitempty"`
+	BirthWeek         *string `json:"birth_week,omitempty" xml:"birth_week,omitempty"`
+	IsMinor           *bool   `json:"is_minor,omitempty" xml:"is_minor,omitempty"`
+
+	// PossibleDuplicates lists existing users a new user matched when the
+	// duplicate policy is warn
+	PossibleDuplicates []int32 `json:"possible_duplicates,omitempty" xml:"possible_duplicate,omitempty"`
+
+	// Version is the stored row version, exposed only through ETags
+	Version int32 `json:"-" xml:"-"`
+}
+
+// UserFilter holds the filtering and sorting query parameters of GET /users
+type UserFilter struct {
+	Name        string `query:"name" json:"name" validate:"omitempty,max=255"`
+	NameMatch   string `query:"name_match" json:"name_match" validate:"omitempty,oneof=exact prefix contains"`
+	DOBFrom     string `query:"dob_from" json:"dob_from" validate:"omitempty,datetime=2006-01-02"`
+	DOBTo       string `query:"dob_to" json:"dob_to" validate:"omitempty,datetime=2006-01-02"`
+	AgeMin      *int   `query:"age_min" json:"age_min" validate:"omitempty,min=0,max=200"`
+	AgeMax      *int   `query:"age_max" json:"age_max" validate:"omitempty,min=0,max=200"`
+	CreatedFrom string `query:"created_from" json:"created_from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	CreatedTo   string `query:"created_to" json:"created_to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	UpdatedFrom string `query:"updated_from" json:"updated_from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	UpdatedTo   string `query:"updated_to" json:"updated_to" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Sort        string `query:"sort" json:"sort" validate:"omitempty,max=255"`
+}
+
+// SearchUsersQuery holds the query parameters of GET /users/search.
+// MinSimilarity and Limit fall back to the service defaults when unset.
+type SearchUsersQuery struct {
+	Q             string   `query:"q" json:"q" validate:"required,max=255"`
+	MinSimilarity *float64 `query:"min_similarity" json:"min_similarity" validate:"omitempty,min=0,max=1"`
+	Limit         int      `query:"limit" json:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// UserSearchResult is a user matched by a search. Highlight is the name as
+// HTML with the matched words wrapped in <mark> tags.
+type UserSearchResult struct {
+	UserResponse
+	Rank      float64 `json:"rank" xml:"rank"`
+	Highlight string  `json:"highlight" xml:"highlight"`
+}
+
+// DuplicatesQuery holds the query parameters of GET /users/duplicates.
+// MinSimilarity falls back to the configured duplicate similarity; 0 only
+// matches identical normalized names.
+type DuplicatesQuery struct {
+	MinSimilarity *float64 `query:"min_similarity" json:"min_similarity" validate:"omitempty,min=0,max=1"`
+}
+
+// DuplicateCluster is a group of live users born the same day whose names
+// match, directly or through other members. Similarity is the lowest name
+// similarity of the matches joining them.
+type DuplicateCluster struct {
+	XMLName    xml.Name       `json:"-" xml:"cluster"`
+	DOB        string         `json:"dob" xml:"dob"`
+	Similarity float64        `json:"similarity" xml:"similarity"`
+	Users      []UserResponse `json:"users" xml:"users>user"`
+}
+
+// ListUsersQuery selects a page of users, either by page number or, when
+// UseCursor is set, by an opaque cursor from a previous page
+type ListUsersQuery struct {
+	Filter         UserFilter
+	Fields         UserFields
+	Page           int
+	PageSize       int
+	UseCursor      bool
+	Cursor         string
+	WithTotal      bool
+	IncludeDeleted bool
+}
+
+// UserPage is one page of a user list. Total is nil unless it was requested
+// or the list is paginated by page number.
+type UserPage struct {
+	Users      []UserResponse
+	Total      *int64
+	Page       int
+	PageSize   int
+	NextCursor string
+	PrevCursor string
 }
 
 // Validate validates CreateUserRequest
 func (r *CreateUserRequest) Validate() error {
-	return validate.Struct(r)
+	return validationError(validate.Struct(r))
 }
 
 // Validate validates UpdateUserRequest
 func (r *UpdateUserRequest) Validate() error {
-	return validate.Struct(r)
+	return validationError(validate.Struct(r))
+}
+
+// Validate validates UserFilter
+func (f *UserFilter) Validate() error {
+	return validationError(validate.Struct(f))
+}
+
+// Validate validates DuplicatesQuery
+func (q *DuplicatesQuery) Validate() error {
+	return validationError(validate.Struct(q))
+}
+
+// Validate validates SearchUsersQuery
+func (q *SearchUsersQuery) Validate() error {
+	return validationError(validate.Struct(q))
 }
 
-// CalculateAge calculates age from date of birth
+// CalculateAge calculates age from date of birth as of the current time
 func CalculateAge(dob time.Time) int {
-	now := time.Now()
+	return CalculateAgeAt(dob, time.Now())
+}
+
+// CalculateAgeAt calculates age from date of birth on the calendar date of
+// now in its own location, so the age changes at that location's midnight
+func CalculateAgeAt(dob, now time.Time) int {
 	age := now.Year() - dob.Year()
 
 	// Adjust if birthday hasn't occurred this year
@@ -47,4 +188,4 @@ func CalculateAge(dob time.Time) int {
 	}
 
 	return age
-}
\ No newline at end of file
+}
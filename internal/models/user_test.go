@@ -121,20 +121,60 @@ func TestCalculateAge_Explicit(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			dob, _ := time.Parse("2006-01-02", tt.dob)
-			
-			// This test assumes current date - if you want to test with specific dates,
-			// you'd need to modify CalculateAge to accept a "now" parameter
-			got := CalculateAge(dob)
-			
-			// For now, just verify it returns a positive integer
-			if got < 0 {
-				t.Errorf("CalculateAge() returned negative age: %v", got)
+			now, _ := time.Parse("2006-01-02", tt.testDate)
+
+			got := CalculateAgeAt(dob, now)
+			if got != tt.wantAge {
+				t.Errorf("CalculateAgeAt() = %v, want %v (dob=%s, now=%s)", got, tt.wantAge, tt.dob, tt.testDate)
 			}
-			
-			// Verify age is reasonable (between 0 and 150 years)
-			if got > 150 {
-				t.Errorf("CalculateAge() returned unreasonable age: %v", got)
+		})
+	}
+}
+
+func TestCalculateAgeAt_Timezone(t *testing.T) {
+	dob, _ := time.Parse("2006-01-02", "1990-05-10")
+	// 20:00 UTC on the 9th is already the 10th in Kolkata (UTC+5:30)
+	instant := time.Date(2024, 5, 9, 20, 0, 0, 0, time.UTC)
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	if got := CalculateAgeAt(dob, instant); got != 33 {
+		t.Errorf("CalculateAgeAt() in UTC = %v, want 33", got)
+	}
+	if got := CalculateAgeAt(dob, instant.In(kolkata)); got != 34 {
+		t.Errorf("CalculateAgeAt() in Asia/Kolkata = %v, want 34", got)
+	}
+}
+
+func TestParseUserFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  string
+		expand  string
+		want    UserFields
+		wantErr bool
+	}{
+		{name: "None", want: DefaultFields},
+		{name: "Sparse", fields: "id,name", want: FieldID | FieldName},
+		{name: "Id is always included", fields: "name", want: FieldID | FieldName},
+		{name: "Computed fields", fields: "next_birthday, is_minor", want: FieldID | FieldNextBirthday | FieldIsMinor},
+		{name: "Expand", expand: "age_detail", want: DefaultFields | AgeDetail},
+		{name: "Expand with fields", fields: "name", expand: "age_detail", want: FieldID | FieldName | AgeDetail},
+		{name: "Unknown field", fields: "shoe_size", wantErr: true},
+		{name: "Unknown expansion", expand: "friends", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseUserFields(tt.fields, tt.expand)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUserFields() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseUserFields() = %b, want %b", got, tt.want)
 			}
 		})
 	}
-}
\ No newline at end of file
+}
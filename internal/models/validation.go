@@ -0,0 +1,75 @@
+// internal/models/validation.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+)
+
+func init() {
+	// Report fields by their JSON names so clients can match them to inputs
+	validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+}
+
+// validationError converts validator errors into a domain validation error
+func validationError(err error) error {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+
+	fields := make([]apperrors.FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		fields = append(fields, apperrors.FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: fieldMessage(fe),
+		})
+	}
+
+	return apperrors.Validation("Validation failed", fields...)
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fe.Field())
+	case "min":
+		if fe.Kind() != reflect.String {
+			return fmt.Sprintf("%s must be at least %s", fe.Field(), fe.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s characters long", fe.Field(), fe.Param())
+	case "max":
+		if fe.Kind() != reflect.String {
+			return fmt.Sprintf("%s must be at most %s", fe.Field(), fe.Param())
+		}
+		return fmt.Sprintf("%s must be at most %s characters long", fe.Field(), fe.Param())
+	case "datetime":
+		if fe.Param() != "2006-01-02" {
+			return fmt.Sprintf("%s must be an RFC 3339 timestamp", fe.Field())
+		}
+		return fmt.Sprintf("%s must be a date in YYYY-MM-DD format", fe.Field())
+	case "timezone":
+		return fmt.Sprintf("%s must be an IANA time zone such as Asia/Kolkata", fe.Field())
+	case "http_url":
+		return fmt.Sprintf("%s must be an http or https URL", fe.Field())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", fe.Field(), strings.ReplaceAll(fe.Param(), " ", ", "))
+	default:
+		return fmt.Sprintf("%s failed the %s rule", fe.Field(), fe.Tag())
+	}
+}
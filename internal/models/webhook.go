@@ -0,0 +1,85 @@
+// internal/models/webhook.go
+package models
+
+import (
+	"encoding/json"
+	"encoding/xml"
+)
+
+// WebhookRequest is the body of POST /webhooks and PUT /webhooks/:id. An
+// empty EventTypes subscribes to every event. Secret signs deliveries: one
+// is generated on create when it is empty, and an update without it keeps
+// the current one. Active defaults to true.
+type WebhookRequest struct {
+	XMLName     xml.Name `json:"-" xml:"webhook"`
+	URL         string   `json:"url" xml:"url" validate:"required,http_url,max=2048"`
+	EventTypes  []string `json:"event_types" xml:"event_types>event_type" validate:"max=3,dive,oneof=UserCreated UserUpdated UserDeleted"`
+	Secret      string   `json:"secret,omitempty" xml:"secret,omitempty" validate:"omitempty,min=16,max=256"`
+	Description string   `json:"description,omitempty" xml:"description,omitempty" validate:"max=255"`
+	Active      *bool    `json:"active,omitempty" xml:"active,omitempty"`
+}
+
+// Validate validates WebhookRequest
+func (r *WebhookRequest) Validate() error {
+	return validationError(validate.Struct(r))
+}
+
+// WebhookResponse is a webhook subscription. Its secret is only returned
+// when it is set, on create or by an update.
+type WebhookResponse struct {
+	XMLName     xml.Name `json:"-" xml:"webhook"`
+	ID          int32    `json:"id" xml:"id"`
+	URL         string   `json:"url" xml:"url"`
+	EventTypes  []string `json:"event_types" xml:"event_types>event_type"`
+	Description string   `json:"description" xml:"description"`
+	Active      bool     `json:"active" xml:"active"`
+	Secret      string   `json:"secret,omitempty" xml:"secret,omitempty"`
+	CreatedAt   string   `json:"created_at" xml:"created_at"`
+	UpdatedAt   string   `json:"updated_at" xml:"updated_at"`
+}
+
+// WebhookDeliveriesQuery holds the query parameters of the delivery lists
+type WebhookDeliveriesQuery struct {
+	Status string `query:"status" json:"status" validate:"omitempty,oneof=pending delivered dead"`
+	Limit  int    `query:"limit" json:"limit" validate:"omitempty,min=1,max=100"`
+	Cursor string `query:"cursor" json:"cursor"`
+}
+
+// Validate validates WebhookDeliveriesQuery
+func (q *WebhookDeliveriesQuery) Validate() error {
+	return validationError(validate.Struct(q))
+}
+
+// WebhookDeliveryResponse is one event queued for one webhook. Payload and
+// Log, its attempts oldest first, are only filled in for a single delivery.
+type WebhookDeliveryResponse struct {
+	XMLName        xml.Name         `json:"-" xml:"delivery"`
+	ID             int64            `json:"id" xml:"id"`
+	WebhookID      int32            `json:"webhook_id" xml:"webhook_id"`
+	EventID        int64            `json:"event_id" xml:"event_id"`
+	EventType      string           `json:"event_type" xml:"event_type"`
+	Status         string           `json:"status" xml:"status"`
+	Attempts       int32            `json:"attempts" xml:"attempts"`
+	NextAttemptAt  *string          `json:"next_attempt_at,omitempty" xml:"next_attempt_at,omitempty"`
+	LastStatusCode *int32           `json:"last_status_code,omitempty" xml:"last_status_code,omitempty"`
+	LastError      *string          `json:"last_error,omitempty" xml:"last_error,omitempty"`
+	CreatedAt      string           `json:"created_at" xml:"created_at"`
+	DeliveredAt    *string          `json:"delivered_at,omitempty" xml:"delivered_at,omitempty"`
+	Payload        json.RawMessage  `json:"payload,omitempty" xml:"payload,omitempty"`
+	Log            []WebhookAttempt `json:"log,omitempty" xml:"attempt,omitempty"`
+}
+
+// WebhookAttempt is one attempt at a delivery. StatusCode is missing when
+// no response arrived.
+type WebhookAttempt struct {
+	AttemptedAt string  `json:"attempted_at" xml:"attempted_at"`
+	StatusCode  *int32  `json:"status_code,omitempty" xml:"status_code,omitempty"`
+	Error       *string `json:"error,omitempty" xml:"error,omitempty"`
+	DurationMs  int32   `json:"duration_ms" xml:"duration_ms"`
+}
+
+// WebhookDeliveryPage is a page of deliveries, newest first
+type WebhookDeliveryPage struct {
+	Deliveries []WebhookDeliveryResponse
+	NextCursor string
+}
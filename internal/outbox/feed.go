@@ -0,0 +1,136 @@
+// internal/outbox/feed.go
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"go.uber.org/zap"
+)
+
+// notifyChannel is where the outbox_events trigger announces new events
+const notifyChannel = "outbox_events"
+
+// catchUpBatch is how many events are read at a time when catching up after
+// a lost connection
+const catchUpBatch = 500
+
+// Feed publishes every event committed to the outbox to a sink, on every
+// replica and as soon as it commits. Unlike the relay it takes no lock and
+// never retries, so it suits in-process sinks that each replica must feed
+// itself and that should not wait on other sinks, such as the event stream.
+type Feed struct {
+	databaseURL string
+	queries     *sqlc.Queries
+	sink        Sink
+	logger      *zap.Logger
+	// last is the highest event ID published, where catching up resumes
+	last int64
+}
+
+// NewFeed listens for outbox notifications on a connection of its own to
+// databaseURL and reads the announced events from db
+func NewFeed(db *sql.DB, databaseURL string, sink Sink, logger *zap.Logger) *Feed {
+	return &Feed{
+		databaseURL: databaseURL,
+		queries:     sqlc.New(db),
+		sink:        sink,
+		logger:      logger,
+	}
+}
+
+// Run publishes events until ctx is done. The listener reconnects on its
+// own; events committed while it was away are then read from the outbox.
+func (f *Feed) Run(ctx context.Context) {
+	listener := pq.NewListener(f.databaseURL, time.Second, time.Minute, func(_ pq.ListenerEventType, err error) {
+		if err != nil {
+			f.logger.Warn("Outbox listener connection failed", zap.Error(err))
+		}
+	})
+	// Listen waits for a connection, so closing is also what stops it
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	if err := listener.Listen(notifyChannel); err != nil {
+		if ctx.Err() == nil {
+			f.logger.Error("Failed to listen for outbox events", zap.Error(err))
+		}
+		return
+	}
+	f.logger.Info("Outbox feed started")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case n, ok := <-listener.Notify:
+			if !ok {
+				return
+			}
+			// A nil notification follows a reconnect
+			if n == nil {
+				f.catchUp(ctx)
+				continue
+			}
+			f.notified(ctx, n.Extra)
+		}
+	}
+}
+
+// notified publishes the event whose ID a notification carries
+func (f *Feed) notified(ctx context.Context, payload string) {
+	id, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		f.logger.Warn("Invalid outbox notification", zap.String("payload", payload))
+		return
+	}
+	row, err := f.queries.GetOutboxEvent(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return
+	}
+	if err != nil {
+		f.logger.Error("Failed to read outbox event", zap.Int64("event_id", id), zap.Error(err))
+		return
+	}
+	f.publish(ctx, eventFrom(row))
+}
+
+// catchUp publishes the events after the last one published. Events are
+// only missed while disconnected, so before any was published there is
+// nowhere to resume from.
+func (f *Feed) catchUp(ctx context.Context) {
+	if f.last == 0 {
+		f.logger.Warn("Outbox listener reconnected; events committed meanwhile were not published")
+		return
+	}
+	for {
+		rows, err := f.queries.ListOutboxEventsAfter(ctx, sqlc.ListOutboxEventsAfterParams{
+			AfterID:    f.last,
+			MaxResults: catchUpBatch,
+		})
+		if err != nil {
+			f.logger.Error("Failed to catch up on outbox events", zap.Error(err))
+			return
+		}
+		for _, row := range rows {
+			f.publish(ctx, eventFrom(row))
+		}
+		if len(rows) < catchUpBatch {
+			return
+		}
+	}
+}
+
+func (f *Feed) publish(ctx context.Context, e Event) {
+	if err := f.sink.Publish(ctx, e); err != nil {
+		f.logger.Warn("Failed to publish event to feed", zap.Int64("event_id", e.ID), zap.Error(err))
+	}
+	f.last = max(f.last, e.ID)
+}
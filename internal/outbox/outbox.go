@@ -0,0 +1,214 @@
+// internal/outbox/outbox.go
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Event types
+const (
+	UserCreated = "UserCreated"
+	UserUpdated = "UserUpdated"
+	UserDeleted = "UserDeleted"
+)
+
+// Event is a domain event as sinks publish it. An event may be published
+// more than once, always with the same ID, so consumers can skip repeats.
+type Event struct {
+	ID         int64           `json:"id"`
+	Type       string          `json:"type"`
+	UserID     int32           `json:"user_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Pending is an event waiting in the outbox
+type Pending struct {
+	Event
+	// Attempts counts the failed attempts to publish the event so far
+	Attempts int
+}
+
+// Sink publishes events where consumers read them. Publish returns nil only
+// once the event is accepted; the relay publishes it again otherwise.
+type Sink interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// Store is the outbox the relay reads events from
+type Store interface {
+	// Lock makes the caller the only relay publishing events. It reports
+	// false when another relay holds the lock; otherwise release must be
+	// called once done.
+	Lock(ctx context.Context) (release func(), locked bool, err error)
+	// Due returns up to limit events to publish, oldest first: the oldest
+	// unpublished event of each user, once its retry time has come
+	Due(ctx context.Context, limit int) ([]Pending, error)
+	// Published marks an event as published
+	Published(ctx context.Context, id int64) error
+	// Failed records a failed attempt to publish an event, which is due
+	// again after retryIn
+	Failed(ctx context.Context, id int64, cause error, retryIn time.Duration) error
+	// DeletePublished removes events published longer than retention ago
+	// and returns how many there were
+	DeletePublished(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// Config holds how the relay polls and retries
+type Config struct {
+	// PollInterval is how often the outbox is checked for new events
+	PollInterval time.Duration
+	// BatchSize is how many events are read from the outbox at a time
+	BatchSize int
+
+	// MinBackoff is the delay before the first retry of an event; each
+	// further retry waits twice as long, up to MaxBackoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Retention is how long published events are kept
+	Retention time.Duration
+}
+
+// cleanupInterval is how often published events past their retention are
+// deleted
+const cleanupInterval = time.Hour
+
+// Relay publishes the events in the outbox to every sink, at least once and
+// in order per user: a user's event is only published after the ones before
+// it, so an event that keeps failing holds back the rest of its user's
+// events but no one else's.
+type Relay struct {
+	store  Store
+	sinks  []Sink
+	config Config
+	logger *zap.Logger
+}
+
+func NewRelay(store Store, sinks []Sink, config Config, logger *zap.Logger) *Relay {
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = time.Second
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = max(5*time.Minute, config.MinBackoff)
+	}
+	if config.Retention <= 0 {
+		config.Retention = 7 * 24 * time.Hour
+	}
+	return &Relay{
+		store:  store,
+		sinks:  sinks,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Run publishes events every poll interval until ctx is done
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	var cleaned time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := r.RunOnce(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			r.logger.Error("Failed to relay outbox events", zap.Error(err))
+		}
+
+		if time.Since(cleaned) >= cleanupInterval {
+			cleaned = time.Now()
+			deleted, err := r.store.DeletePublished(ctx, r.config.Retention)
+			if err != nil {
+				r.logger.Error("Failed to delete published outbox events", zap.Error(err))
+				continue
+			}
+			if deleted > 0 {
+				r.logger.Info("Deleted published outbox events", zap.Int64("deleted", deleted))
+			}
+		}
+	}
+}
+
+// RunOnce publishes the events due now, and those queued behind them, and
+// returns how many were published. It does nothing while another relay
+// holds the outbox.
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	release, locked, err := r.store.Lock(ctx)
+	if err != nil || !locked {
+		return 0, err
+	}
+	defer release()
+
+	published := 0
+	for {
+		due, err := r.store.Due(ctx, r.config.BatchSize)
+		if err != nil {
+			return published, err
+		}
+		if len(due) == 0 {
+			return published, nil
+		}
+
+		for _, p := range due {
+			if err := ctx.Err(); err != nil {
+				return published, err
+			}
+			if err := r.publish(ctx, p.Event); err != nil {
+				retryIn := r.backoff(p.Attempts)
+				r.logger.Warn("Failed to publish outbox event",
+					zap.Error(err),
+					zap.Int64("event_id", p.ID),
+					zap.String("type", p.Type),
+					zap.Int32("user_id", p.UserID),
+					zap.Int("attempts", p.Attempts+1),
+					zap.Duration("retry_in", retryIn),
+				)
+				if err := r.store.Failed(ctx, p.ID, err, retryIn); err != nil {
+					return published, err
+				}
+				continue
+			}
+			if err := r.store.Published(ctx, p.ID); err != nil {
+				return published, err
+			}
+			published++
+		}
+	}
+}
+
+// publish hands e to every sink. When one fails the event is retried on
+// all of them, so the others may see it twice.
+func (r *Relay) publish(ctx context.Context, e Event) error {
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backoff returns the delay before retrying an event that failed attempts
+// times before
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.config.MinBackoff
+	for i := 0; i < attempts && delay < r.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, r.config.MaxBackoff)
+}
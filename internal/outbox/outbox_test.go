@@ -0,0 +1,210 @@
+// internal/outbox/outbox_test.go
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type storedEvent struct {
+	Pending
+	published bool
+	retryAt   time.Time
+	lastError string
+}
+
+// memoryStore is an outbox in memory whose time only moves when told to
+type memoryStore struct {
+	events []*storedEvent
+	now    time.Time
+	locked bool
+}
+
+func (s *memoryStore) add(userID int32, eventType string) {
+	s.events = append(s.events, &storedEvent{Pending: Pending{Event: Event{
+		ID: int64(len(s.events) + 1), Type: eventType, UserID: userID, Data: json.RawMessage(`{}`),
+	}}})
+}
+
+func (s *memoryStore) Lock(context.Context) (func(), bool, error) {
+	if s.locked {
+		return nil, false, nil
+	}
+	s.locked = true
+	return func() { s.locked = false }, true, nil
+}
+
+func (s *memoryStore) Due(_ context.Context, limit int) ([]Pending, error) {
+	var due []Pending
+	held := map[int32]bool{}
+	for _, e := range s.events {
+		if e.published || held[e.UserID] {
+			continue
+		}
+		held[e.UserID] = true
+		if !e.retryAt.After(s.now) && len(due) < limit {
+			due = append(due, e.Pending)
+		}
+	}
+	return due, nil
+}
+
+func (s *memoryStore) Published(_ context.Context, id int64) error {
+	s.events[id-1].published = true
+	return nil
+}
+
+func (s *memoryStore) Failed(_ context.Context, id int64, cause error, retryIn time.Duration) error {
+	e := s.events[id-1]
+	e.Attempts++
+	e.retryAt = s.now.Add(retryIn)
+	e.lastError = cause.Error()
+	return nil
+}
+
+func (s *memoryStore) DeletePublished(context.Context, time.Duration) (int64, error) {
+	return 0, nil
+}
+
+// flakySink fails every event of the users in failing
+type flakySink struct {
+	failing   map[int32]bool
+	published []Event
+}
+
+func (s *flakySink) Publish(_ context.Context, e Event) error {
+	if s.failing[e.UserID] {
+		return errors.New("unavailable")
+	}
+	s.published = append(s.published, e)
+	return nil
+}
+
+func TestRelay_OrdersEventsPerUser(t *testing.T) {
+	store := &memoryStore{now: time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)}
+	store.add(1, UserCreated)
+	store.add(2, UserCreated)
+	store.add(1, UserUpdated)
+	store.add(2, UserUpdated)
+	store.add(1, UserDeleted)
+
+	sink := &flakySink{failing: map[int32]bool{2: true}}
+	relay := NewRelay(store, []Sink{sink}, Config{BatchSize: 2, MinBackoff: time.Second, MaxBackoff: 4 * time.Second}, zap.NewNop())
+
+	published, err := relay.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if published != 3 {
+		t.Fatalf("published %d events, want 3", published)
+	}
+	for i, want := range []int64{1, 3, 5} {
+		if sink.published[i].ID != want {
+			t.Errorf("published[%d] = event %d, want %d", i, sink.published[i].ID, want)
+		}
+	}
+
+	// User 2's first event failed and holds back its update
+	first := store.events[1]
+	if first.Attempts != 1 || first.lastError != "unavailable" || !first.retryAt.Equal(store.now.Add(time.Second)) {
+		t.Errorf("failed event = %d attempts, %q, retry at %v", first.Attempts, first.lastError, first.retryAt)
+	}
+	if published, _ := relay.RunOnce(context.Background()); published != 0 || first.Attempts != 1 {
+		t.Errorf("before its retry time: published %d, attempts %d, want 0, 1", published, first.Attempts)
+	}
+
+	// Each retry waits twice as long, up to MaxBackoff
+	for _, wait := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		if want := store.now.Add(wait); !first.retryAt.Equal(want) {
+			t.Fatalf("retry at %v, want %v", first.retryAt, want)
+		}
+		store.now = first.retryAt
+		relay.RunOnce(context.Background())
+	}
+
+	delete(sink.failing, 2)
+	store.now = first.retryAt
+	if published, _ := relay.RunOnce(context.Background()); published != 2 {
+		t.Fatalf("after recovery published %d events, want 2", published)
+	}
+	if got := sink.published[3:]; got[0].ID != 2 || got[1].ID != 4 {
+		t.Errorf("published events %d, %d, want 2, 4", got[0].ID, got[1].ID)
+	}
+}
+
+func TestRelay_SkipsWhileLocked(t *testing.T) {
+	store := &memoryStore{locked: true}
+	store.add(1, UserCreated)
+	sink := &flakySink{}
+
+	published, err := NewRelay(store, []Sink{sink}, Config{}, zap.NewNop()).RunOnce(context.Background())
+	if err != nil || published != 0 || len(sink.published) != 0 {
+		t.Errorf("RunOnce() = %d, %v, want nothing published", published, err)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var got Event
+	var header http.Header
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(srv.URL, srv.Client())
+	e := Event{ID: 7, Type: UserUpdated, UserID: 3, Data: json.RawMessage(`{"version":2}`)}
+	if err := sink.Publish(context.Background(), e); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if got.ID != 7 || got.UserID != 3 || string(got.Data) != `{"version":2}` {
+		t.Errorf("received %+v", got)
+	}
+	if header.Get("X-Event-ID") != "7" || header.Get("X-Event-Type") != UserUpdated {
+		t.Errorf("headers X-Event-ID = %q, X-Event-Type = %q", header.Get("X-Event-ID"), header.Get("X-Event-Type"))
+	}
+
+	status = http.StatusServiceUnavailable
+	if err := sink.Publish(context.Background(), e); err == nil {
+		t.Error("Publish() error = nil for a 503 response")
+	}
+}
+
+func TestBrokerSink(t *testing.T) {
+	broker := NewMemoryBroker()
+	sink := NewBrokerSink(broker, "users")
+
+	for i, userID := range []int32{4, 5, 4} {
+		e := Event{ID: int64(i + 1), Type: UserCreated, UserID: userID, Data: json.RawMessage(`{}`)}
+		if err := sink.Publish(context.Background(), e); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	messages := broker.Messages("users")
+	if len(messages) != 3 {
+		t.Fatalf("got %d messages, want 3", len(messages))
+	}
+	for i, want := range []string{"4", "5", "4"} {
+		if string(messages[i].Key) != want {
+			t.Errorf("message %d key = %s, want %s", i, messages[i].Key, want)
+		}
+	}
+	if messages[2].Headers["event-id"] != "3" || messages[2].Headers["event-type"] != UserCreated {
+		t.Errorf("headers = %v", messages[2].Headers)
+	}
+	if len(broker.Messages("other")) != 0 {
+		t.Error("messages leaked into another topic")
+	}
+}
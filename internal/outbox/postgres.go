@@ -0,0 +1,81 @@
+// internal/outbox/postgres.go
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+)
+
+type postgresStore struct {
+	db      *sql.DB
+	queries *sqlc.Queries
+}
+
+// NewPostgresStore reads events from the outbox_events table. Replicas share
+// the outbox, and an advisory lock lets one of them relay at a time.
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{db: db, queries: sqlc.New(db)}
+}
+
+func (s *postgresStore) Lock(ctx context.Context) (func(), bool, error) {
+	// Advisory locks belong to a session, so hold on to one connection
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	queries := sqlc.New(conn)
+
+	locked, err := queries.TryLockOutbox(ctx)
+	if err != nil || !locked {
+		conn.Close()
+		return nil, false, err
+	}
+	return func() {
+		// Closing the connection returns it to the pool still holding the
+		// lock, so unlock first
+		queries.UnlockOutbox(context.WithoutCancel(ctx))
+		conn.Close()
+	}, true, nil
+}
+
+func (s *postgresStore) Due(ctx context.Context, limit int) ([]Pending, error) {
+	rows, err := s.queries.ListDueOutboxEvents(ctx, int32(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	due := make([]Pending, 0, len(rows))
+	for _, row := range rows {
+		due = append(due, Pending{Event: eventFrom(row), Attempts: int(row.Attempts)})
+	}
+	return due, nil
+}
+
+func (s *postgresStore) Published(ctx context.Context, id int64) error {
+	return s.queries.MarkOutboxEventPublished(ctx, id)
+}
+
+func (s *postgresStore) Failed(ctx context.Context, id int64, cause error, retryIn time.Duration) error {
+	return s.queries.MarkOutboxEventFailed(ctx, sqlc.MarkOutboxEventFailedParams{
+		LastError:    sql.NullString{String: cause.Error(), Valid: true},
+		RetrySeconds: retryIn.Seconds(),
+		ID:           id,
+	})
+}
+
+func (s *postgresStore) DeletePublished(ctx context.Context, retention time.Duration) (int64, error) {
+	return s.queries.DeletePublishedOutboxEvents(ctx, retention.Seconds())
+}
+
+func eventFrom(row sqlc.OutboxEvent) Event {
+	return Event{
+		ID:         row.ID,
+		Type:       row.EventType,
+		UserID:     row.UserID,
+		OccurredAt: row.CreatedAt,
+		Data:       row.Payload,
+	}
+}
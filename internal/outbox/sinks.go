@@ -0,0 +1,152 @@
+// internal/outbox/sinks.go
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+type logSink struct {
+	logger *zap.Logger
+}
+
+// NewLogSink writes events to the application log
+func NewLogSink(logger *zap.Logger) Sink {
+	return &logSink{logger: logger}
+}
+
+func (s *logSink) Publish(_ context.Context, e Event) error {
+	s.logger.Info("User event",
+		zap.Int64("event_id", e.ID),
+		zap.String("type", e.Type),
+		zap.Int32("user_id", e.UserID),
+		zap.ByteString("data", e.Data),
+	)
+	return nil
+}
+
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink POSTs each event as JSON to url, with its ID and type in
+// the X-Event-ID and X-Event-Type headers. Any response other than 2xx is
+// an error.
+func NewWebhookSink(url string, client *http.Client) Sink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &webhookSink{url: url, client: client}
+}
+
+func (s *webhookSink) Publish(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", strconv.FormatInt(e.ID, 10))
+	req.Header.Set("X-Event-Type", e.Type)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("event webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Message is a record sent to a message broker
+type Message struct {
+	Topic string
+	// Key is the user ID. Brokers that partition topics by key, such as
+	// Kafka, then keep each user's events in order.
+	Key     []byte
+	Value   []byte
+	Headers map[string]string
+}
+
+// Producer is the part of a NATS JetStream or Kafka client the broker sink
+// needs. Produce returns once the broker has acknowledged the message.
+type Producer interface {
+	Produce(ctx context.Context, msg Message) error
+}
+
+type brokerSink struct {
+	producer Producer
+	topic    string
+}
+
+// NewBrokerSink sends each event as a JSON message to topic, keyed by user
+// ID, with its ID and type in the event-id and event-type headers
+func NewBrokerSink(producer Producer, topic string) Sink {
+	return &brokerSink{producer: producer, topic: topic}
+}
+
+func (s *brokerSink) Publish(ctx context.Context, e Event) error {
+	value, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return s.producer.Produce(ctx, Message{
+		Topic: s.topic,
+		Key:   []byte(strconv.FormatInt(int64(e.UserID), 10)),
+		Value: value,
+		Headers: map[string]string{
+			"event-id":   strconv.FormatInt(e.ID, 10),
+			"event-type": e.Type,
+		},
+	})
+}
+
+// MemoryBroker is a Producer that keeps messages in memory, for tests and
+// local development
+type MemoryBroker struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+func (b *MemoryBroker) Produce(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.messages = append(b.messages, msg)
+	return nil
+}
+
+// Messages returns the messages produced to topic, oldest first
+func (b *MemoryBroker) Messages(topic string) []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var messages []Message
+	for _, msg := range b.messages {
+		if msg.Topic == topic {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
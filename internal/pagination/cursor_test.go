@@ -0,0 +1,47 @@
+// internal/pagination/cursor_test.go
+package pagination
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCodec_RoundTrip(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	want := Cursor{Sort: "name,-dob", Values: []string{"Ana", "1990-05-10"}, ID: 42, Backward: true}
+
+	got, err := codec.Decode(codec.Encode(want))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("Decode() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestCodec_RejectsTampering(t *testing.T) {
+	codec := NewCodec([]byte("secret"))
+	encoded := codec.Encode(Cursor{ID: 42})
+	payload, signature, _ := strings.Cut(encoded, ".")
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"Empty", ""},
+		{"No signature", payload},
+		{"Other secret", NewCodec([]byte("other")).Encode(Cursor{ID: 42})},
+		{"Modified payload", NewCodec([]byte("x")).Encode(Cursor{ID: 43})[:len(payload)] + "." + signature},
+		{"Not base64", "!!!." + signature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := codec.Decode(tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Decode() error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// internal/problem/problem.go
+package problem
+
+import (
+	"encoding/json"
+	"maps"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+)
+
+// ContentType is the media type for RFC 9457 problem details
+const ContentType = "application/problem+json"
+
+// Details is an RFC 9457 problem details document
+type Details struct {
+	Type     string                 `json:"type"`
+	Title    string                 `json:"title"`
+	Status   int                    `json:"status"`
+	Detail   string                 `json:"detail,omitempty"`
+	Instance string                 `json:"instance,omitempty"`
+	Errors   []apperrors.FieldError `json:"errors,omitempty"`
+
+	// Extensions are additional members, serialized alongside the standard
+	// ones; their names must not clash with them
+	Extensions map[string]any `json:"-"`
+}
+
+// MarshalJSON writes the extension members as top-level members
+func (p Details) MarshalJSON() ([]byte, error) {
+	type details Details
+	data, err := json.Marshal(details(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+
+	extensions, err := json.Marshal(p.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	// Splice {"a":1} and {"b":2} into {"a":1,"b":2}
+	return append(append(data[:len(data)-1], ','), extensions[1:]...), nil
+}
+
+// New builds a problem for the current request. An empty problemType means
+// the status code alone describes the problem ("about:blank").
+func New(c *fiber.Ctx, status int, problemType, detail string) *Details {
+	if problemType == "" {
+		problemType = "about:blank"
+	}
+
+	p := &Details{
+		Type:   problemType,
+		Title:  utils.StatusMessage(status),
+		Status: status,
+		Detail: detail,
+	}
+	// instance must be a URI reference, and clients may send any request
+	// ID, so it is escaped there; the bare ID stays in request_id
+	if requestID, ok := c.Locals("requestID").(string); ok && requestID != "" {
+		p.Instance = "urn:request:" + url.PathEscape(requestID)
+		p.Extend(map[string]any{"request_id": requestID})
+	}
+	return p
+}
+
+// Extend adds extension members, replacing those of the same name
+func (p *Details) Extend(members map[string]any) {
+	if len(members) == 0 {
+		return
+	}
+	if p.Extensions == nil {
+		p.Extensions = make(map[string]any, len(members))
+	}
+	maps.Copy(p.Extensions, members)
+}
+
+// Write sends the problem as the response body
+func (p *Details) Write(c *fiber.Ctx) error {
+	return c.Status(p.Status).JSON(p, ContentType)
+}
@@ -0,0 +1,20 @@
+// internal/repository/user_birthdays.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+)
+
+// ListUsersByBirthdays returns the live users born on the month and day of
+// any of days, ignoring their years
+func (r *userRepository) ListUsersByBirthdays(ctx context.Context, days []time.Time) ([]sqlc.User, error) {
+	// Keys mirror the idx_users_birthday expression, month * 100 + day
+	keys := make([]int32, 0, len(days))
+	for _, d := range days {
+		keys = append(keys, int32(d.Month())*100+int32(d.Day()))
+	}
+	return r.queries.ListUsersByBirthdays(ctx, keys)
+}
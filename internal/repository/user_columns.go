@@ -0,0 +1,107 @@
+// internal/repository/user_columns.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+)
+
+// userColumns lists the users columns in table order with where each one
+// scans into
+var userColumns = []struct {
+	name string
+	dest func(u *sqlc.User) any
+}{
+	{"id", func(u *sqlc.User) any { return &u.ID }},
+	{"name", func(u *sqlc.User) any { return &u.Name }},
+	{"dob", func(u *sqlc.User) any { return &u.Dob }},
+	{"created_at", func(u *sqlc.User) any { return &u.CreatedAt }},
+	{"updated_at", func(u *sqlc.User) any { return &u.UpdatedAt }},
+	{"version", func(u *sqlc.User) any { return &u.Version }},
+	{"deleted_at", func(u *sqlc.User) any { return &u.DeletedAt }},
+	{"timezone", func(u *sqlc.User) any { return &u.Timezone }},
+	{"merged_into", func(u *sqlc.User) any { return &u.MergedInto }},
+}
+
+// selectColumns returns the columns to read, in table order, for a read
+// asking for columns and ordered by sort. Nil columns selects them all. The
+// id and version, which identify the stored state, and the sort columns,
+// which cursors are built from, are always read; the rest of the returned
+// users are left zero.
+func selectColumns(columns []string, sort []SortField) ([]string, error) {
+	if columns == nil {
+		selected := make([]string, 0, len(userColumns))
+		for _, c := range userColumns {
+			selected = append(selected, c.name)
+		}
+		return selected, nil
+	}
+
+	wanted := append([]string{"id", "version"}, columns...)
+	for _, f := range sort {
+		wanted = append(wanted, f.Column)
+	}
+
+	var selected []string
+	for _, c := range userColumns {
+		if slices.Contains(wanted, c.name) {
+			selected = append(selected, c.name)
+		}
+	}
+	for _, name := range wanted {
+		if !slices.Contains(selected, name) {
+			return nil, fmt.Errorf("unknown user column %q", name)
+		}
+	}
+	return selected, nil
+}
+
+// scanUser reads a row holding columns, as returned by selectColumns
+func scanUser(rows *sql.Rows, columns []string) (sqlc.User, error) {
+	var u sqlc.User
+	dest := make([]any, 0, len(columns))
+	for _, c := range userColumns {
+		if slices.Contains(columns, c.name) {
+			dest = append(dest, c.dest(&u))
+		}
+	}
+	return u, rows.Scan(dest...)
+}
+
+// getUserColumns is GetUserByID reading only some columns
+func (r *userRepository) getUserColumns(ctx context.Context, id int32, includeDeleted bool, columns []string) (*sqlc.User, error) {
+	selected, err := selectColumns(columns, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn().QueryContext(ctx, "SELECT "+columnList(selected)+`
+FROM users
+WHERE id = $1
+  AND (deleted_at IS NULL OR $2::bool)`, id, includeDeleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		return nil, sql.ErrNoRows
+	}
+	u, err := scanUser(rows, selected)
+	if err != nil {
+		return nil, err
+	}
+	return &u, rows.Close()
+}
+
+func columnList(columns []string) string {
+	return strings.Join(columns, ", ")
+}
@@ -0,0 +1,41 @@
+// internal/repository/user_duplicates.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+)
+
+// Names are compared normalized: trimmed, with runs of whitespace collapsed
+// and lower-cased, as indexed by idx_users_identity.
+
+// LockUserIdentity holds a transaction-scoped lock on the normalized name
+// and dob until the transaction ends, so concurrent creates of the same
+// person are checked for duplicates one at a time. It must be called
+// inside InTx.
+func (r *userRepository) LockUserIdentity(ctx context.Context, name string, dob time.Time) error {
+	return r.queries.LockUserIdentity(ctx, sqlc.LockUserIdentityParams{Name: name, Dob: dob})
+}
+
+// FindDuplicateUsers returns up to 10 live users born on dob whose
+// normalized name equals name's or, when minSimilarity is positive, whose
+// name has at least that trigram similarity to it, closest first
+func (r *userRepository) FindDuplicateUsers(ctx context.Context, name string, dob time.Time, minSimilarity float64) ([]sqlc.FindDuplicateUsersRow, error) {
+	return r.queries.FindDuplicateUsers(ctx, sqlc.FindDuplicateUsersParams{
+		Name:          name,
+		Dob:           dob,
+		Fuzzy:         minSimilarity > 0,
+		MinSimilarity: minSimilarity,
+	})
+}
+
+// ListDuplicatePairs returns every pair of live users that FindDuplicateUsers
+// would match with each other, the lower id first
+func (r *userRepository) ListDuplicatePairs(ctx context.Context, minSimilarity float64) ([]sqlc.ListDuplicatePairsRow, error) {
+	return r.queries.ListDuplicatePairs(ctx, sqlc.ListDuplicatePairsParams{
+		Fuzzy:         minSimilarity > 0,
+		MinSimilarity: minSimilarity,
+	})
+}
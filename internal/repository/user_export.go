@@ -0,0 +1,69 @@
+// internal/repository/user_export.go
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+)
+
+// exportBatchSize is how many rows each FETCH reads from the export cursor
+const exportBatchSize = 500
+
+// ExportUsers calls fn for every user selected by params, in order. Rows are
+// read through a server-side cursor so neither side holds the whole result.
+// Returning an error from fn stops the export.
+func (r *userRepository) ExportUsers(ctx context.Context, params ListParams, fn func(u *sqlc.User) error) error {
+	if r.tx == nil {
+		return r.InTx(ctx, func(repo UserRepository) error {
+			return repo.ExportUsers(ctx, params, fn)
+		})
+	}
+
+	params.Limit, params.Offset, params.After = 0, 0, nil
+	query, args, err := buildListQuery(params)
+	if err != nil {
+		return err
+	}
+	columns, err := selectColumns(params.Columns, params.Sort)
+	if err != nil {
+		return err
+	}
+	if _, err := r.tx.ExecContext(ctx, "DECLARE user_export NO SCROLL CURSOR FOR "+query, args...); err != nil {
+		return err
+	}
+	defer r.tx.ExecContext(context.Background(), "CLOSE user_export")
+
+	fetch := fmt.Sprintf("FETCH %d FROM user_export", exportBatchSize)
+	for {
+		n, err := r.fetchExportBatch(ctx, fetch, columns, fn)
+		if err != nil {
+			return err
+		}
+		if n < exportBatchSize {
+			return nil
+		}
+	}
+}
+
+func (r *userRepository) fetchExportBatch(ctx context.Context, fetch string, columns []string, fn func(u *sqlc.User) error) (int, error) {
+	rows, err := r.tx.QueryContext(ctx, fetch)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		u, err := scanUser(rows, columns)
+		if err != nil {
+			return n, err
+		}
+		n++
+		if err := fn(&u); err != nil {
+			return n, err
+		}
+	}
+	return n, rows.Err()
+}
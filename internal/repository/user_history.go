@@ -0,0 +1,56 @@
+// internal/repository/user_history.go
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+)
+
+// LockUser reads a user and locks its row until the transaction ends, so the
+// state read is the one the next write changes. It must be called inside
+// InTx.
+func (r *userRepository) LockUser(ctx context.Context, id int32, includeDeleted bool) (*sqlc.User, error) {
+	user, err := r.queries.LockUser(ctx, sqlc.LockUserParams{ID: id, IncludeDeleted: includeDeleted})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RecordHistory appends a change to a user's history. Call it in the
+// transaction that made the change.
+func (r *userRepository) RecordHistory(ctx context.Context, entry sqlc.CreateUserHistoryParams) error {
+	return r.queries.CreateUserHistory(ctx, entry)
+}
+
+// RecordInsertedUsersHistory records the creation of the users CopyUsers
+// returned the ids of, returning how many were recorded
+func (r *userRepository) RecordInsertedUsersHistory(ctx context.Context, ids []int32, actor, requestID string) (int64, error) {
+	return r.queries.RecordInsertedUsersHistory(ctx, sqlc.RecordInsertedUsersHistoryParams{
+		Actor:     actor,
+		RequestID: requestID,
+		Ids:       ids,
+	})
+}
+
+// ListUserHistory returns up to limit changes of a user, newest first,
+// starting below beforeVersion when it is set
+func (r *userRepository) ListUserHistory(ctx context.Context, userID int32, beforeVersion *int32, limit int32) ([]sqlc.UserHistory, error) {
+	return r.queries.ListUserHistory(ctx, sqlc.ListUserHistoryParams{
+		UserID:        userID,
+		BeforeVersion: nullVersion(beforeVersion),
+		MaxResults:    limit,
+	})
+}
+
+// GetUserHistoryAt returns the last change of a user made at or before
+// asOf, or sql.ErrNoRows when its history starts later
+func (r *userRepository) GetUserHistoryAt(ctx context.Context, userID int32, asOf time.Time) (*sqlc.UserHistory, error) {
+	entry, err := r.queries.GetUserHistoryAt(ctx, sqlc.GetUserHistoryAtParams{UserID: userID, AsOf: asOf})
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
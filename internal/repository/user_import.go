@@ -0,0 +1,91 @@
+// internal/repository/user_import.go
+package repository
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+)
+
+// ExistingUser identifies a live user by its lower-cased name and dob
+type ExistingUser struct {
+	ID      int32
+	NameKey string
+	Dob     string
+}
+
+// FindUsersByNames returns the live users whose lower-cased name is one of
+// names
+func (r *userRepository) FindUsersByNames(ctx context.Context, names []string) ([]ExistingUser, error) {
+	rows, err := r.queries.FindUsersByNames(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]ExistingUser, 0, len(rows))
+	for _, row := range rows {
+		users = append(users, ExistingUser{ID: row.ID, NameKey: row.NameKey, Dob: row.Dob.Format("2006-01-02")})
+	}
+	return users, nil
+}
+
+// CopyUsers bulk-inserts users with COPY in a single transaction and
+// returns their ids. COPY cannot return rows, so users are copied into a
+// staging table dropped at commit and moved from there into users.
+func (r *userRepository) CopyUsers(ctx context.Context, users []sqlc.CreateUserParams) ([]int32, error) {
+	if r.tx == nil {
+		var ids []int32
+		err := r.InTx(ctx, func(repo UserRepository) error {
+			var err error
+			ids, err = repo.CopyUsers(ctx, users)
+			return err
+		})
+		return ids, err
+	}
+
+	if _, err := r.tx.ExecContext(ctx, `CREATE TEMP TABLE IF NOT EXISTS user_import (
+    name TEXT NOT NULL,
+    dob DATE NOT NULL,
+    timezone TEXT
+) ON COMMIT DROP`); err != nil {
+		return nil, err
+	}
+
+	stmt, err := r.tx.PrepareContext(ctx, pq.CopyIn("user_import", "name", "dob", "timezone"))
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	for _, u := range users {
+		if _, err := stmt.ExecContext(ctx, u.Name, u.Dob, u.Timezone); err != nil {
+			return nil, err
+		}
+	}
+	// The final Exec without arguments flushes the buffered rows
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		return nil, err
+	}
+
+	// Emptying the staging table keeps a second call in the transaction from
+	// inserting these users again
+	rows, err := r.tx.QueryContext(ctx, `WITH staged AS (DELETE FROM user_import RETURNING name, dob, timezone)
+INSERT INTO users (name, dob, timezone)
+SELECT name, dob, timezone FROM staged
+RETURNING id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]int32, 0, len(users))
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
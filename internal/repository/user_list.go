@@ -0,0 +1,295 @@
+// internal/repository/user_list.go
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+)
+
+// sortColumn describes how a sortable column is ordered and how a keyset
+// value for it is cast back from text
+type sortColumn struct {
+	expr string
+	cast string
+	text func(u *sqlc.User) string
+}
+
+const timestampLayout = "2006-01-02 15:04:05.999999"
+
+var sortColumns = map[string]sortColumn{
+	"name": {
+		expr: "name",
+		cast: "text",
+		text: func(u *sqlc.User) string { return u.Name },
+	},
+	"dob": {
+		expr: "dob",
+		cast: "date",
+		text: func(u *sqlc.User) string { return u.Dob.Format("2006-01-02") },
+	},
+	"created_at": {
+		expr: "COALESCE(created_at, 'epoch'::timestamp)",
+		cast: "timestamp",
+		text: func(u *sqlc.User) string { return nullTimestampText(u.CreatedAt.Time, u.CreatedAt.Valid) },
+	},
+	"updated_at": {
+		expr: "COALESCE(updated_at, 'epoch'::timestamp)",
+		cast: "timestamp",
+		text: func(u *sqlc.User) string { return nullTimestampText(u.UpdatedAt.Time, u.UpdatedAt.Valid) },
+	},
+}
+
+// SortField orders a list by a column. Ties are always broken by id.
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// Keyset is the position of a row in a sort order: its sort column values
+// as text, one per SortField, followed by its id
+type Keyset struct {
+	Values []string
+	ID     int32
+}
+
+// NameMatch is how UserFilter.Name is compared; all modes ignore case
+type NameMatch string
+
+const (
+	NameExact    NameMatch = "exact"
+	NamePrefix   NameMatch = "prefix"
+	NameContains NameMatch = "contains"
+)
+
+// UserFilter restricts which users are listed or counted. Nil bounds are
+// open and every bound is inclusive.
+type UserFilter struct {
+	Name           string
+	NameMatch      NameMatch
+	DobFrom        *time.Time
+	DobTo          *time.Time
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	UpdatedFrom    *time.Time
+	UpdatedTo      *time.Time
+	IncludeDeleted bool
+}
+
+// ListParams selects a page of users, by Offset or, when After is set, by
+// keyset. Backward returns the rows before After instead; rows always come
+// back in sort order. IDDesc orders ties by descending id. A zero Limit
+// selects every row. Columns limits which columns are read, as described
+// for selectColumns.
+type ListParams struct {
+	Filter   UserFilter
+	Columns  []string
+	Sort     []SortField
+	IDDesc   bool
+	After    *Keyset
+	Backward bool
+	Limit    int32
+	Offset   int32
+}
+
+// ValidSortColumn reports whether users can be ordered by column
+func ValidSortColumn(column string) bool {
+	_, ok := sortColumns[column]
+	return ok
+}
+
+// KeysetOf returns the position of u in the given sort order
+func KeysetOf(u *sqlc.User, sort []SortField) Keyset {
+	values := make([]string, 0, len(sort))
+	for _, f := range sort {
+		values = append(values, sortColumns[f.Column].text(u))
+	}
+	return Keyset{Values: values, ID: u.ID}
+}
+
+func (r *userRepository) ListUsers(ctx context.Context, params ListParams) ([]sqlc.User, error) {
+	query, args, err := buildListQuery(params)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := selectColumns(params.Columns, params.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	users := []sqlc.User{}
+	for rows.Next() {
+		u, err := scanUser(rows, columns)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if params.Backward {
+		for i, j := 0, len(users)-1; i < j; i, j = i+1, j-1 {
+			users[i], users[j] = users[j], users[i]
+		}
+	}
+	return users, nil
+}
+
+func (r *userRepository) CountUsers(ctx context.Context, filter UserFilter) (int64, error) {
+	q := &queryBuilder{}
+	q.filter(filter)
+
+	var count int64
+	err := r.conn().QueryRowContext(ctx, "SELECT COUNT(*) FROM users"+q.whereClause(), q.args...).Scan(&count)
+	return count, err
+}
+
+// queryBuilder accumulates WHERE conditions and their bind parameters
+type queryBuilder struct {
+	where []string
+	args  []any
+}
+
+func (q *queryBuilder) arg(v any) string {
+	q.args = append(q.args, v)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+func (q *queryBuilder) whereClause() string {
+	if len(q.where) == 0 {
+		return ""
+	}
+	return "\nWHERE " + strings.Join(q.where, "\n  AND ")
+}
+
+func (q *queryBuilder) filter(f UserFilter) {
+	if !f.IncludeDeleted {
+		q.where = append(q.where, "deleted_at IS NULL")
+	}
+
+	if f.Name != "" {
+		switch f.NameMatch {
+		case NamePrefix:
+			q.where = append(q.where, "lower(name) LIKE lower("+q.arg(escapeLike(f.Name)+"%")+")")
+		case NameContains:
+			q.where = append(q.where, "lower(name) LIKE lower("+q.arg("%"+escapeLike(f.Name)+"%")+")")
+		default:
+			q.where = append(q.where, "lower(name) = lower("+q.arg(f.Name)+")")
+		}
+	}
+
+	q.between("dob", "date", f.DobFrom, f.DobTo, "2006-01-02")
+	q.between("created_at", "timestamptz", f.CreatedFrom, f.CreatedTo, time.RFC3339Nano)
+	q.between("updated_at", "timestamptz", f.UpdatedFrom, f.UpdatedTo, time.RFC3339Nano)
+}
+
+func (q *queryBuilder) between(column, cast string, from, to *time.Time, layout string) {
+	if from != nil {
+		q.where = append(q.where, column+" >= "+q.arg(from.Format(layout))+"::"+cast)
+	}
+	if to != nil {
+		q.where = append(q.where, column+" <= "+q.arg(to.Format(layout))+"::"+cast)
+	}
+}
+
+// escapeLike makes s match literally inside a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// buildListQuery renders params as parameterized SQL. Only whitelisted
+// column expressions are interpolated; every value is a bind parameter.
+func buildListQuery(params ListParams) (string, []any, error) {
+	selected, err := selectColumns(params.Columns, params.Sort)
+	if err != nil {
+		return "", nil, err
+	}
+
+	q := &queryBuilder{}
+	arg := q.arg
+	q.filter(params.Filter)
+
+	columns := make([]sortColumn, 0, len(params.Sort))
+	for _, f := range params.Sort {
+		col, ok := sortColumns[f.Column]
+		if !ok {
+			return "", nil, fmt.Errorf("unsupported sort column %q", f.Column)
+		}
+		columns = append(columns, col)
+	}
+
+	// Walking backwards flips every direction; the page is reversed afterwards
+	desc := func(d bool) bool { return d != params.Backward }
+
+	if params.After != nil {
+		if len(params.After.Values) != len(params.Sort) {
+			return "", nil, fmt.Errorf("keyset has %d values for %d sort fields", len(params.After.Values), len(params.Sort))
+		}
+
+		// (a > x) OR (a = x AND b < y) OR (a = x AND b = y AND id > z)
+		var alternatives []string
+		var equal []string
+		for i, col := range columns {
+			value := arg(params.After.Values[i]) + "::" + col.cast
+			op := ">"
+			if desc(params.Sort[i].Desc) {
+				op = "<"
+			}
+			cond := append(slices.Clone(equal), col.expr+" "+op+" "+value)
+			alternatives = append(alternatives, "("+strings.Join(cond, " AND ")+")")
+			equal = append(equal, col.expr+" = "+value)
+		}
+		op := ">"
+		if desc(params.IDDesc) {
+			op = "<"
+		}
+		alternatives = append(alternatives, "("+strings.Join(append(equal, "id "+op+" "+arg(params.After.ID)), " AND ")+")")
+		q.where = append(q.where, "("+strings.Join(alternatives, " OR ")+")")
+	}
+
+	var order []string
+	for i, col := range columns {
+		order = append(order, col.expr+direction(desc(params.Sort[i].Desc)))
+	}
+	order = append(order, "id"+direction(desc(params.IDDesc)))
+
+	var b strings.Builder
+	b.WriteString("SELECT " + columnList(selected) + "\nFROM users")
+	b.WriteString(q.whereClause())
+	b.WriteString("\nORDER BY " + strings.Join(order, ", "))
+	if params.Limit > 0 {
+		b.WriteString("\nLIMIT " + arg(params.Limit))
+	}
+	if params.After == nil && params.Offset > 0 {
+		b.WriteString(" OFFSET " + arg(params.Offset))
+	}
+
+	return b.String(), q.args, nil
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+	return " ASC"
+}
+
+func nullTimestampText(t time.Time, valid bool) string {
+	if !valid {
+		return "epoch"
+	}
+	return t.Format(timestampLayout)
+}
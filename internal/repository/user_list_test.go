@@ -0,0 +1,145 @@
+// internal/repository/user_list_test.go
+package repository
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildListQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    ListParams
+		wantWhere string
+		wantOrder string
+		wantArgs  []any
+	}{
+		{
+			name:      "Offset page",
+			params:    ListParams{Limit: 10, Offset: 20},
+			wantWhere: "WHERE deleted_at IS NULL",
+			wantOrder: "ORDER BY id ASC\nLIMIT $1 OFFSET $2",
+			wantArgs:  []any{int32(10), int32(20)},
+		},
+		{
+			name:      "Keyset on id",
+			params:    ListParams{Limit: 11, After: &Keyset{ID: 5}, Filter: UserFilter{IncludeDeleted: true}},
+			wantWhere: "WHERE ((id > $1))",
+			wantOrder: "ORDER BY id ASC\nLIMIT $2",
+			wantArgs:  []any{int32(5), int32(11)},
+		},
+		{
+			name: "Keyset backwards on name and descending dob",
+			params: ListParams{
+				Sort:     []SortField{{Column: "name"}, {Column: "dob", Desc: true}},
+				After:    &Keyset{Values: []string{"Ana", "1990-05-10"}, ID: 7},
+				Backward: true,
+				Limit:    3,
+			},
+			wantWhere: "WHERE deleted_at IS NULL\n  AND ((name < $1::text) OR (name = $1::text AND dob > $2::date) OR (name = $1::text AND dob = $2::date AND id < $3))",
+			wantOrder: "ORDER BY name DESC, dob ASC, id DESC\nLIMIT $4",
+			wantArgs:  []any{"Ana", "1990-05-10", int32(7), int32(3)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args, err := buildListQuery(tt.params)
+			if err != nil {
+				t.Fatalf("buildListQuery() error = %v", err)
+			}
+			if !strings.Contains(query, tt.wantWhere) {
+				t.Errorf("query missing %q:\n%s", tt.wantWhere, query)
+			}
+			if !strings.HasSuffix(query, tt.wantOrder) {
+				t.Errorf("query should end with %q:\n%s", tt.wantOrder, query)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestBuildListQuery_Filters(t *testing.T) {
+	from := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	created := time.Date(2024, 6, 1, 12, 0, 0, 0, time.FixedZone("IST", 5*3600+1800))
+
+	query, args, err := buildListQuery(ListParams{
+		Filter: UserFilter{
+			Name:        "50%_an",
+			NameMatch:   NamePrefix,
+			DobFrom:     &from,
+			CreatedFrom: &created,
+		},
+		IDDesc: true,
+		Limit:  10,
+	})
+	if err != nil {
+		t.Fatalf("buildListQuery() error = %v", err)
+	}
+
+	wantWhere := "WHERE deleted_at IS NULL\n  AND lower(name) LIKE lower($1)\n  AND dob >= $2::date\n  AND created_at >= $3::timestamptz"
+	if !strings.Contains(query, wantWhere) {
+		t.Errorf("query missing %q:\n%s", wantWhere, query)
+	}
+	if !strings.Contains(query, "ORDER BY id DESC") {
+		t.Errorf("query should order by id descending:\n%s", query)
+	}
+
+	wantArgs := []any{`50\%\_an%`, "1990-01-01", "2024-06-01T12:00:00+05:30", int32(10)}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %#v, want %#v", args, wantArgs)
+	}
+}
+
+func TestBuildListQuery_RejectsUnknownColumn(t *testing.T) {
+	_, _, err := buildListQuery(ListParams{Sort: []SortField{{Column: "id; DROP TABLE users"}}})
+	if err == nil {
+		t.Error("buildListQuery() expected an error for an unknown sort column")
+	}
+}
+
+func TestBuildListQuery_Columns(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  ListParams
+		wantSQL string
+		wantErr bool
+	}{
+		{
+			name:    "All columns",
+			params:  ListParams{},
+			wantSQL: "SELECT id, name, dob, created_at, updated_at, version, deleted_at, timezone, merged_into\nFROM users",
+		},
+		{
+			name:    "Sparse in table order",
+			params:  ListParams{Columns: []string{"timezone", "name"}},
+			wantSQL: "SELECT id, name, version, timezone\nFROM users",
+		},
+		{
+			name:    "Sort columns for cursors",
+			params:  ListParams{Columns: []string{}, Sort: []SortField{{Column: "created_at"}}},
+			wantSQL: "SELECT id, created_at, version\nFROM users",
+		},
+		{
+			name:    "Unknown column",
+			params:  ListParams{Columns: []string{"password"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _, err := buildListQuery(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildListQuery() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.HasPrefix(query, tt.wantSQL) {
+				t.Errorf("buildListQuery() = %q, want prefix %q", query, tt.wantSQL)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+// internal/repository/user_merge.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+)
+
+// MergeUser tombstones the live user sourceID as merged into targetID:
+// it is soft-deleted with merged_into pointing at the survivor, and users
+// previously merged into it are repointed there too, so redirects never
+// chain. It returns the repointed users, each at a new version, or
+// sql.ErrNoRows when the source is gone or its version is no longer
+// expectedVersion. It must be called inside InTx.
+func (r *userRepository) MergeUser(ctx context.Context, sourceID, targetID, expectedVersion int32) ([]sqlc.User, error) {
+	rows, err := r.queries.MergeUser(ctx, sqlc.MergeUserParams{
+		MergedInto:      sql.NullInt32{Int32: targetID, Valid: true},
+		ID:              sourceID,
+		ExpectedVersion: expectedVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return r.queries.RepointMergedUsers(ctx, sqlc.RepointMergedUsersParams{
+		MergedInto: sql.NullInt32{Int32: targetID, Valid: true},
+		ID:         sql.NullInt32{Int32: sourceID, Valid: true},
+	})
+}
+
+// GetMergedInto returns the user id was merged into, or sql.ErrNoRows when
+// it was not merged
+func (r *userRepository) GetMergedInto(ctx context.Context, id int32) (int32, error) {
+	return r.queries.GetMergedInto(ctx, id)
+}
@@ -0,0 +1,21 @@
+// internal/repository/user_outbox.go
+package repository
+
+import (
+	"context"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+)
+
+// EnqueueEvent adds an event to the outbox. Call it in the transaction that
+// made the change the event describes, so the two commit together.
+func (r *userRepository) EnqueueEvent(ctx context.Context, event sqlc.CreateOutboxEventParams) error {
+	return r.queries.CreateOutboxEvent(ctx, event)
+}
+
+// EnqueueInsertedUsersEvents enqueues the creation of the users whose history
+// RecordInsertedUsersHistory recorded, returning how many events were
+// enqueued. Call it in the same transaction.
+func (r *userRepository) EnqueueInsertedUsersEvents(ctx context.Context, ids []int32) (int64, error) {
+	return r.queries.EnqueueInsertedUsersEvents(ctx, ids)
+}
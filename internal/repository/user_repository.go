@@ -9,29 +9,67 @@ import (
 	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
 )
 
+// UserRepository reads and writes users. Soft-deleted users are invisible to
+// reads unless includeDeleted is set, and are never modified by writes other
+// than RestoreUser and PurgeDeletedUsers. Users merged into another stay
+// soft-deleted for good: they are neither restored nor purged.
 type UserRepository interface {
-	CreateUser(ctx context.Context, name string, dob time.Time) (*sqlc.User, error)
-	GetUserByID(ctx context.Context, id int32) (*sqlc.User, error)
-	ListUsers(ctx context.Context, limit, offset int32) ([]sqlc.User, error)
-	UpdateUser(ctx context.Context, id int32, name string, dob time.Time) (*sqlc.User, error)
-	DeleteUser(ctx context.Context, id int32) error
-	CountUsers(ctx context.Context) (int64, error)
+	CreateUser(ctx context.Context, name string, dob time.Time, timezone *string) (*sqlc.User, error)
+	GetUserByID(ctx context.Context, id int32, includeDeleted bool, columns ...string) (*sqlc.User, error)
+	ListUsers(ctx context.Context, params ListParams) ([]sqlc.User, error)
+	UpdateUser(ctx context.Context, id int32, name string, dob time.Time, timezone *string, expectedVersion *int32) (*sqlc.User, error)
+	PatchUser(ctx context.Context, id int32, patch UserPatch, expectedVersion *int32) (*sqlc.User, error)
+	DeleteUser(ctx context.Context, id int32, expectedVersion *int32) error
+	RestoreUser(ctx context.Context, id int32) (*sqlc.User, error)
+	PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int64, error)
+	CountUsers(ctx context.Context, filter UserFilter) (int64, error)
+	SearchUsers(ctx context.Context, params SearchParams) ([]sqlc.SearchUsersRow, error)
+	InTx(ctx context.Context, fn func(repo UserRepository) error) error
+	FindUsersByNames(ctx context.Context, names []string) ([]ExistingUser, error)
+	CopyUsers(ctx context.Context, users []sqlc.CreateUserParams) ([]int32, error)
+	ExportUsers(ctx context.Context, params ListParams, fn func(u *sqlc.User) error) error
+	ListUsersByBirthdays(ctx context.Context, days []time.Time) ([]sqlc.User, error)
+	LockUserIdentity(ctx context.Context, name string, dob time.Time) error
+	FindDuplicateUsers(ctx context.Context, name string, dob time.Time, minSimilarity float64) ([]sqlc.FindDuplicateUsersRow, error)
+	ListDuplicatePairs(ctx context.Context, minSimilarity float64) ([]sqlc.ListDuplicatePairsRow, error)
+	MergeUser(ctx context.Context, sourceID, targetID, expectedVersion int32) ([]sqlc.User, error)
+	GetMergedInto(ctx context.Context, id int32) (int32, error)
+	LockUser(ctx context.Context, id int32, includeDeleted bool) (*sqlc.User, error)
+	RecordHistory(ctx context.Context, entry sqlc.CreateUserHistoryParams) error
+	RecordInsertedUsersHistory(ctx context.Context, ids []int32, actor, requestID string) (int64, error)
+	ListUserHistory(ctx context.Context, userID int32, beforeVersion *int32, limit int32) ([]sqlc.UserHistory, error)
+	GetUserHistoryAt(ctx context.Context, userID int32, asOf time.Time) (*sqlc.UserHistory, error)
+	EnqueueEvent(ctx context.Context, event sqlc.CreateOutboxEventParams) error
+	EnqueueInsertedUsersEvents(ctx context.Context, ids []int32) (int64, error)
+}
+
+// UserPatch lists the columns PatchUser changes; nil fields are left alone.
+// Timezone is only written when SetTimezone is set, so it can be cleared.
+type UserPatch struct {
+	Name        *string
+	Dob         *time.Time
+	Timezone    *string
+	SetTimezone bool
 }
 
 type userRepository struct {
+	db      *sql.DB
+	tx      *sql.Tx
 	queries *sqlc.Queries
 }
 
 func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepository{
+		db:      db,
 		queries: sqlc.New(db),
 	}
 }
 
-func (r *userRepository) CreateUser(ctx context.Context, name string, dob time.Time) (*sqlc.User, error) {
+func (r *userRepository) CreateUser(ctx context.Context, name string, dob time.Time, timezone *string) (*sqlc.User, error) {
 	user, err := r.queries.CreateUser(ctx, sqlc.CreateUserParams{
-		Name: name,
-		Dob:  dob,
+		Name:     name,
+		Dob:      dob,
+		Timezone: nullString(timezone),
 	})
 	if err != nil {
 		return nil, err
@@ -39,26 +77,61 @@ func (r *userRepository) CreateUser(ctx context.Context, name string, dob time.T
 	return &user, nil
 }
 
-func (r *userRepository) GetUserByID(ctx context.Context, id int32) (*sqlc.User, error) {
-	user, err := r.queries.GetUserByID(ctx, id)
+// GetUserByID reads every column of the user unless columns limits them,
+// as described for selectColumns
+func (r *userRepository) GetUserByID(ctx context.Context, id int32, includeDeleted bool, columns ...string) (*sqlc.User, error) {
+	if columns != nil {
+		return r.getUserColumns(ctx, id, includeDeleted, columns)
+	}
+
+	user, err := r.queries.GetUserByID(ctx, sqlc.GetUserByIDParams{
+		ID:             id,
+		IncludeDeleted: includeDeleted,
+	})
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *userRepository) ListUsers(ctx context.Context, limit, offset int32) ([]sqlc.User, error) {
-	return r.queries.ListUsers(ctx, sqlc.ListUsersParams{
-		Limit:  limit,
-		Offset: offset,
-	})
+// InTx runs fn with a repository bound to a new transaction, committing when
+// fn returns nil and rolling back otherwise. Calls on a repository that is
+// already in a transaction join it.
+func (r *userRepository) InTx(ctx context.Context, fn func(repo UserRepository) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(&userRepository{db: r.db, tx: tx, queries: r.queries.WithTx(tx)}); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *userRepository) UpdateUser(ctx context.Context, id int32, name string, dob time.Time) (*sqlc.User, error) {
+// conn returns the transaction the repository is bound to, if any, so that
+// hand-written queries take part in it
+func (r *userRepository) conn() sqlc.DBTX {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// UpdateUser returns sql.ErrNoRows when the user does not exist or its
+// version differs from a non-nil expectedVersion
+func (r *userRepository) UpdateUser(ctx context.Context, id int32, name string, dob time.Time, timezone *string, expectedVersion *int32) (*sqlc.User, error) {
 	user, err := r.queries.UpdateUser(ctx, sqlc.UpdateUserParams{
-		ID:   id,
-		Name: name,
-		Dob:  dob,
+		ID:              id,
+		Name:            name,
+		Dob:             dob,
+		Timezone:        nullString(timezone),
+		ExpectedVersion: nullVersion(expectedVersion),
 	})
 	if err != nil {
 		return nil, err
@@ -66,10 +139,68 @@ func (r *userRepository) UpdateUser(ctx context.Context, id int32, name string,
 	return &user, nil
 }
 
-func (r *userRepository) DeleteUser(ctx context.Context, id int32) error {
-	return r.queries.DeleteUser(ctx, id)
+// PatchUser updates only the columns patch sets
+func (r *userRepository) PatchUser(ctx context.Context, id int32, patch UserPatch, expectedVersion *int32) (*sqlc.User, error) {
+	params := sqlc.PatchUserParams{
+		ID:              id,
+		Name:            nullString(patch.Name),
+		SetTimezone:     patch.SetTimezone,
+		Timezone:        nullString(patch.Timezone),
+		ExpectedVersion: nullVersion(expectedVersion),
+	}
+	if patch.Dob != nil {
+		params.Dob = sql.NullTime{Time: *patch.Dob, Valid: true}
+	}
+
+	user, err := r.queries.PatchUser(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteUser soft-deletes a user, returning sql.ErrNoRows when nothing
+// matched
+func (r *userRepository) DeleteUser(ctx context.Context, id int32, expectedVersion *int32) error {
+	rows, err := r.queries.SoftDeleteUser(ctx, sqlc.SoftDeleteUserParams{
+		ID:              id,
+		ExpectedVersion: nullVersion(expectedVersion),
+	})
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// RestoreUser undeletes a soft-deleted user, returning sql.ErrNoRows when
+// no deleted user matched
+func (r *userRepository) RestoreUser(ctx context.Context, id int32) (*sqlc.User, error) {
+	user, err := r.queries.RestoreUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// PurgeDeletedUsers hard-deletes users soft-deleted longer ago than
+// retention, measured against the database clock
+func (r *userRepository) PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int64, error) {
+	return r.queries.PurgeDeletedUsers(ctx, retention.Seconds())
 }
 
-func (r *userRepository) CountUsers(ctx context.Context) (int64, error) {
-	return r.queries.CountUsers(ctx)
-}
\ No newline at end of file
+func nullVersion(version *int32) sql.NullInt32 {
+	if version == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *version, Valid: true}
+}
+
+func nullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
@@ -0,0 +1,43 @@
+// internal/repository/user_search.go
+package repository
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+)
+
+// SearchParams describes a name search. A user matches when its name
+// contains the words of Query or is at least MinSimilarity (0-1) similar to
+// it by trigrams.
+type SearchParams struct {
+	Query         string
+	MinSimilarity float64
+	Limit         int32
+}
+
+// SearchUsers returns live users matching params, most relevant first
+func (r *userRepository) SearchUsers(ctx context.Context, params SearchParams) ([]sqlc.SearchUsersRow, error) {
+	// The trigram % operator only uses the index with the session threshold,
+	// so set it for a transaction rather than comparing similarity()
+	if r.tx == nil {
+		var users []sqlc.SearchUsersRow
+		err := r.InTx(ctx, func(repo UserRepository) error {
+			var err error
+			users, err = repo.SearchUsers(ctx, params)
+			return err
+		})
+		return users, err
+	}
+
+	threshold := strconv.FormatFloat(params.MinSimilarity, 'f', -1, 64)
+	if err := r.queries.SetSimilarityThreshold(ctx, threshold); err != nil {
+		return nil, err
+	}
+
+	return r.queries.SearchUsers(ctx, sqlc.SearchUsersParams{
+		Query:      params.Query,
+		MaxResults: params.Limit,
+	})
+}
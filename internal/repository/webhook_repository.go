@@ -0,0 +1,145 @@
+// internal/repository/webhook_repository.go
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+)
+
+// WebhookRepository stores webhook subscriptions and the deliveries of
+// events to them
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, params sqlc.CreateWebhookSubscriptionParams) (*sqlc.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id int32) (*sqlc.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]sqlc.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, params sqlc.UpdateWebhookSubscriptionParams) (*sqlc.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int32) error
+	ListDeliveries(ctx context.Context, params DeliveryListParams) ([]sqlc.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, subscriptionID int32, id int64) (*sqlc.WebhookDelivery, error)
+	ListDeliveryAttempts(ctx context.Context, deliveryID int64) ([]sqlc.WebhookDeliveryAttempt, error)
+	Redeliver(ctx context.Context, subscriptionID int32, id int64) (*sqlc.WebhookDelivery, error)
+	EnqueueDeliveries(ctx context.Context, eventID int64, eventType string, payload json.RawMessage) (int64, error)
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]sqlc.ClaimWebhookDeliveriesRow, error)
+	RecordAttempt(ctx context.Context, attempt sqlc.RecordWebhookAttemptParams) error
+}
+
+// DeliveryListParams selects deliveries, newest first. Zero values match
+// every subscription and status.
+type DeliveryListParams struct {
+	SubscriptionID int32
+	Status         string
+	// BeforeID, when positive, starts the list below that delivery
+	BeforeID int64
+	Limit    int32
+}
+
+type webhookRepository struct {
+	queries *sqlc.Queries
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{queries: sqlc.New(db)}
+}
+
+func (r *webhookRepository) CreateSubscription(ctx context.Context, params sqlc.CreateWebhookSubscriptionParams) (*sqlc.WebhookSubscription, error) {
+	subscription, err := r.queries.CreateWebhookSubscription(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *webhookRepository) GetSubscription(ctx context.Context, id int32) (*sqlc.WebhookSubscription, error) {
+	subscription, err := r.queries.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *webhookRepository) ListSubscriptions(ctx context.Context) ([]sqlc.WebhookSubscription, error) {
+	return r.queries.ListWebhookSubscriptions(ctx)
+}
+
+// UpdateSubscription replaces a subscription, keeping its secret when
+// params.Secret is null
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, params sqlc.UpdateWebhookSubscriptionParams) (*sqlc.WebhookSubscription, error) {
+	subscription, err := r.queries.UpdateWebhookSubscription(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// DeleteSubscription deletes a subscription and its deliveries, returning
+// sql.ErrNoRows when there was none
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id int32) error {
+	rows, err := r.queries.DeleteWebhookSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, params DeliveryListParams) ([]sqlc.WebhookDelivery, error) {
+	return r.queries.ListWebhookDeliveries(ctx, sqlc.ListWebhookDeliveriesParams{
+		SubscriptionID: sql.NullInt32{Int32: params.SubscriptionID, Valid: params.SubscriptionID > 0},
+		Status:         sql.NullString{String: params.Status, Valid: params.Status != ""},
+		BeforeID:       sql.NullInt64{Int64: params.BeforeID, Valid: params.BeforeID > 0},
+		MaxResults:     params.Limit,
+	})
+}
+
+func (r *webhookRepository) GetDelivery(ctx context.Context, subscriptionID int32, id int64) (*sqlc.WebhookDelivery, error) {
+	delivery, err := r.queries.GetWebhookDelivery(ctx, sqlc.GetWebhookDeliveryParams{ID: id, SubscriptionID: subscriptionID})
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// ListDeliveryAttempts returns the attempts made at a delivery, oldest first
+func (r *webhookRepository) ListDeliveryAttempts(ctx context.Context, deliveryID int64) ([]sqlc.WebhookDeliveryAttempt, error) {
+	return r.queries.ListWebhookDeliveryAttempts(ctx, deliveryID)
+}
+
+// Redeliver queues a delivery again, with its retries starting over
+func (r *webhookRepository) Redeliver(ctx context.Context, subscriptionID int32, id int64) (*sqlc.WebhookDelivery, error) {
+	delivery, err := r.queries.RedeliverWebhookDelivery(ctx, sqlc.RedeliverWebhookDeliveryParams{ID: id, SubscriptionID: subscriptionID})
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// EnqueueDeliveries queues a delivery of an event to each active
+// subscription to its type and returns how many were queued. Enqueueing an
+// event again queues nothing new.
+func (r *webhookRepository) EnqueueDeliveries(ctx context.Context, eventID int64, eventType string, payload json.RawMessage) (int64, error) {
+	return r.queries.EnqueueWebhookDeliveries(ctx, sqlc.EnqueueWebhookDeliveriesParams{
+		EventID:   eventID,
+		EventType: eventType,
+		Payload:   payload,
+	})
+}
+
+// ClaimDeliveries takes up to limit due deliveries, hiding them from other
+// callers until lease has passed or their attempt is recorded
+func (r *webhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]sqlc.ClaimWebhookDeliveriesRow, error) {
+	return r.queries.ClaimWebhookDeliveries(ctx, sqlc.ClaimWebhookDeliveriesParams{
+		LeaseSeconds: lease.Seconds(),
+		MaxResults:   int32(limit),
+	})
+}
+
+// RecordAttempt logs an attempt at a delivery and updates its status
+func (r *webhookRepository) RecordAttempt(ctx context.Context, attempt sqlc.RecordWebhookAttemptParams) error {
+	return r.queries.RecordWebhookAttempt(ctx, attempt)
+}
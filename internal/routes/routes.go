@@ -2,20 +2,54 @@
 package routes
 
 import (
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 	"github.com/shravanirajulu2004/go-user-api/internal/handler"
+	"github.com/shravanirajulu2004/go-user-api/internal/middleware"
 )
 
-func SetupRoutes(app *fiber.App, userHandler handler.UserHandler) {
+// SetupRoutes registers every route. idempotent guards the routes that
+// honour Idempotency-Key.
+func SetupRoutes(app *fiber.App, userHandler handler.UserHandler, webhookHandler handler.WebhookHandler, streamHandler handler.StreamHandler, webSocketHandler handler.WebSocketHandler, idempotent fiber.Handler) {
 	// Health check
 	app.Get("/health", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{"status": "ok"})
 	})
 
 	// User routes
-	app.Post("/users", userHandler.CreateUser)
+	app.Post("/users", idempotent, userHandler.CreateUser)
+	app.Post("/users/batch", userHandler.BatchUsers)
+	app.Post("/users/import", userHandler.ImportUsers)
+	app.Get("/users/search", userHandler.SearchUsers)
+	app.Get("/users/export", userHandler.ExportUsers)
+	app.Get("/users/birthdays", userHandler.UpcomingBirthdays)
+	app.Get("/users/duplicates", userHandler.FindDuplicates)
+	app.Get("/users/stream", middleware.RequireAdmin(), streamHandler.StreamUsers)
 	app.Get("/users/:id", userHandler.GetUserByID)
 	app.Get("/users", userHandler.ListUsers)
 	app.Put("/users/:id", userHandler.UpdateUser)
+	app.Patch("/users/:id", userHandler.PatchUser)
 	app.Delete("/users/:id", userHandler.DeleteUser)
-}
\ No newline at end of file
+	app.Post("/users/:id/restore", middleware.RequireAdmin(), userHandler.RestoreUser)
+	app.Post("/users/:id/merge", middleware.RequireAdmin(), userHandler.MergeUsers)
+	app.Get("/users/:id/history", middleware.RequireAdmin(), userHandler.UserHistory)
+
+	// WebSocket API; admin access is checked before upgrading
+	app.Get("/ws", middleware.RequireAdmin(), webSocketHandler.RequireUpgrade, websocket.New(webSocketHandler.Serve))
+
+	// Admin routes
+	admin := app.Group("/admin", middleware.RequireAdmin())
+	admin.Post("/users/purge", userHandler.PurgeDeletedUsers)
+
+	// Webhook routes
+	webhooks := app.Group("/webhooks", middleware.RequireAdmin())
+	webhooks.Post("/", webhookHandler.CreateWebhook)
+	webhooks.Get("/", webhookHandler.ListWebhooks)
+	webhooks.Get("/dead-letters", webhookHandler.DeadLetters)
+	webhooks.Get("/:id", webhookHandler.GetWebhook)
+	webhooks.Put("/:id", webhookHandler.UpdateWebhook)
+	webhooks.Delete("/:id", webhookHandler.DeleteWebhook)
+	webhooks.Get("/:id/deliveries", webhookHandler.ListDeliveries)
+	webhooks.Get("/:id/deliveries/:deliveryId", webhookHandler.GetDelivery)
+	webhooks.Post("/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+}
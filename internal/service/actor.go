@@ -0,0 +1,24 @@
+// internal/service/actor.go
+package service
+
+import "context"
+
+// Actor identifies who asked for a change and through which request, for
+// the audit log
+type Actor struct {
+	Name      string
+	RequestID string
+}
+
+type actorKey struct{}
+
+// WithActor attributes the changes made with ctx to actor
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// actorFrom returns the actor of ctx, or an unnamed one
+func actorFrom(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
@@ -0,0 +1,53 @@
+// internal/service/age.go
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+)
+
+type locationKey struct{}
+
+// WithLocation makes ages computed for ctx change at loc's midnight,
+// overriding the users' own time zones
+func WithLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey{}, loc)
+}
+
+// locations caches loaded time zones by name; LoadLocation reads the zone
+// database on every call
+var locations sync.Map
+
+func loadLocation(name string) (*time.Location, error) {
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
+
+// now returns the current time in the location requested for ctx, or UTC
+func (s *userService) now(ctx context.Context) time.Time {
+	if loc, ok := ctx.Value(locationKey{}).(*time.Location); ok {
+		return s.clock.Now().In(loc)
+	}
+	return s.clock.Now().UTC()
+}
+
+// userNow returns the current time in the location requested for ctx,
+// falling back to the user's own time zone and then UTC
+func (s *userService) userNow(ctx context.Context, user *sqlc.User) time.Time {
+	if _, ok := ctx.Value(locationKey{}).(*time.Location); !ok && user.Timezone.Valid {
+		if loc, err := loadLocation(user.Timezone.String); err == nil {
+			return s.clock.Now().In(loc)
+		}
+	}
+	return s.now(ctx)
+}
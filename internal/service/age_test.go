@@ -0,0 +1,153 @@
+// internal/service/age_test.go
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/clock"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestGetUserByID_AgeFollowsTimezone(t *testing.T) {
+	kolkata, err := time.LoadLocation("Asia/Kolkata")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+
+	dob := time.Date(1990, 5, 10, 0, 0, 0, 0, time.UTC)
+	// 20:00 UTC on the 9th is 01:30 on the 10th in Kolkata
+	fake := clock.NewFake(time.Date(2024, 5, 9, 20, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name     string
+		timezone sql.NullString
+		ctx      context.Context
+		want     int
+	}{
+		{name: "UTC by default", ctx: context.Background(), want: 33},
+		{
+			name:     "user timezone",
+			timezone: sql.NullString{String: "Asia/Kolkata", Valid: true},
+			ctx:      context.Background(),
+			want:     34,
+		},
+		{name: "requested timezone", ctx: WithLocation(context.Background(), kolkata), want: 34},
+		{
+			name:     "requested timezone overrides user timezone",
+			timezone: sql.NullString{String: "Asia/Kolkata", Valid: true},
+			ctx:      WithLocation(context.Background(), time.UTC),
+			want:     33,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newBatchRepo(sqlc.User{ID: 1, Name: "Alice", Dob: dob, Version: 1, Timezone: tt.timezone})
+			s := NewUserService(repo, zap.NewNop(), Config{Clock: fake})
+
+			user, err := s.GetUserByID(tt.ctx, 1, false, 0)
+			if err != nil {
+				t.Fatalf("GetUserByID() error = %v", err)
+			}
+			if user.Age == nil || *user.Age != tt.want {
+				t.Errorf("Age = %v, want %d", user.Age, tt.want)
+			}
+		})
+	}
+
+	t.Run("advancing the clock past midnight UTC", func(t *testing.T) {
+		repo := newBatchRepo(sqlc.User{ID: 1, Name: "Alice", Dob: dob, Version: 1})
+		s := NewUserService(repo, zap.NewNop(), Config{Clock: clock.NewFake(fake.Now().Add(4 * time.Hour))})
+
+		user, err := s.GetUserByID(context.Background(), 1, false, 0)
+		if err != nil {
+			t.Fatalf("GetUserByID() error = %v", err)
+		}
+		if user.Age == nil || *user.Age != 34 {
+			t.Errorf("Age = %v, want 34", user.Age)
+		}
+	})
+}
+
+func TestGetUserByID_AgeDetail(t *testing.T) {
+	dob := time.Date(2008, 2, 29, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(time.Date(2026, 2, 28, 12, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name        string
+		majority    int
+		wantMinor   bool
+		wantYears   int
+		wantNext    string
+		wantUntil   int
+		wantZodiac  string
+		wantWeek    string
+		wantMonths  int
+		wantDays    int
+		clockOffset time.Duration
+	}{
+		{
+			name: "day before a leap day birthday in a common year", majority: 18, wantMinor: true,
+			wantYears: 17, wantMonths: 11, wantDays: 30, wantNext: "2026-03-01", wantUntil: 1,
+			wantZodiac: "pisces", wantWeek: "2008-W09",
+		},
+		{
+			name: "leap day birthday observed on Mar 1", majority: 18, wantMinor: false,
+			wantYears: 18, wantNext: "2026-03-01", wantUntil: 0,
+			wantZodiac: "pisces", wantWeek: "2008-W09", clockOffset: 24 * time.Hour,
+		},
+		{
+			name: "custom majority age", majority: 21, wantMinor: true,
+			wantYears: 18, wantNext: "2026-03-01", wantUntil: 0,
+			wantZodiac: "pisces", wantWeek: "2008-W09", clockOffset: 24 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newBatchRepo(sqlc.User{ID: 1, Name: "Alice", Dob: dob, Version: 1})
+			c := clock.NewFake(fake.Now().Add(tt.clockOffset))
+			s := NewUserService(repo, zap.NewNop(), Config{Clock: c, MajorityAge: tt.majority})
+
+			user, err := s.GetUserByID(context.Background(), 1, false, models.DefaultFields|models.AgeDetail)
+			if err != nil {
+				t.Fatalf("GetUserByID() error = %v", err)
+			}
+			if *user.AgeYears != tt.wantYears || *user.AgeMonths != tt.wantMonths || *user.AgeDays != tt.wantDays {
+				t.Errorf("age = %dy %dm %dd, want %dy %dm %dd",
+					*user.AgeYears, *user.AgeMonths, *user.AgeDays, tt.wantYears, tt.wantMonths, tt.wantDays)
+			}
+			if *user.Age != *user.AgeYears {
+				t.Errorf("Age = %d, AgeYears = %d, want equal", *user.Age, *user.AgeYears)
+			}
+			if *user.NextBirthday != tt.wantNext || *user.DaysUntilBirthday != tt.wantUntil {
+				t.Errorf("next birthday = %s in %d days, want %s in %d days",
+					*user.NextBirthday, *user.DaysUntilBirthday, tt.wantNext, tt.wantUntil)
+			}
+			if *user.ZodiacSign != tt.wantZodiac || *user.BirthWeek != tt.wantWeek {
+				t.Errorf("zodiac, week = %s, %s, want %s, %s", *user.ZodiacSign, *user.BirthWeek, tt.wantZodiac, tt.wantWeek)
+			}
+			if *user.IsMinor != tt.wantMinor {
+				t.Errorf("IsMinor = %v, want %v", *user.IsMinor, tt.wantMinor)
+			}
+		})
+	}
+
+	t.Run("only selected fields", func(t *testing.T) {
+		repo := newBatchRepo(sqlc.User{ID: 1, Name: "Alice", Dob: dob, Version: 1})
+		s := NewUserService(repo, zap.NewNop(), Config{Clock: fake})
+
+		user, err := s.GetUserByID(context.Background(), 1, false, models.FieldIsMinor)
+		if err != nil {
+			t.Fatalf("GetUserByID() error = %v", err)
+		}
+		if user.IsMinor == nil || user.NextBirthday != nil || user.AgeYears != nil {
+			t.Errorf("got IsMinor=%v NextBirthday=%v AgeYears=%v, want only IsMinor", user.IsMinor, user.NextBirthday, user.AgeYears)
+		}
+	})
+}
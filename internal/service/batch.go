@@ -0,0 +1,97 @@
+// internal/service/batch.go
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// errBatchFailed rolls back an atomic batch once an operation has failed
+var errBatchFailed = errors.New("batch operation failed")
+
+// ExecuteBatch applies req.Operations in order and reports the outcome of
+// each. In atomic mode the first failure rolls back the whole batch and
+// every other operation is reported as aborted; the returned error is only
+// set when the batch as a whole could not run.
+func (s *userService) ExecuteBatch(ctx context.Context, req models.BatchRequest) ([]models.BatchItemResult, error) {
+	results := make([]models.BatchItemResult, len(req.Operations))
+	invalid := false
+	for i := range req.Operations {
+		op := &req.Operations[i]
+		results[i] = models.BatchItemResult{Index: i, Op: op.Op, ID: op.ID}
+		if err := op.Validate(); err != nil {
+			results[i].Err = err
+			invalid = true
+		}
+	}
+
+	if req.Mode == models.BatchBestEffort {
+		for i := range req.Operations {
+			if results[i].Err == nil {
+				s.applyOperation(ctx, &req.Operations[i], &results[i])
+			}
+		}
+		return results, nil
+	}
+
+	if invalid {
+		abortBatch(results)
+		return results, nil
+	}
+
+	err := s.repo.InTx(ctx, func(repo repository.UserRepository) error {
+		tx := s.withRepo(repo)
+		for i := range req.Operations {
+			if tx.applyOperation(ctx, &req.Operations[i], &results[i]); results[i].Err != nil {
+				return errBatchFailed
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchFailed) {
+		abortBatch(results)
+		return results, nil
+	}
+	if err != nil {
+		s.logger.Error("Failed to commit batch", zap.Error(err))
+		return nil, storeError(err, "failed to commit batch")
+	}
+
+	s.logger.Info("Batch applied", zap.Int("operations", len(results)))
+	return results, nil
+}
+
+// applyOperation runs a validated operation and records its outcome
+func (s *userService) applyOperation(ctx context.Context, op *models.BatchOperation, result *models.BatchItemResult) {
+	switch op.Op {
+	case models.BatchCreate:
+		result.User, result.Err = s.CreateUser(ctx, models.CreateUserRequest{Name: op.Name, DOB: op.DOB, Timezone: op.Timezone})
+	case models.BatchUpdate:
+		result.User, result.Err = s.UpdateUser(ctx, op.ID, models.UpdateUserRequest{Name: op.Name, DOB: op.DOB, Timezone: op.Timezone}, op.Version)
+	case models.BatchDelete:
+		result.Err = s.DeleteUser(ctx, op.ID, op.Version)
+	}
+	if result.User != nil {
+		result.ID = result.User.ID
+	}
+}
+
+// abortBatch marks every operation of a rolled back batch that did not fail
+// itself as aborted
+func abortBatch(results []models.BatchItemResult) {
+	for i := range results {
+		if results[i].Err != nil {
+			continue
+		}
+		if results[i].Op == models.BatchCreate {
+			results[i].ID = 0
+		}
+		results[i].User = nil
+		results[i].Err = apperrors.Aborted("not applied because another operation in the batch failed")
+	}
+}
@@ -0,0 +1,216 @@
+// internal/service/batch_test.go
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// batchRepo stores users in memory. InTx works on a copy that replaces the
+// original only when fn succeeds.
+type batchRepo struct {
+	repository.UserRepository
+	users  map[int32]sqlc.User
+	nextID int32
+	// deleted holds soft-deleted users, including merged ones
+	deleted map[int32]sqlc.User
+	// merged maps merged users to their survivors
+	merged  map[int32]int32
+	history []sqlc.CreateUserHistoryParams
+	events  []sqlc.CreateOutboxEventParams
+}
+
+func newBatchRepo(users ...sqlc.User) *batchRepo {
+	r := &batchRepo{users: map[int32]sqlc.User{}, deleted: map[int32]sqlc.User{}, merged: map[int32]int32{}}
+	for _, u := range users {
+		r.users[u.ID] = u
+		r.nextID = max(r.nextID, u.ID)
+	}
+	return r
+}
+
+func (r *batchRepo) CreateUser(_ context.Context, name string, dob time.Time, _ *string) (*sqlc.User, error) {
+	r.nextID++
+	u := sqlc.User{ID: r.nextID, Name: name, Dob: dob, Version: 1}
+	r.users[u.ID] = u
+	return &u, nil
+}
+
+func (r *batchRepo) GetUserByID(_ context.Context, id int32, includeDeleted bool, _ ...string) (*sqlc.User, error) {
+	u, ok := r.users[id]
+	if !ok && includeDeleted {
+		u, ok = r.deleted[id]
+	}
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &u, nil
+}
+
+func (r *batchRepo) LockUser(ctx context.Context, id int32, includeDeleted bool) (*sqlc.User, error) {
+	return r.GetUserByID(ctx, id, includeDeleted)
+}
+
+func (r *batchRepo) RecordHistory(_ context.Context, entry sqlc.CreateUserHistoryParams) error {
+	r.history = append(r.history, entry)
+	return nil
+}
+
+func (r *batchRepo) EnqueueEvent(_ context.Context, event sqlc.CreateOutboxEventParams) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *batchRepo) UpdateUser(_ context.Context, id int32, name string, dob time.Time, timezone *string, expectedVersion *int32) (*sqlc.User, error) {
+	u, ok := r.users[id]
+	if !ok || (expectedVersion != nil && *expectedVersion != u.Version) {
+		return nil, sql.ErrNoRows
+	}
+	u.Name, u.Dob, u.Timezone, u.Version = name, dob, nullString(timezone), u.Version+1
+	r.users[id] = u
+	return &u, nil
+}
+
+func (r *batchRepo) DeleteUser(_ context.Context, id int32, expectedVersion *int32) error {
+	u, ok := r.users[id]
+	if !ok || (expectedVersion != nil && *expectedVersion != u.Version) {
+		return sql.ErrNoRows
+	}
+	delete(r.users, id)
+	u.DeletedAt, u.Version = sql.NullTime{Time: time.Now(), Valid: true}, u.Version+1
+	r.deleted[id] = u
+	return nil
+}
+
+func (r *batchRepo) MergeUser(_ context.Context, sourceID, targetID, expectedVersion int32) ([]sqlc.User, error) {
+	u, ok := r.users[sourceID]
+	if !ok || u.Version != expectedVersion {
+		return nil, sql.ErrNoRows
+	}
+	delete(r.users, sourceID)
+	u.DeletedAt, u.Version = sql.NullTime{Time: time.Now(), Valid: true}, u.Version+1
+	u.MergedInto = sql.NullInt32{Int32: targetID, Valid: true}
+	r.deleted[sourceID] = u
+
+	var repointed []sqlc.User
+	for id, into := range r.merged {
+		if into == sourceID {
+			r.merged[id] = targetID
+			tombstone := r.deleted[id]
+			tombstone.MergedInto, tombstone.Version = u.MergedInto, tombstone.Version+1
+			r.deleted[id] = tombstone
+			repointed = append(repointed, tombstone)
+		}
+	}
+	r.merged[sourceID] = targetID
+	return repointed, nil
+}
+
+func (r *batchRepo) GetMergedInto(_ context.Context, id int32) (int32, error) {
+	into, ok := r.merged[id]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	return into, nil
+}
+
+func (r *batchRepo) InTx(_ context.Context, fn func(repo repository.UserRepository) error) error {
+	tx := newBatchRepo()
+	for id, u := range r.users {
+		tx.users[id] = u
+	}
+	for id, u := range r.deleted {
+		tx.deleted[id] = u
+	}
+	for id, into := range r.merged {
+		tx.merged[id] = into
+	}
+	tx.nextID = r.nextID
+	tx.history = append(tx.history, r.history...)
+	tx.events = append(tx.events, r.events...)
+	if err := fn(tx); err != nil {
+		return err
+	}
+	r.users, r.nextID, r.deleted, r.merged, r.history, r.events = tx.users, tx.nextID, tx.deleted, tx.merged, tx.history, tx.events
+	return nil
+}
+
+func TestExecuteBatch(t *testing.T) {
+	existing := sqlc.User{ID: 1, Name: "Alice", Dob: time.Date(1990, 5, 10, 0, 0, 0, 0, time.UTC), Version: 1}
+	operations := []models.BatchOperation{
+		{Op: models.BatchCreate, Name: "Bob", DOB: "1985-01-02"},
+		{Op: models.BatchUpdate, ID: 1, Name: "Alicia", DOB: "1990-05-10"},
+		{Op: models.BatchDelete, ID: 42},
+	}
+
+	tests := []struct {
+		name      string
+		mode      models.BatchMode
+		wantKinds []error
+		wantUsers int
+	}{
+		{
+			name:      "Atomic rolls back",
+			mode:      models.BatchAtomic,
+			wantKinds: []error{apperrors.ErrAborted, apperrors.ErrAborted, apperrors.ErrNotFound},
+			wantUsers: 1,
+		},
+		{
+			name:      "Best effort keeps successes",
+			mode:      models.BatchBestEffort,
+			wantKinds: []error{nil, nil, apperrors.ErrNotFound},
+			wantUsers: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newBatchRepo(existing)
+			s := NewUserService(repo, zap.NewNop(), Config{})
+
+			results, err := s.ExecuteBatch(context.Background(), models.BatchRequest{Mode: tt.mode, Operations: operations})
+			if err != nil {
+				t.Fatalf("ExecuteBatch() error = %v", err)
+			}
+			for i, want := range tt.wantKinds {
+				if got := results[i].Err; (want == nil) != (got == nil) || (want != nil && !errors.Is(got, want)) {
+					t.Errorf("results[%d].Err = %v, want %v", i, got, want)
+				}
+			}
+			if len(repo.users) != tt.wantUsers {
+				t.Errorf("stored %d users, want %d", len(repo.users), tt.wantUsers)
+			}
+		})
+	}
+}
+
+func TestExecuteBatch_AtomicValidatesFirst(t *testing.T) {
+	repo := newBatchRepo()
+	s := NewUserService(repo, zap.NewNop(), Config{})
+
+	results, err := s.ExecuteBatch(context.Background(), models.BatchRequest{
+		Mode: models.BatchAtomic,
+		Operations: []models.BatchOperation{
+			{Op: models.BatchCreate, Name: "Bob", DOB: "1985-01-02"},
+			{Op: models.BatchUpdate, Name: "No ID", DOB: "1985-01-02"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteBatch() error = %v", err)
+	}
+	if !errors.Is(results[0].Err, apperrors.ErrAborted) || !errors.Is(results[1].Err, apperrors.ErrValidation) {
+		t.Errorf("errors = %v, %v; want aborted, validation", results[0].Err, results[1].Err)
+	}
+	if len(repo.users) != 0 {
+		t.Errorf("stored %d users, want none", len(repo.users))
+	}
+}
@@ -0,0 +1,77 @@
+// internal/service/birthdays.go
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"go.uber.org/zap"
+)
+
+// MaxBirthdayWindow is the largest number of days UpcomingBirthdays looks
+// ahead, which covers every birthday
+const MaxBirthdayWindow = 366
+
+// UpcomingBirthdays returns the users whose next birthday is at most within
+// days away, soonest first, with next_birthday and days_until_birthday set.
+// Days are counted in the location requested for ctx, or UTC; unlike other
+// reads, users' own time zones are not used so the window is the same for
+// everyone.
+func (s *userService) UpcomingBirthdays(ctx context.Context, within int) ([]models.UserResponse, error) {
+	if within < 0 || within > MaxBirthdayWindow {
+		return nil, invalidWithin()
+	}
+	if _, ok := ctx.Value(locationKey{}).(*time.Location); !ok {
+		ctx = WithLocation(ctx, time.UTC)
+	}
+
+	users, err := s.repo.ListUsersByBirthdays(ctx, birthdayDays(s.now(ctx), within))
+	if err != nil {
+		s.logger.Error("Failed to list upcoming birthdays", zap.Error(err))
+		return nil, storeError(err, "failed to list upcoming birthdays")
+	}
+
+	responses := make([]models.UserResponse, 0, len(users))
+	for i := range users {
+		response := s.userResponse(ctx, &users[i], models.DefaultFields|models.FieldNextBirthday|models.FieldDaysUntilBirthday)
+		if *response.DaysUntilBirthday <= within {
+			responses = append(responses, response)
+		}
+	}
+	// Users arrive ordered by id, which breaks ties
+	sort.SliceStable(responses, func(i, j int) bool {
+		return *responses[i].DaysUntilBirthday < *responses[j].DaysUntilBirthday
+	})
+	return responses, nil
+}
+
+// birthdayDays lists the days from today through within days later whose
+// month and day a birthday in the window falls on. Feb 29 birthdays are
+// observed on Mar 1 in common years, so Mar 1 also brings in Feb 29.
+func birthdayDays(today time.Time, within int) []time.Time {
+	days := make([]time.Time, 0, within+2)
+	for i := 0; i <= within; i++ {
+		day := time.Date(today.Year(), today.Month(), today.Day()+i, 0, 0, 0, 0, time.UTC)
+		days = append(days, day)
+		if day.Month() == time.March && day.Day() == 1 && !isLeapYear(day.Year()) {
+			// Only the month and day matter, but the year must have a Feb 29
+			days = append(days, time.Date(2000, time.February, 29, 0, 0, 0, 0, time.UTC))
+		}
+	}
+	return days
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+func invalidWithin() error {
+	return apperrors.Validation("Invalid within", apperrors.FieldError{
+		Field:   "within",
+		Rule:    "max",
+		Message: "within must be between 0d and 366d",
+	})
+}
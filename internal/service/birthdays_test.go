@@ -0,0 +1,115 @@
+// internal/service/birthdays_test.go
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/clock"
+	"go.uber.org/zap"
+)
+
+// birthdayRepo answers ListUsersByBirthdays by matching month and day the
+// way the SQL query does
+type birthdayRepo struct {
+	*batchRepo
+}
+
+func (r birthdayRepo) ListUsersByBirthdays(_ context.Context, days []time.Time) ([]sqlc.User, error) {
+	var users []sqlc.User
+	for id := int32(1); id <= r.nextID; id++ {
+		u, ok := r.users[id]
+		if !ok {
+			continue
+		}
+		for _, d := range days {
+			if u.Dob.Month() == d.Month() && u.Dob.Day() == d.Day() {
+				users = append(users, u)
+				break
+			}
+		}
+	}
+	return users, nil
+}
+
+func TestUpcomingBirthdays(t *testing.T) {
+	user := func(id int32, dob string) sqlc.User {
+		d, _ := time.Parse("2006-01-02", dob)
+		return sqlc.User{ID: id, Name: dob, Dob: d, Version: 1}
+	}
+	users := []sqlc.User{
+		user(1, "1990-01-02"),
+		user(2, "1985-12-30"),
+		user(3, "2000-02-29"),
+		user(4, "1970-03-01"),
+		user(5, "1995-12-28"),
+		user(6, "1980-06-15"),
+	}
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		within int
+		want   []int32
+	}{
+		{name: "Across the new year", now: time.Date(2025, 12, 28, 9, 0, 0, 0, time.UTC), within: 7, want: []int32{5, 2, 1}},
+		{name: "Today only", now: time.Date(2025, 12, 28, 9, 0, 0, 0, time.UTC), within: 0, want: []int32{5}},
+		{name: "Leap day excluded before Mar 1 in a common year", now: time.Date(2025, 2, 27, 9, 0, 0, 0, time.UTC), within: 1, want: []int32{}},
+		{name: "Leap day observed on Mar 1 in a common year", now: time.Date(2025, 2, 27, 9, 0, 0, 0, time.UTC), within: 2, want: []int32{3, 4}},
+		{name: "Leap day in a leap year", now: time.Date(2028, 2, 28, 9, 0, 0, 0, time.UTC), within: 1, want: []int32{3}},
+		{name: "Whole year", now: time.Date(2025, 6, 15, 9, 0, 0, 0, time.UTC), within: 366, want: []int32{6, 5, 2, 1, 3, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := birthdayRepo{newBatchRepo(users...)}
+			s := NewUserService(repo, zap.NewNop(), Config{Clock: clock.NewFake(tt.now)})
+
+			got, err := s.UpcomingBirthdays(context.Background(), tt.within)
+			if err != nil {
+				t.Fatalf("UpcomingBirthdays() error = %v", err)
+			}
+			ids := []int32{}
+			for _, u := range got {
+				ids = append(ids, u.ID)
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("UpcomingBirthdays() = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("UpcomingBirthdays() = %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestUpcomingBirthdays_RequestedLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	dob, _ := time.Parse("2006-01-02", "1990-05-10")
+	repo := birthdayRepo{newBatchRepo(sqlc.User{ID: 1, Name: "Alice", Dob: dob, Version: 1})}
+	// 16:00 UTC on the 9th is already the 10th in Tokyo
+	s := NewUserService(repo, zap.NewNop(), Config{Clock: clock.NewFake(time.Date(2024, 5, 9, 16, 0, 0, 0, time.UTC))})
+
+	got, err := s.UpcomingBirthdays(WithLocation(context.Background(), tokyo), 0)
+	if err != nil {
+		t.Fatalf("UpcomingBirthdays() error = %v", err)
+	}
+	if len(got) != 1 || *got[0].DaysUntilBirthday != 0 {
+		t.Fatalf("UpcomingBirthdays() = %+v, want Alice today", got)
+	}
+
+	got, err = s.UpcomingBirthdays(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("UpcomingBirthdays() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("UpcomingBirthdays() in UTC = %+v, want none", got)
+	}
+}
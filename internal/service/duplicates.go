@@ -0,0 +1,145 @@
+// internal/service/duplicates.go
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// DuplicatePolicy is what CreateUser does when a new user has the same
+// normalized name and dob as a live user, or a similar enough name when
+// fuzzy matching is on
+type DuplicatePolicy string
+
+const (
+	// DuplicateAllow creates the user without checking
+	DuplicateAllow DuplicatePolicy = "allow"
+	// DuplicateWarn creates the user and lists the matches in the response
+	DuplicateWarn DuplicatePolicy = "warn"
+	// DuplicateReject refuses with a conflict naming the closest match
+	DuplicateReject DuplicatePolicy = "reject"
+)
+
+// createChecked creates a user after looking for duplicates. The check and
+// insert run under a lock on the normalized name and dob, so two identical
+// concurrent creates cannot both pass it.
+func (s *userService) createChecked(ctx context.Context, req models.CreateUserRequest, dob time.Time) (*models.UserResponse, error) {
+	var response models.UserResponse
+	err := s.repo.InTx(ctx, func(repo repository.UserRepository) error {
+		if err := repo.LockUserIdentity(ctx, req.Name, dob); err != nil {
+			return err
+		}
+		duplicates, err := repo.FindDuplicateUsers(ctx, req.Name, dob, s.config.DuplicateMinSimilarity)
+		if err != nil {
+			return err
+		}
+		if len(duplicates) > 0 && s.config.DuplicatePolicy == DuplicateReject {
+			return duplicateUser(duplicates[0].ID)
+		}
+
+		user, err := repo.CreateUser(ctx, req.Name, dob, req.Timezone)
+		if err != nil {
+			return err
+		}
+		if err := s.recordChange(ctx, repo, historyCreate, nil, user); err != nil {
+			return err
+		}
+		response = toUserResponse(user)
+		for _, d := range duplicates {
+			response.PossibleDuplicates = append(response.PossibleDuplicates, d.ID)
+		}
+		return nil
+	})
+	if errors.Is(err, apperrors.ErrConflict) {
+		return nil, err
+	}
+	if err != nil {
+		s.logger.Error("Failed to create user", zap.Error(err))
+		return nil, storeError(err, "failed to create user")
+	}
+
+	if len(response.PossibleDuplicates) > 0 {
+		s.logger.Warn("Created a probable duplicate user",
+			zap.Int32("user_id", response.ID),
+			zap.Int32s("duplicates", response.PossibleDuplicates),
+		)
+	} else {
+		s.logger.Info("User created successfully", zap.Int32("user_id", response.ID))
+	}
+	return &response, nil
+}
+
+// DuplicateClusters groups live users into clusters of probable duplicates,
+// largest first
+func (s *userService) DuplicateClusters(ctx context.Context, query models.DuplicatesQuery) ([]models.DuplicateCluster, error) {
+	minSimilarity := s.config.DuplicateMinSimilarity
+	if query.MinSimilarity != nil {
+		minSimilarity = *query.MinSimilarity
+	}
+
+	pairs, err := s.repo.ListDuplicatePairs(ctx, minSimilarity)
+	if err != nil {
+		s.logger.Error("Failed to list duplicate users", zap.Error(err))
+		return nil, storeError(err, "failed to list duplicate users")
+	}
+
+	// Union-find over the matched pairs; a cluster is named by its lowest id
+	parent := make(map[int32]int32)
+	var find func(id int32) int32
+	find = func(id int32) int32 {
+		if p, ok := parent[id]; ok && p != id {
+			parent[id] = find(p)
+			return parent[id]
+		}
+		parent[id] = id
+		return id
+	}
+
+	users := make(map[int32]models.UserResponse)
+	for _, p := range pairs {
+		dob := p.Dob.Format("2006-01-02")
+		users[p.ID] = models.UserResponse{ID: p.ID, Name: p.Name, DOB: dob}
+		users[p.DuplicateID] = models.UserResponse{ID: p.DuplicateID, Name: p.DuplicateName, DOB: dob}
+
+		a, b := find(p.ID), find(p.DuplicateID)
+		if a > b {
+			a, b = b, a
+		}
+		parent[b] = a
+	}
+
+	clusters := make(map[int32]*models.DuplicateCluster)
+	for _, p := range pairs {
+		root := find(p.ID)
+		c, ok := clusters[root]
+		if !ok {
+			c = &models.DuplicateCluster{DOB: p.Dob.Format("2006-01-02"), Similarity: p.Similarity}
+			clusters[root] = c
+		}
+		c.Similarity = min(c.Similarity, p.Similarity)
+	}
+	for id, u := range users {
+		c := clusters[find(id)]
+		c.Users = append(c.Users, u)
+	}
+
+	result := make([]models.DuplicateCluster, 0, len(clusters))
+	for _, c := range clusters {
+		sort.Slice(c.Users, func(i, j int) bool { return c.Users[i].ID < c.Users[j].ID })
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i].Users) != len(result[j].Users) {
+			return len(result[i].Users) > len(result[j].Users)
+		}
+		return result[i].Users[0].ID < result[j].Users[0].ID
+	})
+	return result, nil
+}
@@ -0,0 +1,152 @@
+// internal/service/duplicates_test.go
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// duplicateRepo matches users on case and space insensitive names, the way
+// the exact SQL match does
+type duplicateRepo struct {
+	*batchRepo
+}
+
+func (r duplicateRepo) InTx(ctx context.Context, fn func(repo repository.UserRepository) error) error {
+	return r.batchRepo.InTx(ctx, func(tx repository.UserRepository) error {
+		return fn(duplicateRepo{tx.(*batchRepo)})
+	})
+}
+
+func (r duplicateRepo) LockUserIdentity(context.Context, string, time.Time) error {
+	return nil
+}
+
+func (r duplicateRepo) FindDuplicateUsers(_ context.Context, name string, dob time.Time, _ float64) ([]sqlc.FindDuplicateUsersRow, error) {
+	var rows []sqlc.FindDuplicateUsersRow
+	for id := int32(1); id <= r.nextID; id++ {
+		u, ok := r.users[id]
+		if ok && u.Dob.Equal(dob) && normalize(u.Name) == normalize(name) {
+			rows = append(rows, sqlc.FindDuplicateUsersRow{ID: u.ID, Name: u.Name, Similarity: 1})
+		}
+	}
+	return rows, nil
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func TestCreateUser_DuplicatePolicy(t *testing.T) {
+	existing := sqlc.User{ID: 1, Name: "Alice  Smith", Dob: time.Date(1990, 5, 10, 0, 0, 0, 0, time.UTC), Version: 1}
+	duplicate := models.CreateUserRequest{Name: "alice smith", DOB: "1990-05-10"}
+
+	tests := []struct {
+		name           string
+		policy         DuplicatePolicy
+		req            models.CreateUserRequest
+		wantConflict   bool
+		wantDuplicates []int32
+	}{
+		{name: "Allow", policy: DuplicateAllow, req: duplicate},
+		{name: "Warn", policy: DuplicateWarn, req: duplicate, wantDuplicates: []int32{1}},
+		{name: "Reject", policy: DuplicateReject, req: duplicate, wantConflict: true},
+		{name: "Reject different dob", policy: DuplicateReject, req: models.CreateUserRequest{Name: "Alice Smith", DOB: "1990-05-11"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := duplicateRepo{newBatchRepo(existing)}
+			s := NewUserService(repo, zap.NewNop(), Config{DuplicatePolicy: tt.policy})
+
+			user, err := s.CreateUser(context.Background(), tt.req)
+			if tt.wantConflict {
+				var appErr *apperrors.Error
+				if !errors.As(err, &appErr) || appErr.Kind != apperrors.ErrConflict {
+					t.Fatalf("CreateUser() error = %v, want conflict", err)
+				}
+				if appErr.Details["conflicting_id"] != int32(1) {
+					t.Errorf("conflicting_id = %v, want 1", appErr.Details["conflicting_id"])
+				}
+				if len(repo.users) != 1 {
+					t.Errorf("users = %d, want 1", len(repo.users))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateUser() error = %v", err)
+			}
+			if len(user.PossibleDuplicates) != len(tt.wantDuplicates) {
+				t.Fatalf("PossibleDuplicates = %v, want %v", user.PossibleDuplicates, tt.wantDuplicates)
+			}
+			for i, id := range tt.wantDuplicates {
+				if user.PossibleDuplicates[i] != id {
+					t.Errorf("PossibleDuplicates = %v, want %v", user.PossibleDuplicates, tt.wantDuplicates)
+				}
+			}
+		})
+	}
+}
+
+// pairsRepo returns fixed duplicate pairs
+type pairsRepo struct {
+	repository.UserRepository
+	pairs []sqlc.ListDuplicatePairsRow
+}
+
+func (r pairsRepo) ListDuplicatePairs(context.Context, float64) ([]sqlc.ListDuplicatePairsRow, error) {
+	return r.pairs, nil
+}
+
+func TestDuplicateClusters(t *testing.T) {
+	dob := time.Date(1990, 5, 10, 0, 0, 0, 0, time.UTC)
+	pair := func(id, dup int32, similarity float64) sqlc.ListDuplicatePairsRow {
+		return sqlc.ListDuplicatePairsRow{ID: id, Name: "n", Dob: dob, DuplicateID: dup, DuplicateName: "n", Similarity: similarity}
+	}
+	repo := pairsRepo{pairs: []sqlc.ListDuplicatePairsRow{
+		pair(7, 9, 1),
+		pair(2, 5, 0.8),
+		// 5 and 8 joins 8 to the 2-5 cluster
+		pair(5, 8, 0.6),
+	}}
+	s := NewUserService(repo, zap.NewNop(), Config{})
+
+	clusters, err := s.DuplicateClusters(context.Background(), models.DuplicatesQuery{})
+	if err != nil {
+		t.Fatalf("DuplicateClusters() error = %v", err)
+	}
+
+	want := []struct {
+		ids        []int32
+		similarity float64
+	}{
+		{[]int32{2, 5, 8}, 0.6},
+		{[]int32{7, 9}, 1},
+	}
+	if len(clusters) != len(want) {
+		t.Fatalf("clusters = %d, want %d", len(clusters), len(want))
+	}
+	for i, w := range want {
+		c := clusters[i]
+		if c.Similarity != w.similarity || c.DOB != "1990-05-10" {
+			t.Errorf("cluster %d similarity, dob = %v, %s, want %v, 1990-05-10", i, c.Similarity, c.DOB, w.similarity)
+		}
+		if len(c.Users) != len(w.ids) {
+			t.Fatalf("cluster %d users = %d, want %d", i, len(c.Users), len(w.ids))
+		}
+		for j, id := range w.ids {
+			if c.Users[j].ID != id {
+				t.Errorf("cluster %d user %d = %d, want %d", i, j, c.Users[j].ID, id)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// internal/service/errors.go
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"github.com/lib/pq"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+)
+
+func userNotFound(id int32) error {
+	return apperrors.NotFound("user %d not found", id)
+}
+
+func userMerged(id, into int32) error {
+	return apperrors.Moved("user %d was merged into user %d", id, into).With("merged_into", into)
+}
+
+func versionMismatch(id, current int32) error {
+	return apperrors.PreconditionFailed("user %d has been modified, current version is %d", id, current)
+}
+
+func duplicateUser(id int32) error {
+	return apperrors.Conflict("a user with the same name and date of birth already exists").With("conflicting_id", id)
+}
+
+func invalidCursor() error {
+	return apperrors.Validation("Invalid cursor", apperrors.FieldError{
+		Field:   "cursor",
+		Rule:    "cursor",
+		Message: "cursor is malformed, expired or belongs to a different query",
+	})
+}
+
+func invalidDOB() error {
+	return apperrors.Validation("Validation failed", apperrors.FieldError{
+		Field:   "dob",
+		Rule:    "datetime",
+		Message: "dob must be a date in YYYY-MM-DD format",
+	})
+}
+
+// storeError classifies a repository error so callers can map it to a status;
+// errors it does not recognise are returned unchanged
+func storeError(err error, message string) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code.Name() == "unique_violation":
+			return &apperrors.Error{Kind: apperrors.ErrConflict, Message: message + ": resource already exists", Err: err}
+		case pqErr.Code.Class() == "08", pqErr.Code.Class() == "57", pqErr.Code.Class() == "53":
+			return apperrors.Unavailable(err, "%s: database unavailable", message)
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) {
+		return apperrors.Unavailable(err, "%s: database unavailable", message)
+	}
+
+	return err
+}
@@ -0,0 +1,63 @@
+// internal/service/events.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/outbox"
+	"github.com/shravanirajulu2004/go-user-api/internal/repository"
+)
+
+// eventTypes maps history actions to the event announcing them. Merges are
+// announced as an update of the survivor and a deletion of the user merged
+// away.
+var eventTypes = map[string]string{
+	historyCreate:  outbox.UserCreated,
+	historyUpdate:  outbox.UserUpdated,
+	historyRestore: outbox.UserUpdated,
+	historyMerge:   outbox.UserUpdated,
+	historyDelete:  outbox.UserDeleted,
+	historyMerged:  outbox.UserDeleted,
+}
+
+// userEventData is the data of a user event: the change as recorded in the
+// user's history, and the user after it
+type userEventData struct {
+	Action    string               `json:"action"`
+	Version   int32                `json:"version"`
+	Actor     string               `json:"actor,omitempty"`
+	RequestID string               `json:"request_id,omitempty"`
+	User      eventUser            `json:"user"`
+	Changes   []models.FieldChange `json:"changes"`
+}
+
+type eventUser struct {
+	ID int32 `json:"id"`
+	userSnapshot
+}
+
+// enqueueEvent adds the event announcing a change to the outbox of repo,
+// which must be the transaction that made the change
+func enqueueEvent(ctx context.Context, repo repository.UserRepository, action string, id, version int32, user userSnapshot, changes []models.FieldChange) error {
+	actor := actorFrom(ctx)
+	payload, err := json.Marshal(userEventData{
+		Action:    action,
+		Version:   version,
+		Actor:     actor.Name,
+		RequestID: actor.RequestID,
+		User:      eventUser{ID: id, userSnapshot: user},
+		Changes:   changes,
+	})
+	if err != nil {
+		return err
+	}
+
+	return repo.EnqueueEvent(ctx, sqlc.CreateOutboxEventParams{
+		EventType: eventTypes[action],
+		UserID:    id,
+		Payload:   payload,
+	})
+}
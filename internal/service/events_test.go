@@ -0,0 +1,66 @@
+// internal/service/events_test.go
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/outbox"
+	"go.uber.org/zap"
+)
+
+func TestUserChanges_EnqueueEvents(t *testing.T) {
+	dob := time.Date(1990, 5, 10, 0, 0, 0, 0, time.UTC)
+	repo := newBatchRepo(sqlc.User{ID: 1, Name: "Alice", Dob: dob, Version: 1})
+	s := NewUserService(repo, zap.NewNop(), Config{})
+	ctx := WithActor(context.Background(), Actor{Name: "crm-sync", RequestID: "req-7"})
+
+	created, err := s.CreateUser(ctx, models.CreateUserRequest{Name: "Alice Smith", DOB: "1990-05-10"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if _, err := s.UpdateUser(ctx, 1, models.UpdateUserRequest{Name: "Alicia", DOB: "1990-05-10"}, nil); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if _, err := s.MergeUsers(ctx, 1, models.MergeUsersRequest{SourceID: created.ID}, nil); err != nil {
+		t.Fatalf("MergeUsers() error = %v", err)
+	}
+
+	want := []struct {
+		eventType string
+		userID    int32
+		action    string
+	}{
+		{outbox.UserCreated, created.ID, historyCreate},
+		{outbox.UserUpdated, 1, historyUpdate},
+		{outbox.UserUpdated, 1, historyMerge},
+		{outbox.UserDeleted, created.ID, historyMerged},
+	}
+	if len(repo.events) != len(want) {
+		t.Fatalf("enqueued %d events, want %d", len(repo.events), len(want))
+	}
+	for i, w := range want {
+		event := repo.events[i]
+		var data userEventData
+		if err := json.Unmarshal(event.Payload, &data); err != nil {
+			t.Fatalf("event %d: %v", i, err)
+		}
+		if event.EventType != w.eventType || event.UserID != w.userID || data.Action != w.action {
+			t.Errorf("event %d = %s user %d (%s), want %s user %d (%s)",
+				i, event.EventType, event.UserID, data.Action, w.eventType, w.userID, w.action)
+		}
+		if data.User.ID != event.UserID || data.Actor != "crm-sync" || data.RequestID != "req-7" {
+			t.Errorf("event %d data = %+v", i, data)
+		}
+	}
+
+	var update userEventData
+	_ = json.Unmarshal(repo.events[1].Payload, &update)
+	if update.User.Name != "Alicia" || len(update.Changes) != 1 || update.Changes[0].From != "Alice" {
+		t.Errorf("update event = %+v, want name changed from Alice to Alicia", update)
+	}
+}
@@ -0,0 +1,71 @@
+// internal/service/export.go
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// UserExport streams the users selected by ExportUsers to fn. An error from
+// fn stops the export and is returned as is.
+type UserExport func(ctx context.Context, fn func(user *models.UserResponse) error) error
+
+// ExportUsers checks filter and returns an export of every user matching
+// it, ages included, in the filter's sort order. Nothing is read until the
+// export runs, so callers can reject a bad filter before streaming.
+func (s *userService) ExportUsers(ctx context.Context, filter models.UserFilter, includeDeleted bool) (UserExport, error) {
+	order, err := parseSort(filter.Sort)
+	if err != nil {
+		return nil, err
+	}
+	repoFilter, err := toRepositoryFilter(filter, includeDeleted, s.now(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	// The export runs later, usually on another context; keep the location
+	// requested for this one
+	loc, hasLoc := ctx.Value(locationKey{}).(*time.Location)
+	return func(ctx context.Context, fn func(user *models.UserResponse) error) error {
+		if hasLoc {
+			ctx = WithLocation(ctx, loc)
+		}
+		return s.export(ctx, repository.ListParams{
+			Filter: repoFilter,
+			Sort:   order.fields,
+			IDDesc: order.idDesc,
+		}, fn)
+	}, nil
+}
+
+func (s *userService) export(ctx context.Context, params repository.ListParams, fn func(user *models.UserResponse) error) error {
+	var fnErr error
+	exported := 0
+	err := s.repo.ExportUsers(ctx, params, func(u *sqlc.User) error {
+		response := s.userResponse(ctx, u, models.DefaultFields)
+		if fnErr = fn(&response); fnErr != nil {
+			return fnErr
+		}
+		exported++
+		return nil
+	})
+	switch {
+	case fnErr != nil:
+		return fnErr
+	case errors.Is(err, context.Canceled):
+		s.logger.Info("User export cancelled", zap.Int("exported", exported))
+		return err
+	case err != nil:
+		s.logger.Error("Failed to export users", zap.Error(err), zap.Int("exported", exported))
+		return storeError(err, "failed to export users")
+	}
+
+	s.logger.Info("Users exported", zap.Int("count", exported))
+	return nil
+}
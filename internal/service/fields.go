@@ -0,0 +1,109 @@
+// internal/service/fields.go
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/age"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+)
+
+// ageFields are the fields computed from dob in the user's time zone
+const ageFields = models.FieldAge | models.AgeDetail
+
+// userColumns returns the columns a read needs for fields, or nil for all
+// of them
+func userColumns(fields models.UserFields) []string {
+	if fields == 0 || fields.Has(models.DefaultFields) {
+		return nil
+	}
+
+	columns := []string{}
+	if fields.Has(models.FieldName) {
+		columns = append(columns, "name")
+	}
+	if fields.Any(models.FieldDOB | ageFields) {
+		columns = append(columns, "dob")
+	}
+	if fields.Any(models.FieldTimezone | ageFields) {
+		columns = append(columns, "timezone")
+	}
+	if fields.Has(models.FieldDeletedAt) {
+		columns = append(columns, "deleted_at")
+	}
+	if fields.Has(models.FieldMergedInto) {
+		columns = append(columns, "merged_into")
+	}
+	return columns
+}
+
+// userResponse converts user to a response holding just fields, computing
+// ages only when they are selected
+func (s *userService) userResponse(ctx context.Context, user *sqlc.User, fields models.UserFields) models.UserResponse {
+	if fields == 0 {
+		fields = models.DefaultFields
+	}
+
+	response := models.UserResponse{ID: user.ID, Version: user.Version}
+	if fields.Has(models.FieldName) {
+		response.Name = user.Name
+	}
+	if fields.Has(models.FieldDOB) {
+		response.DOB = user.Dob.Format("2006-01-02")
+	}
+	if fields.Has(models.FieldTimezone) && user.Timezone.Valid {
+		timezone := user.Timezone.String
+		response.Timezone = &timezone
+	}
+	if fields.Has(models.FieldDeletedAt) && user.DeletedAt.Valid {
+		deletedAt := user.DeletedAt.Time.Format(time.RFC3339)
+		response.DeletedAt = &deletedAt
+	}
+	if fields.Has(models.FieldMergedInto) && user.MergedInto.Valid {
+		mergedInto := user.MergedInto.Int32
+		response.MergedInto = &mergedInto
+	}
+	if !fields.Any(ageFields) {
+		return response
+	}
+
+	now := s.userNow(ctx, user)
+	if fields.Has(models.FieldAge) {
+		a := models.CalculateAgeAt(user.Dob, now)
+		response.Age = &a
+	}
+	if !fields.Any(models.AgeDetail) {
+		return response
+	}
+
+	detail := age.Compute(user.Dob, now)
+	if fields.Has(models.FieldAgeYears) {
+		response.AgeYears = &detail.Years
+	}
+	if fields.Has(models.FieldAgeMonths) {
+		response.AgeMonths = &detail.Months
+	}
+	if fields.Has(models.FieldAgeDays) {
+		response.AgeDays = &detail.Days
+	}
+	if fields.Has(models.FieldNextBirthday) {
+		next := detail.NextBirthday.Format("2006-01-02")
+		response.NextBirthday = &next
+	}
+	if fields.Has(models.FieldDaysUntilBirthday) {
+		response.DaysUntilBirthday = &detail.DaysUntilBirthday
+	}
+	if fields.Has(models.FieldZodiacSign) {
+		response.ZodiacSign = &detail.ZodiacSign
+	}
+	if fields.Has(models.FieldBirthWeek) {
+		response.BirthWeek = &detail.BirthWeek
+	}
+	if fields.Has(models.FieldIsMinor) {
+		minor := detail.Years < s.config.MajorityAge
+		response.IsMinor = &minor
+	}
+	return response
+}
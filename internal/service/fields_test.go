@@ -0,0 +1,52 @@
+// internal/service/fields_test.go
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestUserColumns(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields models.UserFields
+		want   []string
+	}{
+		{name: "Default", fields: models.DefaultFields, want: nil},
+		{name: "Zero", fields: 0, want: nil},
+		{name: "Id only", fields: models.FieldID, want: []string{}},
+		{name: "Id and name", fields: models.FieldID | models.FieldName, want: []string{"name"}},
+		{name: "Age needs dob and timezone", fields: models.FieldID | models.FieldAge, want: []string{"dob", "timezone"}},
+		{name: "Age detail", fields: models.FieldID | models.FieldZodiacSign, want: []string{"dob", "timezone"}},
+		{name: "Deleted at", fields: models.FieldID | models.FieldDeletedAt, want: []string{"deleted_at"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := userColumns(tt.fields); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("userColumns() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetUserByID_SparseFields(t *testing.T) {
+	dob, _ := time.Parse("2006-01-02", "1990-05-10")
+	repo := newBatchRepo(sqlc.User{ID: 1, Name: "Alice", Dob: dob, Version: 2})
+	s := NewUserService(repo, zap.NewNop(), Config{})
+
+	user, err := s.GetUserByID(context.Background(), 1, false, models.FieldID|models.FieldName)
+	if err != nil {
+		t.Fatalf("GetUserByID() error = %v", err)
+	}
+	want := models.UserResponse{ID: 1, Name: "Alice", Version: 2}
+	if !reflect.DeepEqual(*user, want) {
+		t.Errorf("GetUserByID() = %+v, want %+v", *user, want)
+	}
+}
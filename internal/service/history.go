@@ -0,0 +1,238 @@
+// internal/service/history.go
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/clock"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/pagination"
+	"github.com/shravanirajulu2004/go-user-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// Actions recorded in a user's history
+const (
+	historyCreate  = "create"
+	historyUpdate  = "update"
+	historyDelete  = "delete"
+	historyRestore = "restore"
+	historyMerge   = "merge"
+	historyMerged  = "merged"
+)
+
+const (
+	defaultHistoryLimit = 20
+
+	// historySort marks pagination cursors issued for a history
+	historySort = "history"
+)
+
+// userSnapshot is the stored state of a user as kept in its history.
+// Derived values such as ages are left out.
+type userSnapshot struct {
+	Name       string  `json:"name"`
+	DOB        string  `json:"dob"`
+	Timezone   *string `json:"timezone,omitempty"`
+	DeletedAt  *string `json:"deleted_at,omitempty"`
+	MergedInto *int32  `json:"merged_into,omitempty"`
+}
+
+func snapshotOf(user *sqlc.User) userSnapshot {
+	response := toUserResponse(user)
+	return userSnapshot{
+		Name:       response.Name,
+		DOB:        response.DOB,
+		Timezone:   response.Timezone,
+		DeletedAt:  response.DeletedAt,
+		MergedInto: response.MergedInto,
+	}
+}
+
+// user rebuilds the user the snapshot was taken of
+func (u userSnapshot) user(id, version int32) *sqlc.User {
+	user := &sqlc.User{ID: id, Name: u.Name, Version: version, Timezone: nullString(u.Timezone)}
+	user.Dob, _ = time.Parse("2006-01-02", u.DOB)
+	if u.DeletedAt != nil {
+		deletedAt, _ := time.Parse(time.RFC3339, *u.DeletedAt)
+		user.DeletedAt = sql.NullTime{Time: deletedAt, Valid: true}
+	}
+	if u.MergedInto != nil {
+		user.MergedInto = sql.NullInt32{Int32: *u.MergedInto, Valid: true}
+	}
+	return user
+}
+
+// fields returns the snapshot's fields in response order, unset ones nil
+func (u userSnapshot) fields() []models.FieldChange {
+	return []models.FieldChange{
+		{Field: "name", To: nonEmpty(u.Name)},
+		{Field: "dob", To: nonEmpty(u.DOB)},
+		{Field: "timezone", To: deref(u.Timezone)},
+		{Field: "deleted_at", To: deref(u.DeletedAt)},
+		{Field: "merged_into", To: deref(u.MergedInto)},
+	}
+}
+
+// diffSnapshots lists the fields that differ between before and after
+func diffSnapshots(before, after userSnapshot) []models.FieldChange {
+	from, to := before.fields(), after.fields()
+	changes := []models.FieldChange{}
+	for i := range to {
+		if from[i].To != to[i].To {
+			changes = append(changes, models.FieldChange{Field: to[i].Field, From: from[i].To, To: to[i].To})
+		}
+	}
+	return changes
+}
+
+// recordChange appends the change of a user from before, nil for a new
+// user, to after to its history and enqueues the event announcing it. repo
+// must be the transaction that made the change.
+func (s *userService) recordChange(ctx context.Context, repo repository.UserRepository, action string, before, after *sqlc.User) error {
+	var from userSnapshot
+	if before != nil {
+		from = snapshotOf(before)
+	}
+	to := snapshotOf(after)
+	diff := diffSnapshots(from, to)
+
+	changes, err := json.Marshal(diff)
+	if err != nil {
+		return err
+	}
+	snapshot, err := json.Marshal(to)
+	if err != nil {
+		return err
+	}
+
+	actor := actorFrom(ctx)
+	err = repo.RecordHistory(ctx, sqlc.CreateUserHistoryParams{
+		UserID:    after.ID,
+		Action:    action,
+		Version:   after.Version,
+		Actor:     actor.Name,
+		RequestID: actor.RequestID,
+		Changes:   changes,
+		Snapshot:  snapshot,
+	})
+	if err != nil {
+		return err
+	}
+	return enqueueEvent(ctx, repo, action, after.ID, after.Version, to, diff)
+}
+
+// UserHistory returns a page of the changes made to a user, newest first.
+// History outlives the user, so purged users still have one.
+func (s *userService) UserHistory(ctx context.Context, id int32, query models.HistoryQuery) (*models.HistoryPage, error) {
+	limit := query.Limit
+	if limit < 1 || limit > 100 {
+		limit = defaultHistoryLimit
+	}
+
+	var before *int32
+	if query.Cursor != "" {
+		cursor, err := s.cursors.Decode(query.Cursor)
+		if err != nil || cursor.Sort != historySort || cursor.ID != id || len(cursor.Values) != 1 {
+			return nil, invalidCursor()
+		}
+		version, err := strconv.ParseInt(cursor.Values[0], 10, 32)
+		if err != nil {
+			return nil, invalidCursor()
+		}
+		v := int32(version)
+		before = &v
+	}
+
+	// Fetch one extra entry to learn whether another page exists
+	entries, err := s.repo.ListUserHistory(ctx, id, before, int32(limit+1))
+	if err != nil {
+		s.logger.Error("Failed to list user history", zap.Error(err), zap.Int32("user_id", id))
+		return nil, storeError(err, "failed to list user history")
+	}
+	if len(entries) == 0 && before == nil {
+		return nil, userNotFound(id)
+	}
+
+	page := &models.HistoryPage{Entries: make([]models.UserHistoryEntry, 0, len(entries))}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		page.NextCursor = s.cursors.Encode(pagination.Cursor{
+			Sort:   historySort,
+			Values: []string{strconv.Itoa(int(entries[limit-1].Version))},
+			ID:     id,
+		})
+	}
+	for i := range entries {
+		entry, err := toHistoryEntry(&entries[i])
+		if err != nil {
+			s.logger.Error("Corrupt user history", zap.Error(err), zap.Int64("history_id", entries[i].ID))
+			return nil, err
+		}
+		page.Entries = append(page.Entries, entry)
+	}
+	return page, nil
+}
+
+// GetUserAsOf returns a user as it was at asOf, with ages computed at that
+// time. Users deleted by then are only returned with includeDeleted.
+func (s *userService) GetUserAsOf(ctx context.Context, id int32, asOf time.Time, includeDeleted bool, fields models.UserFields) (*models.UserResponse, error) {
+	entry, err := s.repo.GetUserHistoryAt(ctx, id, asOf)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.NotFound("user %d has no history at %s", id, asOf.UTC().Format(time.RFC3339))
+		}
+		s.logger.Error("Failed to get user history", zap.Error(err), zap.Int32("user_id", id))
+		return nil, storeError(err, "failed to get user history")
+	}
+
+	var snapshot userSnapshot
+	if err := json.Unmarshal(entry.Snapshot, &snapshot); err != nil {
+		s.logger.Error("Corrupt user history", zap.Error(err), zap.Int64("history_id", entry.ID))
+		return nil, err
+	}
+	if snapshot.DeletedAt != nil && !includeDeleted {
+		return nil, apperrors.NotFound("user %d was deleted at %s", id, asOf.UTC().Format(time.RFC3339))
+	}
+
+	then := *s
+	then.clock = clock.Fixed(asOf)
+	response := then.userResponse(ctx, snapshot.user(id, entry.Version), fields)
+	return &response, nil
+}
+
+func toHistoryEntry(entry *sqlc.UserHistory) (models.UserHistoryEntry, error) {
+	var changes []models.FieldChange
+	if err := json.Unmarshal(entry.Changes, &changes); err != nil {
+		return models.UserHistoryEntry{}, err
+	}
+	return models.UserHistoryEntry{
+		Version:   entry.Version,
+		Action:    entry.Action,
+		Actor:     entry.Actor,
+		RequestID: entry.RequestID,
+		Changes:   changes,
+		ChangedAt: entry.ChangedAt.UTC().Format(time.RFC3339Nano),
+	}, nil
+}
+
+func nonEmpty(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func deref[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
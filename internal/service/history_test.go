@@ -0,0 +1,229 @@
+// internal/service/history_test.go
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"go.uber.org/zap"
+)
+
+// historyRepo reads back the history recorded in batchRepo, each version
+// changed an hour after the one before it
+type historyRepo struct {
+	*batchRepo
+	start time.Time
+}
+
+func (r *historyRepo) entries(userID int32) []sqlc.UserHistory {
+	var entries []sqlc.UserHistory
+	for i, h := range r.history {
+		if h.UserID == userID {
+			entries = append(entries, sqlc.UserHistory{
+				ID: int64(i + 1), UserID: h.UserID, Action: h.Action, Version: h.Version, Actor: h.Actor,
+				RequestID: h.RequestID, Changes: h.Changes, Snapshot: h.Snapshot,
+				ChangedAt: r.start.Add(time.Duration(h.Version) * time.Hour),
+			})
+		}
+	}
+	return entries
+}
+
+func (r *historyRepo) ListUserHistory(_ context.Context, userID int32, beforeVersion *int32, limit int32) ([]sqlc.UserHistory, error) {
+	entries := r.entries(userID)
+	items := []sqlc.UserHistory{}
+	for i := len(entries) - 1; i >= 0 && len(items) < int(limit); i-- {
+		if beforeVersion == nil || entries[i].Version < *beforeVersion {
+			items = append(items, entries[i])
+		}
+	}
+	return items, nil
+}
+
+func (r *historyRepo) GetUserHistoryAt(_ context.Context, userID int32, asOf time.Time) (*sqlc.UserHistory, error) {
+	entries := r.entries(userID)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if !entries[i].ChangedAt.After(asOf) {
+			return &entries[i], nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func TestUserHistory_RecordsChanges(t *testing.T) {
+	repo := newBatchRepo()
+	s := NewUserService(repo, zap.NewNop(), Config{})
+	ctx := WithActor(context.Background(), Actor{Name: "admin", RequestID: "req-1"})
+
+	created, err := s.CreateUser(ctx, models.CreateUserRequest{Name: "Alice", DOB: "1990-05-10"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if _, err := s.UpdateUser(ctx, created.ID, models.UpdateUserRequest{Name: "Alicia", DOB: "1990-05-10"}, nil); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if err := s.DeleteUser(ctx, created.ID, nil); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+
+	want := []struct {
+		action  string
+		version int32
+		fields  []string
+	}{
+		{historyCreate, 1, []string{"name", "dob"}},
+		{historyUpdate, 2, []string{"name"}},
+		{historyDelete, 3, []string{"deleted_at"}},
+	}
+	if len(repo.history) != len(want) {
+		t.Fatalf("recorded %d changes, want %d", len(repo.history), len(want))
+	}
+	for i, w := range want {
+		entry := repo.history[i]
+		if entry.Action != w.action || entry.Version != w.version {
+			t.Errorf("change %d = %s v%d, want %s v%d", i, entry.Action, entry.Version, w.action, w.version)
+		}
+		if entry.Actor != "admin" || entry.RequestID != "req-1" {
+			t.Errorf("change %d actor = %q, %q, want admin, req-1", i, entry.Actor, entry.RequestID)
+		}
+		var changes []models.FieldChange
+		if err := json.Unmarshal(entry.Changes, &changes); err != nil {
+			t.Fatalf("change %d: %v", i, err)
+		}
+		var fields []string
+		for _, c := range changes {
+			fields = append(fields, c.Field)
+		}
+		if len(fields) != len(w.fields) || (len(fields) > 0 && fields[0] != w.fields[0]) {
+			t.Errorf("change %d fields = %v, want %v", i, fields, w.fields)
+		}
+	}
+
+	var update []models.FieldChange
+	_ = json.Unmarshal(repo.history[1].Changes, &update)
+	if update[0].From != "Alice" || update[0].To != "Alicia" {
+		t.Errorf("update = %+v, want name Alice -> Alicia", update[0])
+	}
+}
+
+func TestUserHistory_FailedChangeRecordsNothing(t *testing.T) {
+	repo := newBatchRepo(sqlc.User{ID: 1, Name: "Alice", Dob: time.Date(1990, 5, 10, 0, 0, 0, 0, time.UTC), Version: 2})
+	s := NewUserService(repo, zap.NewNop(), Config{})
+
+	stale := int32(1)
+	_, err := s.UpdateUser(context.Background(), 1, models.UpdateUserRequest{Name: "Alicia", DOB: "1990-05-10"}, &stale)
+	if !errors.Is(err, apperrors.ErrPreconditionFailed) {
+		t.Fatalf("UpdateUser() error = %v, want precondition failed", err)
+	}
+	if len(repo.history) != 0 {
+		t.Errorf("recorded %d changes, want none", len(repo.history))
+	}
+}
+
+func TestUserHistory_Pagination(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &historyRepo{batchRepo: newBatchRepo(), start: start}
+	s := NewUserService(repo, zap.NewNop(), Config{})
+	ctx := context.Background()
+
+	user, err := s.CreateUser(ctx, models.CreateUserRequest{Name: "Alice", DOB: "1990-05-10"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	for _, name := range []string{"Alicia", "Ali", "Alice"} {
+		if _, err := s.UpdateUser(ctx, user.ID, models.UpdateUserRequest{Name: name, DOB: "1990-05-10"}, nil); err != nil {
+			t.Fatalf("UpdateUser() error = %v", err)
+		}
+	}
+
+	var versions []int32
+	query := models.HistoryQuery{Limit: 3}
+	for pages := 0; ; pages++ {
+		if pages > 2 {
+			t.Fatal("pagination did not end")
+		}
+		page, err := s.UserHistory(ctx, user.ID, query)
+		if err != nil {
+			t.Fatalf("UserHistory() error = %v", err)
+		}
+		for _, e := range page.Entries {
+			versions = append(versions, e.Version)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	if want := []int32{4, 3, 2, 1}; len(versions) != len(want) || versions[0] != 4 || versions[3] != 1 {
+		t.Errorf("versions = %v, want %v", versions, want)
+	}
+
+	if _, err := s.UserHistory(ctx, 42, models.HistoryQuery{}); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Errorf("UserHistory(unknown) error = %v, want not found", err)
+	}
+	if _, err := s.UserHistory(ctx, 42, models.HistoryQuery{Cursor: query.Cursor}); !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("UserHistory(another user's cursor) error = %v, want validation error", err)
+	}
+}
+
+func TestGetUserAsOf(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &historyRepo{batchRepo: newBatchRepo(), start: start}
+	s := NewUserService(repo, zap.NewNop(), Config{})
+	ctx := context.Background()
+
+	user, err := s.CreateUser(ctx, models.CreateUserRequest{Name: "Alice", DOB: "1990-05-10"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if _, err := s.UpdateUser(ctx, user.ID, models.UpdateUserRequest{Name: "Alicia", DOB: "1990-05-10"}, nil); err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if err := s.DeleteUser(ctx, user.ID, nil); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+
+	// Version n was changed n hours after start
+	tests := []struct {
+		name           string
+		asOf           time.Time
+		includeDeleted bool
+		wantName       string
+		wantErr        error
+	}{
+		{name: "Before creation", asOf: start, wantErr: apperrors.ErrNotFound},
+		{name: "After creation", asOf: start.Add(90 * time.Minute), wantName: "Alice"},
+		{name: "After update", asOf: start.Add(2 * time.Hour), wantName: "Alicia"},
+		{name: "After delete", asOf: start.Add(3 * time.Hour), wantErr: apperrors.ErrNotFound},
+		{name: "After delete with deleted", asOf: start.Add(3 * time.Hour), includeDeleted: true, wantName: "Alicia"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.GetUserAsOf(ctx, user.ID, tt.asOf, tt.includeDeleted, 0)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetUserAsOf() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetUserAsOf() error = %v", err)
+			}
+			if got.Name != tt.wantName {
+				t.Errorf("Name = %q, want %q", got.Name, tt.wantName)
+			}
+			// Ages are computed at asOf
+			if got.Age == nil || *got.Age != 33 {
+				t.Errorf("Age = %v, want 33", got.Age)
+			}
+		})
+	}
+}
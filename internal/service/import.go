@@ -0,0 +1,252 @@
+// internal/service/import.go
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/importer"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultImportChunkSize = 1000
+
+	// maxImportErrors bounds the row errors kept in a report
+	maxImportErrors = 1000
+)
+
+// importRow is a valid row waiting to be inserted
+type importRow struct {
+	line   int
+	key    string
+	params sqlc.CreateUserParams
+}
+
+// importRun holds the state of one import
+type importRun struct {
+	s      *userService
+	opts   models.ImportOptions
+	report *models.ImportReport
+	seen   map[string]int
+	chunk  []importRow
+	// committed is set once a chunk is committed, after which an error
+	// still returns the report of what was imported
+	committed bool
+}
+
+// ImportUsers validates every row of reader like CreateUser and inserts the
+// valid ones in chunks. Chunks are committed as they fill up, so an error
+// aborts the import without undoing the chunks already imported; the report
+// of those is returned along with the error.
+func (s *userService) ImportUsers(ctx context.Context, reader importer.Reader, opts models.ImportOptions) (*models.ImportReport, error) {
+	if opts.ChunkSize < 1 {
+		opts.ChunkSize = defaultImportChunkSize
+	}
+	run := &importRun{
+		s:      s,
+		opts:   opts,
+		report: &models.ImportReport{DryRun: opts.DryRun, Errors: []models.ImportRowError{}},
+		seen:   make(map[string]int),
+		chunk:  make([]importRow, 0, opts.ChunkSize),
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return run.abort(err)
+		}
+
+		row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return run.abort(&apperrors.Error{Kind: apperrors.ErrValidation, Message: "Invalid import file", Err: err})
+		}
+
+		if err := run.add(ctx, row); err != nil {
+			return run.abort(err)
+		}
+	}
+	if err := run.flush(ctx); err != nil {
+		return run.abort(err)
+	}
+
+	s.logger.Info("Users imported",
+		zap.Bool("dry_run", opts.DryRun),
+		zap.Int("total", run.report.Total),
+		zap.Int64("imported", run.report.Imported),
+		zap.Int("skipped", run.report.Skipped),
+		zap.Int("failed", run.report.Failed),
+	)
+	return run.report, nil
+}
+
+// abort ends the import with err, returning the report too once a chunk has
+// been committed
+func (r *importRun) abort(err error) (*models.ImportReport, error) {
+	if !r.committed {
+		return nil, err
+	}
+	r.s.logger.Warn("Import aborted",
+		zap.Error(err),
+		zap.Int("total", r.report.Total),
+		zap.Int64("imported", r.report.Imported),
+	)
+	return r.report, err
+}
+
+func (r *importRun) add(ctx context.Context, row importer.Row) error {
+	r.report.Total++
+
+	if row.Err != nil {
+		r.fail(row.Line, "Malformed record: "+row.Err.Error(), nil)
+		return nil
+	}
+	if err := row.User.Validate(); err != nil {
+		var appErr *apperrors.Error
+		if errors.As(err, &appErr) {
+			r.fail(row.Line, appErr.Message, appErr.Fields)
+			return nil
+		}
+		return err
+	}
+
+	dob, err := time.Parse("2006-01-02", row.User.DOB)
+	if err != nil {
+		r.fail(row.Line, "Invalid date format. Use YYYY-MM-DD", nil)
+		return nil
+	}
+
+	key := dedupKey(r.opts.Dedup, row.User.Name, row.User.DOB)
+	if key != "" {
+		if first, ok := r.seen[key]; ok {
+			r.skip(row.Line, fmt.Sprintf("Duplicate of line %d", first))
+			return nil
+		}
+		r.seen[key] = row.Line
+	}
+
+	r.chunk = append(r.chunk, importRow{
+		line:   row.Line,
+		key:    key,
+		params: sqlc.CreateUserParams{Name: row.User.Name, Dob: dob, Timezone: nullString(row.User.Timezone)},
+	})
+	if len(r.chunk) >= r.opts.ChunkSize {
+		return r.flush(ctx)
+	}
+	return nil
+}
+
+// flush drops rows matching existing users when deduplicating and inserts
+// the rest of the chunk
+func (r *importRun) flush(ctx context.Context) error {
+	if len(r.chunk) == 0 {
+		return nil
+	}
+	defer func() { r.chunk = r.chunk[:0] }()
+
+	rows := r.chunk
+	if r.opts.Dedup != models.DedupNone {
+		var err error
+		if rows, err = r.withoutExisting(ctx, rows); err != nil {
+			return err
+		}
+	}
+
+	if r.opts.DryRun {
+		r.report.Imported += int64(len(rows))
+		return nil
+	}
+
+	params := make([]sqlc.CreateUserParams, 0, len(rows))
+	for _, row := range rows {
+		params = append(params, row.params)
+	}
+	var ids []int32
+	err := r.s.repo.InTx(ctx, func(repo repository.UserRepository) error {
+		var err error
+		if ids, err = repo.CopyUsers(ctx, params); err != nil {
+			return err
+		}
+		actor := actorFrom(ctx)
+		if _, err = repo.RecordInsertedUsersHistory(ctx, ids, actor.Name, actor.RequestID); err != nil {
+			return err
+		}
+		_, err = repo.EnqueueInsertedUsersEvents(ctx, ids)
+		return err
+	})
+	if err != nil {
+		r.s.logger.Error("Failed to import users", zap.Error(err), zap.Int64("imported", r.report.Imported))
+		return storeError(err, "failed to import users")
+	}
+	r.report.Imported += int64(len(ids))
+	r.committed = true
+	return nil
+}
+
+func (r *importRun) withoutExisting(ctx context.Context, rows []importRow) ([]importRow, error) {
+	names := make([]string, 0, len(rows))
+	for _, row := range rows {
+		names = append(names, strings.ToLower(row.params.Name))
+	}
+
+	existing, err := r.s.repo.FindUsersByNames(ctx, names)
+	if err != nil {
+		r.s.logger.Error("Failed to look up existing users", zap.Error(err))
+		return nil, storeError(err, "failed to look up existing users")
+	}
+	ids := make(map[string]int32, len(existing))
+	for _, u := range existing {
+		ids[dedupKey(r.opts.Dedup, u.NameKey, u.Dob)] = u.ID
+	}
+
+	kept := rows[:0:0]
+	for _, row := range rows {
+		if id, ok := ids[row.key]; ok {
+			r.skip(row.line, fmt.Sprintf("User %d already exists", id))
+			continue
+		}
+		kept = append(kept, row)
+	}
+	return kept, nil
+}
+
+func (r *importRun) fail(line int, message string, fields []apperrors.FieldError) {
+	r.report.Failed++
+	r.record(models.ImportRowError{Line: line, Message: message, Fields: fields})
+}
+
+func (r *importRun) skip(line int, message string) {
+	r.report.Skipped++
+	r.record(models.ImportRowError{Line: line, Message: message})
+}
+
+func (r *importRun) record(e models.ImportRowError) {
+	if len(r.report.Errors) >= maxImportErrors {
+		r.report.Truncated = true
+		return
+	}
+	r.report.Errors = append(r.report.Errors, e)
+}
+
+// dedupKey identifies a user for deduplication, ignoring the case of the
+// name. It is empty when not deduplicating.
+func dedupKey(key models.DedupKey, name, dob string) string {
+	switch key {
+	case models.DedupName:
+		return strings.ToLower(name)
+	case models.DedupNameDOB:
+		return strings.ToLower(name) + "\x00" + dob
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,138 @@
+// internal/service/import_test.go
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/importer"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+type importRepo struct {
+	repository.UserRepository
+	existing []repository.ExistingUser
+	chunks   [][]sqlc.CreateUserParams
+	// failChunk makes inserting that chunk, counting from 1, fail
+	failChunk int
+}
+
+func (r *importRepo) FindUsersByNames(_ context.Context, names []string) ([]repository.ExistingUser, error) {
+	var found []repository.ExistingUser
+	for _, u := range r.existing {
+		for _, name := range names {
+			if u.NameKey == name {
+				found = append(found, u)
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+func (r *importRepo) CopyUsers(_ context.Context, users []sqlc.CreateUserParams) ([]int32, error) {
+	if len(r.chunks)+1 == r.failChunk {
+		return nil, errors.New("connection reset")
+	}
+	r.chunks = append(r.chunks, append([]sqlc.CreateUserParams(nil), users...))
+	return make([]int32, len(users)), nil
+}
+
+func (r *importRepo) InTx(_ context.Context, fn func(repo repository.UserRepository) error) error {
+	return fn(r)
+}
+
+func (r *importRepo) RecordInsertedUsersHistory(context.Context, []int32, string, string) (int64, error) {
+	return 0, nil
+}
+
+func (r *importRepo) EnqueueInsertedUsersEvents(context.Context, []int32) (int64, error) {
+	return 0, nil
+}
+
+const importCSV = `name,dob
+Alice,1990-05-10
+alice,1990-05-10
+Bob,not-a-date
+,1985-01-02
+Carol,1970-01-01
+Dave,1980-02-03
+`
+
+func TestImportUsers(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         models.ImportOptions
+		wantImported int64
+		wantSkipped  int
+		wantChunks   int
+	}{
+		{name: "No dedup", opts: models.ImportOptions{ChunkSize: 2}, wantImported: 4, wantChunks: 2},
+		{name: "Dedup by name and dob", opts: models.ImportOptions{Dedup: models.DedupNameDOB, ChunkSize: 2}, wantImported: 2, wantSkipped: 2, wantChunks: 2},
+		{name: "Dry run", opts: models.ImportOptions{DryRun: true}, wantImported: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &importRepo{existing: []repository.ExistingUser{{ID: 7, NameKey: "carol", Dob: "1970-01-01"}}}
+			s := NewUserService(repo, zap.NewNop(), Config{})
+
+			reader, err := importer.NewReader(strings.NewReader(importCSV), importer.CSV)
+			if err != nil {
+				t.Fatalf("NewReader() error = %v", err)
+			}
+			report, err := s.ImportUsers(context.Background(), reader, tt.opts)
+			if err != nil {
+				t.Fatalf("ImportUsers() error = %v", err)
+			}
+
+			if report.Total != 6 || report.Failed != 2 {
+				t.Errorf("total = %d, failed = %d; want 6, 2", report.Total, report.Failed)
+			}
+			if report.Imported != tt.wantImported || report.Skipped != tt.wantSkipped {
+				t.Errorf("imported = %d, skipped = %d; want %d, %d", report.Imported, report.Skipped, tt.wantImported, tt.wantSkipped)
+			}
+			if len(repo.chunks) != tt.wantChunks {
+				t.Errorf("copied %d chunks, want %d", len(repo.chunks), tt.wantChunks)
+			}
+			if len(report.Errors) != report.Failed+report.Skipped {
+				t.Errorf("report has %d errors, want %d", len(report.Errors), report.Failed+report.Skipped)
+			}
+			if report.Errors[0].Line == 0 {
+				t.Error("row errors should carry their line number")
+			}
+		})
+	}
+}
+
+func TestImportUsers_ReportsChunksImportedBeforeFailure(t *testing.T) {
+	repo := &importRepo{failChunk: 2}
+	s := NewUserService(repo, zap.NewNop(), Config{})
+	reader, err := importer.NewReader(strings.NewReader(importCSV), importer.CSV)
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	report, err := s.ImportUsers(context.Background(), reader, models.ImportOptions{ChunkSize: 2})
+	if err == nil {
+		t.Fatal("ImportUsers() succeeded, want the second chunk's error")
+	}
+	if report == nil {
+		t.Fatal("ImportUsers() returned no report after committing a chunk")
+	}
+	if report.Imported != 2 || report.Failed != 2 {
+		t.Errorf("imported = %d, failed = %d; want 2, 2", report.Imported, report.Failed)
+	}
+
+	repo = &importRepo{failChunk: 1}
+	s = NewUserService(repo, zap.NewNop(), Config{})
+	reader, _ = importer.NewReader(strings.NewReader(importCSV), importer.CSV)
+	if report, err := s.ImportUsers(context.Background(), reader, models.ImportOptions{ChunkSize: 2}); err == nil || report != nil {
+		t.Errorf("ImportUsers() failing the first chunk = %v, %v; want no report and an error", report, err)
+	}
+}
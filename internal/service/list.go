@@ -0,0 +1,148 @@
+// internal/service/list.go
+package service
+
+import (
+	"strings"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/repository"
+)
+
+// listOrder is a parsed ?sort= value
+type listOrder struct {
+	fields []repository.SortField
+	idDesc bool
+	// canonical identifies the order inside cursors
+	canonical string
+}
+
+// parseSort parses a comma separated list of columns, each optionally
+// prefixed with "-" for descending order. Since id is unique, anything
+// after an id term cannot affect the order and is ignored.
+func parseSort(sort string) (listOrder, error) {
+	var order listOrder
+	if strings.TrimSpace(sort) == "" {
+		return order, nil
+	}
+
+	seen := make(map[string]bool)
+	var terms []string
+	for _, term := range strings.Split(sort, ",") {
+		term = strings.TrimSpace(term)
+		column, desc := strings.CutPrefix(term, "-")
+
+		if column != "id" && !repository.ValidSortColumn(column) {
+			return order, invalidSort("sort must list columns from: id, name, dob, created_at, updated_at")
+		}
+		if seen[column] {
+			return order, invalidSort("sort must not repeat " + column)
+		}
+		seen[column] = true
+		terms = append(terms, term)
+
+		if column == "id" {
+			order.idDesc = desc
+			break
+		}
+		order.fields = append(order.fields, repository.SortField{Column: column, Desc: desc})
+	}
+
+	order.canonical = strings.Join(terms, ",")
+	return order, nil
+}
+
+func invalidSort(message string) error {
+	return apperrors.Validation("Invalid sort", apperrors.FieldError{
+		Field:   "sort",
+		Rule:    "sort",
+		Message: message,
+	})
+}
+
+// toRepositoryFilter converts validated query parameters into a filter.
+// Age bounds become dob bounds relative to today so the dob index is used.
+func toRepositoryFilter(f models.UserFilter, includeDeleted bool, today time.Time) (repository.UserFilter, error) {
+	filter := repository.UserFilter{
+		Name:           f.Name,
+		NameMatch:      repository.NameMatch(f.NameMatch),
+		IncludeDeleted: includeDeleted,
+	}
+
+	var err error
+	if filter.DobFrom, err = parseOptional("2006-01-02", f.DOBFrom); err != nil {
+		return filter, err
+	}
+	if filter.DobTo, err = parseOptional("2006-01-02", f.DOBTo); err != nil {
+		return filter, err
+	}
+	if filter.CreatedFrom, err = parseOptional(time.RFC3339, f.CreatedFrom); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo, err = parseOptional(time.RFC3339, f.CreatedTo); err != nil {
+		return filter, err
+	}
+	if filter.UpdatedFrom, err = parseOptional(time.RFC3339, f.UpdatedFrom); err != nil {
+		return filter, err
+	}
+	if filter.UpdatedTo, err = parseOptional(time.RFC3339, f.UpdatedTo); err != nil {
+		return filter, err
+	}
+
+	if f.AgeMin != nil && f.AgeMax != nil && *f.AgeMin > *f.AgeMax {
+		return filter, apperrors.Validation("Invalid age range", apperrors.FieldError{
+			Field:   "age_max",
+			Rule:    "gtefield",
+			Message: "age_max must be greater than or equal to age_min",
+		})
+	}
+
+	// age >= n exactly when dob is on or before today n years ago
+	if f.AgeMin != nil {
+		filter.DobTo = earliest(filter.DobTo, yearsBefore(today, *f.AgeMin))
+	}
+	// age <= n exactly when dob is after today n+1 years ago
+	if f.AgeMax != nil {
+		filter.DobFrom = latest(filter.DobFrom, yearsBefore(today, *f.AgeMax+1).AddDate(0, 0, 1))
+	}
+
+	return filter, nil
+}
+
+func parseOptional(layout, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		// Already checked by UserFilter.Validate
+		return nil, apperrors.Validation("Invalid filter value " + value)
+	}
+	return &t, nil
+}
+
+// yearsBefore returns the calendar date n years before t. Feb 29 maps to
+// Feb 28 in non-leap years, matching models.CalculateAge, which only counts
+// a Feb 29 birthday once March begins.
+func yearsBefore(t time.Time, n int) time.Time {
+	d := time.Date(t.Year()-n, t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if d.Month() != t.Month() {
+		d = d.AddDate(0, 0, -d.Day())
+	}
+	return d
+}
+
+func earliest(current *time.Time, t time.Time) *time.Time {
+	if current != nil && current.Before(t) {
+		return current
+	}
+	return &t
+}
+
+func latest(current *time.Time, t time.Time) *time.Time {
+	if current != nil && current.After(t) {
+		return current
+	}
+	return &t
+}
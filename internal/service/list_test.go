@@ -0,0 +1,110 @@
+// internal/service/list_test.go
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/repository"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name      string
+		sort      string
+		want      []repository.SortField
+		idDesc    bool
+		canonical string
+		wantErr   bool
+	}{
+		{name: "Empty", sort: ""},
+		{
+			name:      "Mixed directions",
+			sort:      "name, -dob",
+			want:      []repository.SortField{{Column: "name"}, {Column: "dob", Desc: true}},
+			canonical: "name,-dob",
+		},
+		{
+			name:      "Descending id ends the sort",
+			sort:      "created_at,-id,name",
+			want:      []repository.SortField{{Column: "created_at"}},
+			idDesc:    true,
+			canonical: "created_at,-id",
+		},
+		{name: "Unknown column", sort: "password", wantErr: true},
+		{name: "Repeated column", sort: "name,-name", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSort(tt.sort)
+			if tt.wantErr {
+				if !errors.Is(err, apperrors.ErrValidation) {
+					t.Fatalf("parseSort() error = %v, want validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSort() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.fields, tt.want) || got.idDesc != tt.idDesc || got.canonical != tt.canonical {
+				t.Errorf("parseSort() = %+v, want fields %+v idDesc %v canonical %q", got, tt.want, tt.idDesc, tt.canonical)
+			}
+		})
+	}
+}
+
+func TestToRepositoryFilter_AgeRange(t *testing.T) {
+	ageMin, ageMax := 18, 25
+	today := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+
+	got, err := toRepositoryFilter(models.UserFilter{AgeMin: &ageMin, AgeMax: &ageMax}, false, today)
+	if err != nil {
+		t.Fatalf("toRepositoryFilter() error = %v", err)
+	}
+
+	// 18 on 2024-02-29 means born on or before 2006-02-28; 25 or younger
+	// means born after 1998-02-28
+	if got.DobTo == nil || got.DobTo.Format("2006-01-02") != "2006-02-28" {
+		t.Errorf("DobTo = %v, want 2006-02-28", got.DobTo)
+	}
+	if got.DobFrom == nil || got.DobFrom.Format("2006-01-02") != "1998-03-01" {
+		t.Errorf("DobFrom = %v, want 1998-03-01", got.DobFrom)
+	}
+
+	for _, dob := range []time.Time{*got.DobFrom, *got.DobTo} {
+		// Both bounds must be consistent with CalculateAge on that day
+		age := today.Year() - dob.Year()
+		if today.Month() < dob.Month() || (today.Month() == dob.Month() && today.Day() < dob.Day()) {
+			age--
+		}
+		if age < ageMin || age > ageMax {
+			t.Errorf("dob %s gives age %d, outside %d-%d", dob.Format("2006-01-02"), age, ageMin, ageMax)
+		}
+	}
+}
+
+func TestToRepositoryFilter_NarrowsExplicitDobRange(t *testing.T) {
+	ageMin := 30
+	today := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	got, err := toRepositoryFilter(models.UserFilter{DOBTo: "1980-01-01", AgeMin: &ageMin}, false, today)
+	if err != nil {
+		t.Fatalf("toRepositoryFilter() error = %v", err)
+	}
+	if got.DobTo.Format("2006-01-02") != "1980-01-01" {
+		t.Errorf("DobTo = %s, want the stricter 1980-01-01", got.DobTo.Format("2006-01-02"))
+	}
+}
+
+func TestToRepositoryFilter_InvertedAgeRange(t *testing.T) {
+	ageMin, ageMax := 30, 20
+	_, err := toRepositoryFilter(models.UserFilter{AgeMin: &ageMin, AgeMax: &ageMax}, false, time.Now())
+	if !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("toRepositoryFilter() error = %v, want validation error", err)
+	}
+}
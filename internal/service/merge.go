@@ -0,0 +1,154 @@
+// internal/service/merge.go
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+// MergeUsers merges the user req.SourceID into the user id in one
+// transaction. The survivor takes the fields req picks and the source is
+// soft-deleted as a tombstone pointing at it, so reads of the old id are
+// redirected. expectedVersion guards the survivor like UpdateUser.
+func (s *userService) MergeUsers(ctx context.Context, id int32, req models.MergeUsersRequest, expectedVersion *int32) (*models.UserResponse, error) {
+	if req.SourceID == id {
+		return nil, apperrors.Validation("Validation failed", apperrors.FieldError{
+			Field:   "source_id",
+			Rule:    "nefield",
+			Message: "source_id must not be the id of the surviving user",
+		})
+	}
+	if req.Strategy == "" {
+		req.Strategy = models.MergeKeepTarget
+	}
+
+	var merged *sqlc.User
+	err := s.repo.InTx(ctx, func(repo repository.UserRepository) error {
+		tx := s.withRepo(repo)
+
+		target, err := repo.GetUserByID(ctx, id, false)
+		if errors.Is(err, sql.ErrNoRows) {
+			return tx.missingUser(ctx, id)
+		}
+		if err != nil {
+			return err
+		}
+		if expectedVersion != nil && *expectedVersion != target.Version {
+			return versionMismatch(id, target.Version)
+		}
+
+		source, err := repo.GetUserByID(ctx, req.SourceID, false)
+		if errors.Is(err, sql.ErrNoRows) {
+			if into, err := repo.GetMergedInto(ctx, req.SourceID); err == nil {
+				return apperrors.Conflict("user %d was already merged into user %d", req.SourceID, into).With("merged_into", into)
+			}
+			return apperrors.NotFound("source user %d not found", req.SourceID)
+		}
+		if err != nil {
+			return err
+		}
+
+		name, dob, timezone := resolveMerge(target, source, req)
+		merged, err = repo.UpdateUser(ctx, id, name, dob, timezone, &target.Version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return tx.missingOrStale(ctx, id, &target.Version)
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.recordChange(ctx, repo, historyMerge, target, merged); err != nil {
+			return err
+		}
+
+		repointed, err := repo.MergeUser(ctx, source.ID, id, source.Version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return apperrors.Conflict("user %d changed while being merged, retry the merge", source.ID)
+		}
+		if err != nil {
+			return err
+		}
+		tombstone, err := repo.GetUserByID(ctx, source.ID, true)
+		if err != nil {
+			return err
+		}
+		if err := s.recordChange(ctx, repo, historyMerged, source, tombstone); err != nil {
+			return err
+		}
+
+		// Users merged into the source before now point at the survivor.
+		// Repointing changed nothing else about them but their version.
+		for i := range repointed {
+			after := &repointed[i]
+			before := *after
+			before.MergedInto = sql.NullInt32{Int32: source.ID, Valid: true}
+			before.Version--
+			if err := s.recordChange(ctx, repo, historyMerged, &before, after); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		var appErr *apperrors.Error
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		s.logger.Error("Failed to merge users", zap.Error(err), zap.Int32("user_id", id), zap.Int32("source_id", req.SourceID))
+		return nil, storeError(err, "failed to merge users")
+	}
+
+	actor := actorFrom(ctx)
+	s.logger.Info("Users merged",
+		zap.Int32("user_id", id),
+		zap.Int32("source_id", req.SourceID),
+		zap.String("strategy", string(req.Strategy)),
+		zap.String("actor", actor.Name),
+		zap.String("request_id", actor.RequestID),
+	)
+
+	response := toUserResponse(merged)
+	return &response, nil
+}
+
+// resolveMerge returns the name, dob and timezone the survivor of merging
+// source into target keeps
+func resolveMerge(target, source *sqlc.User, req models.MergeUsersRequest) (string, time.Time, *string) {
+	preferred, other := target, source
+	switch req.Strategy {
+	case models.MergeKeepSource:
+		preferred, other = source, target
+	case models.MergeNewest:
+		if source.UpdatedAt.Time.After(target.UpdatedAt.Time) {
+			preferred, other = source, target
+		}
+	}
+
+	pick := func(side models.MergeSide) *sqlc.User {
+		switch side {
+		case models.MergeTarget:
+			return target
+		case models.MergeSource:
+			return source
+		}
+		return preferred
+	}
+
+	timezone := pick(req.Fields.Timezone).Timezone
+	if req.Fields.Timezone == "" && !timezone.Valid {
+		timezone = other.Timezone
+	}
+
+	var tz *string
+	if timezone.Valid {
+		tz = &timezone.String
+	}
+	return pick(req.Fields.Name).Name, pick(req.Fields.DOB).Dob, tz
+}
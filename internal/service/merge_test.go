@@ -0,0 +1,137 @@
+// internal/service/merge_test.go
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestMergeUsers(t *testing.T) {
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	target := sqlc.User{
+		ID: 1, Name: "Alice", Dob: time.Date(1990, 5, 10, 0, 0, 0, 0, time.UTC), Version: 3,
+		UpdatedAt: sql.NullTime{Time: older, Valid: true},
+	}
+	source := sqlc.User{
+		ID: 2, Name: "Alice Smith", Dob: time.Date(1990, 5, 11, 0, 0, 0, 0, time.UTC), Version: 1,
+		UpdatedAt: sql.NullTime{Time: newer, Valid: true},
+		Timezone:  sql.NullString{String: "Asia/Kolkata", Valid: true},
+	}
+
+	tests := []struct {
+		name         string
+		req          models.MergeUsersRequest
+		wantName     string
+		wantDOB      string
+		wantTimezone string
+	}{
+		{
+			name:     "Keep target fills missing timezone",
+			req:      models.MergeUsersRequest{SourceID: 2},
+			wantName: "Alice", wantDOB: "1990-05-10", wantTimezone: "Asia/Kolkata",
+		},
+		{
+			name:     "Keep source",
+			req:      models.MergeUsersRequest{SourceID: 2, Strategy: models.MergeKeepSource},
+			wantName: "Alice Smith", wantDOB: "1990-05-11", wantTimezone: "Asia/Kolkata",
+		},
+		{
+			name:     "Newest",
+			req:      models.MergeUsersRequest{SourceID: 2, Strategy: models.MergeNewest},
+			wantName: "Alice Smith", wantDOB: "1990-05-11", wantTimezone: "Asia/Kolkata",
+		},
+		{
+			name: "Field overrides",
+			req: models.MergeUsersRequest{SourceID: 2, Fields: models.MergeFields{
+				DOB: models.MergeSource, Timezone: models.MergeTarget,
+			}},
+			wantName: "Alice", wantDOB: "1990-05-11",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newBatchRepo(target, source)
+			s := NewUserService(repo, zap.NewNop(), Config{})
+
+			user, err := s.MergeUsers(context.Background(), 1, tt.req, nil)
+			if err != nil {
+				t.Fatalf("MergeUsers() error = %v", err)
+			}
+			if user.Name != tt.wantName || user.DOB != tt.wantDOB {
+				t.Errorf("merged = %s %s, want %s %s", user.Name, user.DOB, tt.wantName, tt.wantDOB)
+			}
+			var timezone string
+			if user.Timezone != nil {
+				timezone = *user.Timezone
+			}
+			if timezone != tt.wantTimezone {
+				t.Errorf("Timezone = %q, want %q", timezone, tt.wantTimezone)
+			}
+			if into := repo.merged[2]; into != 1 {
+				t.Errorf("source merged into %d, want 1", into)
+			}
+		})
+	}
+}
+
+func TestMergeUsers_Tombstone(t *testing.T) {
+	dob := time.Date(1990, 5, 10, 0, 0, 0, 0, time.UTC)
+	repo := newBatchRepo(
+		sqlc.User{ID: 1, Name: "A", Dob: dob, Version: 1},
+		sqlc.User{ID: 2, Name: "B", Dob: dob, Version: 1},
+		sqlc.User{ID: 3, Name: "C", Dob: dob, Version: 1},
+	)
+	s := NewUserService(repo, zap.NewNop(), Config{})
+	ctx := context.Background()
+
+	if _, err := s.MergeUsers(ctx, 2, models.MergeUsersRequest{SourceID: 3}, nil); err != nil {
+		t.Fatalf("MergeUsers(3 into 2) error = %v", err)
+	}
+	if _, err := s.MergeUsers(ctx, 1, models.MergeUsersRequest{SourceID: 2}, nil); err != nil {
+		t.Fatalf("MergeUsers(2 into 1) error = %v", err)
+	}
+
+	// The repointed tombstone has the change in its history
+	last := repo.history[len(repo.history)-1]
+	if last.UserID != 3 || last.Action != historyMerged || last.Version != 3 {
+		t.Errorf("last history entry = user %d %s v%d, want user 3 merged v3", last.UserID, last.Action, last.Version)
+	}
+	if len(repo.events) != len(repo.history) {
+		t.Errorf("enqueued %d events for %d history entries", len(repo.events), len(repo.history))
+	}
+
+	// Both tombstones point straight at the last survivor
+	for _, id := range []int32{2, 3} {
+		_, err := s.GetUserByID(ctx, id, false, 0)
+		var appErr *apperrors.Error
+		if !errors.As(err, &appErr) || appErr.Kind != apperrors.ErrMoved || appErr.Details["merged_into"] != int32(1) {
+			t.Errorf("GetUserByID(%d) error = %v, want moved to 1", id, err)
+		}
+	}
+
+	_, err := s.MergeUsers(ctx, 1, models.MergeUsersRequest{SourceID: 3}, nil)
+	if !errors.Is(err, apperrors.ErrConflict) {
+		t.Errorf("merging a tombstone error = %v, want conflict", err)
+	}
+
+	stale := int32(1)
+	_, err = s.MergeUsers(ctx, 1, models.MergeUsersRequest{SourceID: 9}, &stale)
+	if !errors.Is(err, apperrors.ErrPreconditionFailed) {
+		t.Errorf("stale If-Match error = %v, want precondition failed", err)
+	}
+
+	_, err = s.MergeUsers(ctx, 1, models.MergeUsersRequest{SourceID: 1}, nil)
+	if !errors.Is(err, apperrors.ErrValidation) {
+		t.Errorf("self merge error = %v, want validation", err)
+	}
+}
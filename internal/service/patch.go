@@ -0,0 +1,54 @@
+// internal/service/patch.go
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+)
+
+// applyPatch applies req to the current editable fields of a user and
+// returns the validated result
+func applyPatch(current models.UpdateUserRequest, req models.PatchUserRequest) (*models.UpdateUserRequest, error) {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	switch req.Format {
+	case models.MergePatch:
+		patched, err = jsonpatch.MergePatch(doc, req.Patch)
+	case models.JSONPatch:
+		var patch jsonpatch.Patch
+		patch, err = jsonpatch.DecodePatch(req.Patch)
+		if err == nil {
+			patched, err = patch.Apply(doc)
+		}
+	default:
+		return nil, apperrors.Validation("Unsupported patch format")
+	}
+	if err != nil {
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return nil, &apperrors.Error{Kind: apperrors.ErrConflict, Message: "Patch test operation failed", Err: err}
+		}
+		return nil, &apperrors.Error{Kind: apperrors.ErrValidation, Message: "Invalid patch document", Err: err}
+	}
+
+	var merged models.UpdateUserRequest
+	dec := json.NewDecoder(bytes.NewReader(patched))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&merged); err != nil {
+		return nil, &apperrors.Error{Kind: apperrors.ErrValidation, Message: "Patched user is invalid", Err: err}
+	}
+
+	if err := merged.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &merged, nil
+}
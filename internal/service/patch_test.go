@@ -0,0 +1,75 @@
+// internal/service/patch_test.go
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+)
+
+func TestApplyPatch(t *testing.T) {
+	current := models.UpdateUserRequest{Name: "Alice", DOB: "1990-05-10"}
+
+	tests := []struct {
+		name    string
+		req     models.PatchUserRequest
+		want    models.UpdateUserRequest
+		wantErr error
+	}{
+		{
+			name: "Merge patch changes name only",
+			req:  models.PatchUserRequest{Format: models.MergePatch, Patch: []byte(`{"name":"Alicia"}`)},
+			want: models.UpdateUserRequest{Name: "Alicia", DOB: "1990-05-10"},
+		},
+		{
+			name: "JSON patch replaces dob",
+			req:  models.PatchUserRequest{Format: models.JSONPatch, Patch: []byte(`[{"op":"replace","path":"/dob","value":"1991-01-02"}]`)},
+			want: models.UpdateUserRequest{Name: "Alice", DOB: "1991-01-02"},
+		},
+		{
+			name:    "Merge patch removing a required field",
+			req:     models.PatchUserRequest{Format: models.MergePatch, Patch: []byte(`{"name":null}`)},
+			wantErr: apperrors.ErrValidation,
+		},
+		{
+			name:    "Merge patch with invalid date",
+			req:     models.PatchUserRequest{Format: models.MergePatch, Patch: []byte(`{"dob":"10/05/1990"}`)},
+			wantErr: apperrors.ErrValidation,
+		},
+		{
+			name:    "Unknown field",
+			req:     models.PatchUserRequest{Format: models.MergePatch, Patch: []byte(`{"age":30}`)},
+			wantErr: apperrors.ErrValidation,
+		},
+		{
+			name:    "Failed test operation",
+			req:     models.PatchUserRequest{Format: models.JSONPatch, Patch: []byte(`[{"op":"test","path":"/name","value":"Bob"}]`)},
+			wantErr: apperrors.ErrConflict,
+		},
+		{
+			name:    "Malformed patch",
+			req:     models.PatchUserRequest{Format: models.JSONPatch, Patch: []byte(`{"op":"replace"}`)},
+			wantErr: apperrors.ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyPatch(current, tt.req)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("applyPatch() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyPatch() error = %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("applyPatch() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
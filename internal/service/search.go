@@ -0,0 +1,93 @@
+// internal/service/search.go
+package service
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/repository"
+	"go.uber.org/zap"
+)
+
+const defaultSearchLimit = 20
+
+func (s *userService) SearchUsers(ctx context.Context, query models.SearchUsersQuery) ([]models.UserSearchResult, error) {
+	params := repository.SearchParams{
+		Query:         strings.TrimSpace(query.Q),
+		MinSimilarity: s.config.SearchMinSimilarity,
+		Limit:         defaultSearchLimit,
+	}
+	if query.MinSimilarity != nil {
+		params.MinSimilarity = *query.MinSimilarity
+	}
+	if query.Limit > 0 {
+		params.Limit = int32(query.Limit)
+	}
+
+	rows, err := s.repo.SearchUsers(ctx, params)
+	if err != nil {
+		s.logger.Error("Failed to search users", zap.Error(err))
+		return nil, storeError(err, "failed to search users")
+	}
+
+	terms := strings.Fields(params.Query)
+	results := make([]models.UserSearchResult, 0, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		user := &sqlc.User{
+			ID:        row.ID,
+			Name:      row.Name,
+			Dob:       row.Dob,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+			Version:   row.Version,
+			DeletedAt: row.DeletedAt,
+			Timezone:  row.Timezone,
+
+			MergedInto: row.MergedInto,
+		}
+		results = append(results, models.UserSearchResult{
+			UserResponse: s.userResponse(ctx, user, models.DefaultFields),
+			Rank:         row.Rank,
+			Highlight:    highlight(row.Name, terms),
+		})
+	}
+	return results, nil
+}
+
+// highlight HTML-escapes name and wraps every case-insensitive occurrence of
+// a search term in <mark> tags. Names matched only by similarity, such as
+// misspellings, come back unmarked.
+func highlight(name string, terms []string) string {
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		// Skip websearch syntax: negated words, OR and phrase quotes
+		if strings.HasPrefix(term, "-") || strings.EqualFold(term, "or") {
+			continue
+		}
+		if term = strings.Trim(term, `"`); term != "" {
+			quoted = append(quoted, regexp.QuoteMeta(term))
+		}
+	}
+	if len(quoted) == 0 {
+		return html.EscapeString(name)
+	}
+
+	// Alternation prefers the first match, so try longer terms first
+	sort.Slice(quoted, func(i, j int) bool { return len(quoted[i]) > len(quoted[j]) })
+	pattern := regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+	var b strings.Builder
+	last := 0
+	for _, loc := range pattern.FindAllStringIndex(name, -1) {
+		b.WriteString(html.EscapeString(name[last:loc[0]]))
+		b.WriteString("<mark>" + html.EscapeString(name[loc[0]:loc[1]]) + "</mark>")
+		last = loc[1]
+	}
+	b.WriteString(html.EscapeString(name[last:]))
+	return b.String()
+}
@@ -0,0 +1,34 @@
+// internal/service/search_test.go
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		userName string
+		query    string
+		want     string
+	}{
+		{name: "Whole word", userName: "Alice Smith", query: "smith", want: "Alice <mark>Smith</mark>"},
+		{name: "Partial word", userName: "Alice Smith", query: "ali", want: "<mark>Ali</mark>ce Smith"},
+		{name: "Longer term wins", userName: "Alice", query: "al alice", want: "<mark>Alice</mark>"},
+		{name: "Repeated match", userName: "Anna Annan", query: "ann", want: "<mark>Ann</mark>a <mark>Ann</mark>an"},
+		{name: "Misspelling", userName: "Alice", query: "alise", want: "Alice"},
+		{name: "Negated and OR", userName: "Bob Lee", query: "lee or -bob", want: "Bob <mark>Lee</mark>"},
+		{name: "Phrase", userName: "Ann Lee", query: `"ann lee"`, want: "<mark>Ann</mark> <mark>Lee</mark>"},
+		{name: "Escapes HTML", userName: "<b>Eve</b>", query: "eve", want: "&lt;b&gt;<mark>Eve</mark>&lt;/b&gt;"},
+		{name: "Regex characters", userName: "J. (Jay) Doe", query: "(jay)", want: "J. <mark>(Jay)</mark> Doe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := highlight(tt.userName, strings.Fields(tt.query)); got != tt.want {
+				t.Errorf("highlight(%q, %q) = %q, want %q", tt.userName, tt.query, got, tt.want)
+			}
+		})
+	}
+}
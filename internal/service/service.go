@@ -7,74 +7,144 @@ import (
 	"errors"
 	"time"
 
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/clock"
+	"github.com/shravanirajulu2004/go-user-api/internal/importer"
 	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/pagination"
 	"github.com/shravanirajulu2004/go-user-api/internal/repository"
 	"go.uber.org/zap"
 )
 
+// UserService manages users. Methods taking an expectedVersion perform the
+// write only if the stored version still matches; nil skips the check.
 type UserService interface {
 	CreateUser(ctx context.Context, req models.CreateUserRequest) (*models.UserResponse, error)
-	GetUserByID(ctx context.Context, id int32) (*models.UserResponse, error)
-	ListUsers(ctx context.Context, page, pageSize int) ([]models.UserResponse, int64, error)
-	UpdateUser(ctx context.Context, id int32, req models.UpdateUserRequest) (*models.UserResponse, error)
-	DeleteUser(ctx context.Context, id int32) error
+	GetUserByID(ctx context.Context, id int32, includeDeleted bool, fields models.UserFields) (*models.UserResponse, error)
+	ListUsers(ctx context.Context, query models.ListUsersQuery) (*models.UserPage, error)
+	UpdateUser(ctx context.Context, id int32, req models.UpdateUserRequest, expectedVersion *int32) (*models.UserResponse, error)
+	PatchUser(ctx context.Context, id int32, req models.PatchUserRequest, expectedVersion *int32) (*models.UserResponse, error)
+	DeleteUser(ctx context.Context, id int32, expectedVersion *int32) error
+	RestoreUser(ctx context.Context, id int32) (*models.UserResponse, error)
+	PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int64, error)
+	SearchUsers(ctx context.Context, query models.SearchUsersQuery) ([]models.UserSearchResult, error)
+	ExecuteBatch(ctx context.Context, req models.BatchRequest) ([]models.BatchItemResult, error)
+	ImportUsers(ctx context.Context, reader importer.Reader, opts models.ImportOptions) (*models.ImportReport, error)
+	ExportUsers(ctx context.Context, filter models.UserFilter, includeDeleted bool) (UserExport, error)
+	UpcomingBirthdays(ctx context.Context, within int) ([]models.UserResponse, error)
+	DuplicateClusters(ctx context.Context, query models.DuplicatesQuery) ([]models.DuplicateCluster, error)
+	MergeUsers(ctx context.Context, id int32, req models.MergeUsersRequest, expectedVersion *int32) (*models.UserResponse, error)
+	UserHistory(ctx context.Context, id int32, query models.HistoryQuery) (*models.HistoryPage, error)
+	GetUserAsOf(ctx context.Context, id int32, asOf time.Time, includeDeleted bool, fields models.UserFields) (*models.UserResponse, error)
+}
+
+// Config holds tunables for UserService
+type Config struct {
+	// PurgeRetention is the default age of soft-deleted users to purge
+	PurgeRetention time.Duration
+
+	// CursorSecret signs pagination cursors
+	CursorSecret []byte
+
+	// SearchMinSimilarity is the trigram similarity used when a search
+	// does not specify one
+	SearchMinSimilarity float64
+
+	// Clock tells the time ages are computed at; the system clock when nil
+	Clock clock.Clock
+
+	// MajorityAge is the age below which users are reported as minors;
+	// 18 when zero
+	MajorityAge int
+
+	// DuplicatePolicy is what CreateUser does with a user matching an
+	// existing one; DuplicateAllow when empty
+	DuplicatePolicy DuplicatePolicy
+	// DuplicateMinSimilarity, when positive, also matches users born the
+	// same day whose names have at least this trigram similarity (0-1)
+	DuplicateMinSimilarity float64
 }
 
 type userService struct {
-	repo   repository.UserRepository
-	logger *zap.Logger
+	repo    repository.UserRepository
+	logger  *zap.Logger
+	config  Config
+	cursors pagination.Codec
+	clock   clock.Clock
 }
 
-func NewUserService(repo repository.UserRepository, logger *zap.Logger) UserService {
+func NewUserService(repo repository.UserRepository, logger *zap.Logger, config Config) UserService {
+	if config.Clock == nil {
+		config.Clock = clock.System()
+	}
+	if config.MajorityAge <= 0 {
+		config.MajorityAge = 18
+	}
+	if config.DuplicatePolicy == "" {
+		config.DuplicatePolicy = DuplicateAllow
+	}
 	return &userService{
-		repo:   repo,
-		logger: logger,
+		repo:    repo,
+		logger:  logger,
+		config:  config,
+		cursors: pagination.NewCodec(config.CursorSecret),
+		clock:   config.Clock,
 	}
 }
 
+// withRepo returns a copy of s using repo, typically bound to a transaction
+func (s *userService) withRepo(repo repository.UserRepository) *userService {
+	tx := *s
+	tx.repo = repo
+	return &tx
+}
+
 func (s *userService) CreateUser(ctx context.Context, req models.CreateUserRequest) (*models.UserResponse, error) {
 	dob, err := time.Parse("2006-01-02", req.DOB)
 	if err != nil {
 		s.logger.Error("Invalid date format", zap.Error(err))
-		return nil, errors.New("invalid date format, use YYYY-MM-DD")
+		return nil, invalidDOB()
 	}
 
-	user, err := s.repo.CreateUser(ctx, req.Name, dob)
+	if s.config.DuplicatePolicy != DuplicateAllow {
+		return s.createChecked(ctx, req, dob)
+	}
+
+	var user *sqlc.User
+	err = s.repo.InTx(ctx, func(repo repository.UserRepository) error {
+		if user, err = repo.CreateUser(ctx, req.Name, dob, req.Timezone); err != nil {
+			return err
+		}
+		return s.recordChange(ctx, repo, historyCreate, nil, user)
+	})
 	if err != nil {
 		s.logger.Error("Failed to create user", zap.Error(err))
-		return nil, err
+		return nil, storeError(err, "failed to create user")
 	}
 
 	s.logger.Info("User created successfully", zap.Int32("user_id", user.ID))
 
-	return &models.UserResponse{
-		ID:   user.ID,
-		Name: user.Name,
-		DOB:  user.Dob.Format("2006-01-02"),
-	}, nil
+	response := toUserResponse(user)
+	return &response, nil
 }
 
-func (s *userService) GetUserByID(ctx context.Context, id int32) (*models.UserResponse, error) {
-	user, err := s.repo.GetUserByID(ctx, id)
+func (s *userService) GetUserByID(ctx context.Context, id int32, includeDeleted bool, fields models.UserFields) (*models.UserResponse, error) {
+	user, err := s.repo.GetUserByID(ctx, id, includeDeleted, userColumns(fields)...)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New("user not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, s.missingUser(ctx, id)
 		}
 		s.logger.Error("Failed to get user", zap.Error(err), zap.Int32("user_id", id))
-		return nil, err
+		return nil, storeError(err, "failed to get user")
 	}
 
-	age := models.CalculateAge(user.Dob)
-
-	return &models.UserResponse{
-		ID:   user.ID,
-		Name: user.Name,
-		DOB:  user.Dob.Format("2006-01-02"),
-		Age:  &age,
-	}, nil
+	response := s.userResponse(ctx, user, fields)
+	return &response, nil
 }
 
-func (s *userService) ListUsers(ctx context.Context, page, pageSize int) ([]models.UserResponse, int64, error) {
+func (s *userService) ListUsers(ctx context.Context, query models.ListUsersQuery) (*models.UserPage, error) {
+	page, pageSize := query.Page, query.PageSize
 	if page < 1 {
 		page = 1
 	}
@@ -82,68 +152,338 @@ func (s *userService) ListUsers(ctx context.Context, page, pageSize int) ([]mode
 		pageSize = 10
 	}
 
-	offset := (page - 1) * pageSize
-	users, err := s.repo.ListUsers(ctx, int32(pageSize), int32(offset))
+	order, err := parseSort(query.Filter.Sort)
 	if err != nil {
-		s.logger.Error("Failed to list users", zap.Error(err))
-		return nil, 0, err
+		return nil, err
+	}
+	filter, err := toRepositoryFilter(query.Filter, query.IncludeDeleted, s.now(ctx))
+	if err != nil {
+		return nil, err
 	}
 
-	total, err := s.repo.CountUsers(ctx)
+	params := repository.ListParams{
+		Filter:  filter,
+		Columns: userColumns(query.Fields),
+		Sort:    order.fields,
+		IDDesc:  order.idDesc,
+		Limit:   int32(pageSize),
+	}
+	result := &models.UserPage{PageSize: pageSize}
+
+	if query.UseCursor {
+		if query.Cursor != "" {
+			cursor, err := s.cursors.Decode(query.Cursor)
+			if err != nil || cursor.Sort != order.canonical || len(cursor.Values) != len(order.fields) {
+				return nil, invalidCursor()
+			}
+			params.After = &repository.Keyset{Values: cursor.Values, ID: cursor.ID}
+			params.Backward = cursor.Backward
+		}
+		// Fetch one extra row to learn whether another page exists
+		params.Limit++
+	} else {
+		params.Offset = int32((page - 1) * pageSize)
+		result.Page = page
+	}
+
+	users, err := s.repo.ListUsers(ctx, params)
 	if err != nil {
-		s.logger.Error("Failed to count users", zap.Error(err))
-		return nil, 0, err
-	}
-
-	responses := make([]models.UserResponse, 0, len(users))
-	for _, user := range users {
-		age := models.CalculateAge(user.Dob)
-		responses = append(responses, models.UserResponse{
-			ID:   user.ID,
-			Name: user.Name,
-			DOB:  user.Dob.Format("2006-01-02"),
-			Age:  &age,
-		})
+		s.logger.Error("Failed to list users", zap.Error(err))
+		return nil, storeError(err, "failed to list users")
+	}
+
+	if query.UseCursor {
+		users = s.setCursors(result, users, params, order.canonical, query.Cursor != "")
+	}
+
+	if !query.UseCursor || query.WithTotal {
+		total, err := s.repo.CountUsers(ctx, filter)
+		if err != nil {
+			s.logger.Error("Failed to count users", zap.Error(err))
+			return nil, storeError(err, "failed to count users")
+		}
+		result.Total = &total
+	}
+
+	result.Users = make([]models.UserResponse, 0, len(users))
+	for i := range users {
+		result.Users = append(result.Users, s.userResponse(ctx, &users[i], query.Fields))
+	}
+
+	return result, nil
+}
+
+// setCursors trims the extra row fetched by a keyset query and records the
+// cursors of the neighbouring pages, returning the users on this page
+func (s *userService) setCursors(page *models.UserPage, users []sqlc.User, params repository.ListParams, sort string, fromCursor bool) []sqlc.User {
+	hasMore := len(users) > page.PageSize
+	if hasMore {
+		if params.Backward {
+			users = users[1:]
+		} else {
+			users = users[:page.PageSize]
+		}
+	}
+	if len(users) == 0 {
+		return users
+	}
+
+	hasNext, hasPrev := hasMore, fromCursor
+	if params.Backward {
+		hasNext, hasPrev = true, hasMore
 	}
 
-	return responses, total, nil
+	if hasNext {
+		last := repository.KeysetOf(&users[len(users)-1], params.Sort)
+		page.NextCursor = s.cursors.Encode(pagination.Cursor{Sort: sort, Values: last.Values, ID: last.ID})
+	}
+	if hasPrev {
+		first := repository.KeysetOf(&users[0], params.Sort)
+		page.PrevCursor = s.cursors.Encode(pagination.Cursor{Sort: sort, Values: first.Values, ID: first.ID, Backward: true})
+	}
+	return users
 }
 
-func (s *userService) UpdateUser(ctx context.Context, id int32, req models.UpdateUserRequest) (*models.UserResponse, error) {
+func (s *userService) UpdateUser(ctx context.Context, id int32, req models.UpdateUserRequest, expectedVersion *int32) (*models.UserResponse, error) {
 	dob, err := time.Parse("2006-01-02", req.DOB)
 	if err != nil {
 		s.logger.Error("Invalid date format", zap.Error(err))
-		return nil, errors.New("invalid date format, use YYYY-MM-DD")
+		return nil, invalidDOB()
 	}
 
-	user, err := s.repo.UpdateUser(ctx, id, req.Name, dob)
+	var user *sqlc.User
+	err = s.repo.InTx(ctx, func(repo repository.UserRepository) error {
+		before, err := repo.LockUser(ctx, id, false)
+		if err != nil {
+			return err
+		}
+		if user, err = repo.UpdateUser(ctx, id, req.Name, dob, req.Timezone, expectedVersion); err != nil {
+			return err
+		}
+		return s.recordChange(ctx, repo, historyUpdate, before, user)
+	})
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New("user not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, s.missingOrStale(ctx, id, expectedVersion)
 		}
 		s.logger.Error("Failed to update user", zap.Error(err), zap.Int32("user_id", id))
-		return nil, err
+		return nil, storeError(err, "failed to update user")
 	}
 
 	s.logger.Info("User updated successfully", zap.Int32("user_id", user.ID))
 
-	return &models.UserResponse{
-		ID:   user.ID,
+	response := toUserResponse(user)
+	return &response, nil
+}
+
+func (s *userService) PatchUser(ctx context.Context, id int32, req models.PatchUserRequest, expectedVersion *int32) (*models.UserResponse, error) {
+	user, err := s.repo.GetUserByID(ctx, id, false)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, s.missingUser(ctx, id)
+		}
+		s.logger.Error("Failed to get user", zap.Error(err), zap.Int32("user_id", id))
+		return nil, storeError(err, "failed to get user")
+	}
+	if expectedVersion != nil && *expectedVersion != user.Version {
+		return nil, versionMismatch(id, user.Version)
+	}
+
+	current := models.UpdateUserRequest{
 		Name: user.Name,
 		DOB:  user.Dob.Format("2006-01-02"),
-	}, nil
+	}
+	if user.Timezone.Valid {
+		current.Timezone = &user.Timezone.String
+	}
+	merged, err := applyPatch(current, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only send the columns that actually changed
+	var patch repository.UserPatch
+	if merged.Name != current.Name {
+		patch.Name = &merged.Name
+	}
+	if merged.DOB != current.DOB {
+		parsed, err := time.Parse("2006-01-02", merged.DOB)
+		if err != nil {
+			return nil, invalidDOB()
+		}
+		patch.Dob = &parsed
+	}
+	if !equalTimezone(merged.Timezone, current.Timezone) {
+		patch.Timezone = merged.Timezone
+		patch.SetTimezone = true
+	}
+
+	if patch.Name != nil || patch.Dob != nil || patch.SetTimezone {
+		// Guard with the version the patch was applied to so a concurrent
+		// write between the read and the update is not lost
+		readVersion := user.Version
+		before := user
+		err = s.repo.InTx(ctx, func(repo repository.UserRepository) error {
+			if user, err = repo.PatchUser(ctx, id, patch, &readVersion); err != nil {
+				return err
+			}
+			return s.recordChange(ctx, repo, historyUpdate, before, user)
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, s.missingOrStale(ctx, id, &readVersion)
+			}
+			s.logger.Error("Failed to patch user", zap.Error(err), zap.Int32("user_id", id))
+			return nil, storeError(err, "failed to patch user")
+		}
+		s.logger.Info("User patched successfully", zap.Int32("user_id", user.ID))
+	}
+
+	response := toUserResponse(user)
+	return &response, nil
 }
 
-func (s *userService) DeleteUser(ctx context.Context, id int32) error {
-	err := s.repo.DeleteUser(ctx, id)
+func (s *userService) DeleteUser(ctx context.Context, id int32, expectedVersion *int32) error {
+	err := s.repo.InTx(ctx, func(repo repository.UserRepository) error {
+		before, err := repo.LockUser(ctx, id, false)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteUser(ctx, id, expectedVersion); err != nil {
+			return err
+		}
+		after, err := repo.GetUserByID(ctx, id, true)
+		if err != nil {
+			return err
+		}
+		return s.recordChange(ctx, repo, historyDelete, before, after)
+	})
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return errors.New("user not found")
+		if errors.Is(err, sql.ErrNoRows) {
+			return s.missingOrStale(ctx, id, expectedVersion)
 		}
 		s.logger.Error("Failed to delete user", zap.Error(err), zap.Int32("user_id", id))
-		return err
+		return storeError(err, "failed to delete user")
 	}
 
 	s.logger.Info("User deleted successfully", zap.Int32("user_id", id))
 	return nil
-}
\ No newline at end of file
+}
+
+func (s *userService) RestoreUser(ctx context.Context, id int32) (*models.UserResponse, error) {
+	var user *sqlc.User
+	err := s.repo.InTx(ctx, func(repo repository.UserRepository) error {
+		before, err := repo.LockUser(ctx, id, true)
+		if err != nil {
+			return err
+		}
+		if user, err = repo.RestoreUser(ctx, id); err != nil {
+			return err
+		}
+		return s.recordChange(ctx, repo, historyRestore, before, user)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Distinguish a live or merged user from one that never existed
+			// or was purged
+			if _, getErr := s.repo.GetUserByID(ctx, id, false); getErr == nil {
+				return nil, apperrors.Conflict("user %d is not deleted", id)
+			}
+			if into, getErr := s.repo.GetMergedInto(ctx, id); getErr == nil {
+				return nil, apperrors.Conflict("user %d was merged into user %d and cannot be restored", id, into).With("merged_into", into)
+			}
+			return nil, userNotFound(id)
+		}
+		s.logger.Error("Failed to restore user", zap.Error(err), zap.Int32("user_id", id))
+		return nil, storeError(err, "failed to restore user")
+	}
+
+	s.logger.Info("User restored successfully", zap.Int32("user_id", id))
+
+	response := toUserResponse(user)
+	return &response, nil
+}
+
+// PurgeDeletedUsers hard-deletes users soft-deleted longer ago than
+// retention, or the configured retention when it is not positive
+func (s *userService) PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int64, error) {
+	if retention <= 0 {
+		retention = s.config.PurgeRetention
+	}
+
+	purged, err := s.repo.PurgeDeletedUsers(ctx, retention)
+	if err != nil {
+		s.logger.Error("Failed to purge deleted users", zap.Error(err))
+		return 0, storeError(err, "failed to purge deleted users")
+	}
+
+	s.logger.Info("Purged deleted users", zap.Int64("count", purged), zap.Duration("retention", retention))
+	return purged, nil
+}
+
+// missingOrStale explains why a guarded write matched no rows: either the
+// user does not exist or its version has moved on
+func (s *userService) missingOrStale(ctx context.Context, id int32, expectedVersion *int32) error {
+	if expectedVersion == nil {
+		return s.missingUser(ctx, id)
+	}
+
+	user, err := s.repo.GetUserByID(ctx, id, false)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return s.missingUser(ctx, id)
+		}
+		s.logger.Error("Failed to get user", zap.Error(err), zap.Int32("user_id", id))
+		return storeError(err, "failed to get user")
+	}
+	return versionMismatch(id, user.Version)
+}
+
+// missingUser explains why a live user was not found: it was merged into
+// another, which callers are redirected to, or it does not exist
+func (s *userService) missingUser(ctx context.Context, id int32) error {
+	into, err := s.repo.GetMergedInto(ctx, id)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.logger.Error("Failed to look up merged user", zap.Error(err), zap.Int32("user_id", id))
+		}
+		return userNotFound(id)
+	}
+	return userMerged(id, into)
+}
+
+func toUserResponse(user *sqlc.User) models.UserResponse {
+	response := models.UserResponse{
+		ID:      user.ID,
+		Name:    user.Name,
+		DOB:     user.Dob.Format("2006-01-02"),
+		Version: user.Version,
+	}
+	if user.Timezone.Valid {
+		timezone := user.Timezone.String
+		response.Timezone = &timezone
+	}
+	if user.DeletedAt.Valid {
+		deletedAt := user.DeletedAt.Time.Format(time.RFC3339)
+		response.DeletedAt = &deletedAt
+	}
+	if user.MergedInto.Valid {
+		mergedInto := user.MergedInto.Int32
+		response.MergedInto = &mergedInto
+	}
+	return response
+}
+
+func equalTimezone(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func nullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
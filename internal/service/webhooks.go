@@ -0,0 +1,308 @@
+// internal/service/webhooks.go
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/apperrors"
+	"github.com/shravanirajulu2004/go-user-api/internal/models"
+	"github.com/shravanirajulu2004/go-user-api/internal/pagination"
+	"github.com/shravanirajulu2004/go-user-api/internal/repository"
+	"github.com/shravanirajulu2004/go-user-api/internal/webhook"
+	"go.uber.org/zap"
+)
+
+// WebhookService manages webhook subscriptions and the deliveries of user
+// events to them
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, req models.WebhookRequest) (*models.WebhookResponse, error)
+	GetWebhook(ctx context.Context, id int32) (*models.WebhookResponse, error)
+	ListWebhooks(ctx context.Context) ([]models.WebhookResponse, error)
+	UpdateWebhook(ctx context.Context, id int32, req models.WebhookRequest) (*models.WebhookResponse, error)
+	DeleteWebhook(ctx context.Context, id int32) error
+	// ListDeliveries lists the deliveries to a webhook, or to every webhook
+	// when webhookID is 0, newest first
+	ListDeliveries(ctx context.Context, webhookID int32, query models.WebhookDeliveriesQuery) (*models.WebhookDeliveryPage, error)
+	GetDelivery(ctx context.Context, webhookID int32, id int64) (*models.WebhookDeliveryResponse, error)
+	Redeliver(ctx context.Context, webhookID int32, id int64) (*models.WebhookDeliveryResponse, error)
+}
+
+// WebhookConfig holds tunables for WebhookService
+type WebhookConfig struct {
+	// CursorSecret signs pagination cursors
+	CursorSecret []byte
+}
+
+const defaultDeliveryLimit = 20
+
+type webhookService struct {
+	repo    repository.WebhookRepository
+	logger  *zap.Logger
+	cursors pagination.Codec
+}
+
+func NewWebhookService(repo repository.WebhookRepository, logger *zap.Logger, config WebhookConfig) WebhookService {
+	return &webhookService{
+		repo:    repo,
+		logger:  logger,
+		cursors: pagination.NewCodec(config.CursorSecret),
+	}
+}
+
+func webhookNotFound(id int32) error {
+	return apperrors.NotFound("webhook %d not found", id)
+}
+
+func deliveryNotFound(webhookID int32, id int64) error {
+	return apperrors.NotFound("delivery %d of webhook %d not found", id, webhookID)
+}
+
+func (s *webhookService) CreateWebhook(ctx context.Context, req models.WebhookRequest) (*models.WebhookResponse, error) {
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = webhook.NewSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	subscription, err := s.repo.CreateSubscription(ctx, sqlc.CreateWebhookSubscriptionParams{
+		Url:         req.URL,
+		EventTypes:  subscribedTypes(req.EventTypes),
+		Secret:      secret,
+		Description: req.Description,
+		Active:      req.Active == nil || *req.Active,
+	})
+	if err != nil {
+		s.logger.Error("Failed to create webhook", zap.Error(err))
+		return nil, storeError(err, "failed to create webhook")
+	}
+
+	s.logger.Info("Webhook created", zap.Int32("id", subscription.ID), zap.String("url", subscription.Url))
+	response := toWebhookResponse(subscription)
+	response.Secret = subscription.Secret
+	return &response, nil
+}
+
+func (s *webhookService) GetWebhook(ctx context.Context, id int32) (*models.WebhookResponse, error) {
+	subscription, err := s.repo.GetSubscription(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, webhookNotFound(id)
+		}
+		s.logger.Error("Failed to get webhook", zap.Error(err), zap.Int32("id", id))
+		return nil, storeError(err, "failed to get webhook")
+	}
+	response := toWebhookResponse(subscription)
+	return &response, nil
+}
+
+func (s *webhookService) ListWebhooks(ctx context.Context) ([]models.WebhookResponse, error) {
+	subscriptions, err := s.repo.ListSubscriptions(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list webhooks", zap.Error(err))
+		return nil, storeError(err, "failed to list webhooks")
+	}
+
+	responses := make([]models.WebhookResponse, 0, len(subscriptions))
+	for i := range subscriptions {
+		responses = append(responses, toWebhookResponse(&subscriptions[i]))
+	}
+	return responses, nil
+}
+
+func (s *webhookService) UpdateWebhook(ctx context.Context, id int32, req models.WebhookRequest) (*models.WebhookResponse, error) {
+	subscription, err := s.repo.UpdateSubscription(ctx, sqlc.UpdateWebhookSubscriptionParams{
+		Url:         req.URL,
+		EventTypes:  subscribedTypes(req.EventTypes),
+		Secret:      sql.NullString{String: req.Secret, Valid: req.Secret != ""},
+		Description: req.Description,
+		Active:      req.Active == nil || *req.Active,
+		ID:          id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, webhookNotFound(id)
+		}
+		s.logger.Error("Failed to update webhook", zap.Error(err), zap.Int32("id", id))
+		return nil, storeError(err, "failed to update webhook")
+	}
+
+	s.logger.Info("Webhook updated", zap.Int32("id", id))
+	response := toWebhookResponse(subscription)
+	if req.Secret != "" {
+		response.Secret = subscription.Secret
+	}
+	return &response, nil
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, id int32) error {
+	if err := s.repo.DeleteSubscription(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return webhookNotFound(id)
+		}
+		s.logger.Error("Failed to delete webhook", zap.Error(err), zap.Int32("id", id))
+		return storeError(err, "failed to delete webhook")
+	}
+	s.logger.Info("Webhook deleted", zap.Int32("id", id))
+	return nil
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, webhookID int32, query models.WebhookDeliveriesQuery) (*models.WebhookDeliveryPage, error) {
+	limit := query.Limit
+	if limit < 1 || limit > 100 {
+		limit = defaultDeliveryLimit
+	}
+
+	// Cursors only continue the list they were issued for
+	sort := "deliveries:" + query.Status
+	params := repository.DeliveryListParams{
+		SubscriptionID: webhookID,
+		Status:         query.Status,
+		Limit:          int32(limit + 1),
+	}
+	if query.Cursor != "" {
+		cursor, err := s.cursors.Decode(query.Cursor)
+		if err != nil || cursor.Sort != sort || cursor.ID != webhookID || len(cursor.Values) != 1 {
+			return nil, invalidCursor()
+		}
+		if params.BeforeID, err = strconv.ParseInt(cursor.Values[0], 10, 64); err != nil {
+			return nil, invalidCursor()
+		}
+	} else if webhookID > 0 {
+		if _, err := s.GetWebhook(ctx, webhookID); err != nil {
+			return nil, err
+		}
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, params)
+	if err != nil {
+		s.logger.Error("Failed to list webhook deliveries", zap.Error(err), zap.Int32("webhook_id", webhookID))
+		return nil, storeError(err, "failed to list webhook deliveries")
+	}
+
+	page := &models.WebhookDeliveryPage{Deliveries: make([]models.WebhookDeliveryResponse, 0, len(deliveries))}
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+		page.NextCursor = s.cursors.Encode(pagination.Cursor{
+			Sort:   sort,
+			Values: []string{strconv.FormatInt(deliveries[limit-1].ID, 10)},
+			ID:     webhookID,
+		})
+	}
+	for i := range deliveries {
+		page.Deliveries = append(page.Deliveries, toDeliveryResponse(&deliveries[i]))
+	}
+	return page, nil
+}
+
+func (s *webhookService) GetDelivery(ctx context.Context, webhookID int32, id int64) (*models.WebhookDeliveryResponse, error) {
+	delivery, err := s.repo.GetDelivery(ctx, webhookID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, deliveryNotFound(webhookID, id)
+		}
+		s.logger.Error("Failed to get webhook delivery", zap.Error(err), zap.Int64("id", id))
+		return nil, storeError(err, "failed to get webhook delivery")
+	}
+
+	attempts, err := s.repo.ListDeliveryAttempts(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to list webhook delivery attempts", zap.Error(err), zap.Int64("id", id))
+		return nil, storeError(err, "failed to list webhook delivery attempts")
+	}
+
+	response := toDeliveryResponse(delivery)
+	response.Payload = delivery.Payload
+	response.Log = make([]models.WebhookAttempt, 0, len(attempts))
+	for _, a := range attempts {
+		response.Log = append(response.Log, models.WebhookAttempt{
+			AttemptedAt: formatTime(a.AttemptedAt),
+			StatusCode:  nullInt32(a.StatusCode),
+			Error:       nullStringPtr(a.Error),
+			DurationMs:  a.DurationMs,
+		})
+	}
+	return &response, nil
+}
+
+func (s *webhookService) Redeliver(ctx context.Context, webhookID int32, id int64) (*models.WebhookDeliveryResponse, error) {
+	delivery, err := s.repo.Redeliver(ctx, webhookID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, deliveryNotFound(webhookID, id)
+		}
+		s.logger.Error("Failed to redeliver webhook delivery", zap.Error(err), zap.Int64("id", id))
+		return nil, storeError(err, "failed to redeliver webhook delivery")
+	}
+
+	s.logger.Info("Webhook delivery queued again", zap.Int64("id", id), zap.Int32("webhook_id", webhookID))
+	response := toDeliveryResponse(delivery)
+	return &response, nil
+}
+
+func toWebhookResponse(subscription *sqlc.WebhookSubscription) models.WebhookResponse {
+	return models.WebhookResponse{
+		ID:          subscription.ID,
+		URL:         subscription.Url,
+		EventTypes:  subscribedTypes(subscription.EventTypes),
+		Description: subscription.Description,
+		Active:      subscription.Active,
+		CreatedAt:   formatTime(subscription.CreatedAt),
+		UpdatedAt:   formatTime(subscription.UpdatedAt),
+	}
+}
+
+func toDeliveryResponse(delivery *sqlc.WebhookDelivery) models.WebhookDeliveryResponse {
+	response := models.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		WebhookID:      delivery.SubscriptionID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastStatusCode: nullInt32(delivery.LastStatusCode),
+		LastError:      nullStringPtr(delivery.LastError),
+		CreatedAt:      formatTime(delivery.CreatedAt),
+	}
+	if delivery.Status == webhook.StatusPending {
+		next := formatTime(delivery.NextAttemptAt)
+		response.NextAttemptAt = &next
+	}
+	if delivery.DeliveredAt.Valid {
+		deliveredAt := formatTime(delivery.DeliveredAt.Time)
+		response.DeliveredAt = &deliveredAt
+	}
+	return response
+}
+
+// subscribedTypes returns types, or an empty list for every type
+func subscribedTypes(types []string) []string {
+	if types == nil {
+		return []string{}
+	}
+	return types
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func nullInt32(n sql.NullInt32) *int32 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int32
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
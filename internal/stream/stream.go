@@ -0,0 +1,247 @@
+// internal/stream/stream.go
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/outbox"
+)
+
+// ErrClosed is returned by Subscribe once the hub has shut down
+var ErrClosed = errors.New("stream is closed")
+
+// Entry is an event in the hub's log. Its ID orders it among every event
+// the hub has seen.
+type Entry struct {
+	ID    string
+	Event outbox.Event
+}
+
+// Filter selects the events a subscriber receives. Empty fields match
+// everything.
+type Filter struct {
+	UserIDs []int32
+	Types   []string
+}
+
+func (f Filter) match(e outbox.Event) bool {
+	if len(f.UserIDs) > 0 && !containsID(f.UserIDs, e.UserID) {
+		return false
+	}
+	if len(f.Types) > 0 && !containsType(f.Types, e.Type) {
+		return false
+	}
+	return true
+}
+
+func containsID(ids []int32, id int32) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsType(types []string, t string) bool {
+	for _, s := range types {
+		if s == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Config holds the sizes of the hub's buffers
+type Config struct {
+	// LogSize is how many recent events are kept for subscribers resuming
+	// with Last-Event-ID
+	LogSize int
+	// BufferSize is how many events may wait for a slow subscriber before
+	// it is dropped
+	BufferSize int
+}
+
+// Hub fans user events out to live subscribers and keeps a bounded log of
+// them, so a subscriber that reconnects can pick up where it left off. It
+// is an outbox.Sink.
+type Hub struct {
+	mu sync.Mutex
+	// epoch tells this hub's IDs from those of an earlier process, whose
+	// log is gone
+	epoch  string
+	seq    uint64
+	log    []Entry // ring buffer of the last LogSize entries
+	start  int     // index of the oldest entry in log
+	subs   map[*Subscription]struct{}
+	closed bool
+	config Config
+}
+
+func NewHub(config Config) *Hub {
+	if config.LogSize <= 0 {
+		config.LogSize = 1000
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 64
+	}
+	return &Hub{
+		epoch:  strconv.FormatInt(time.Now().UnixNano(), 36),
+		log:    make([]Entry, 0, config.LogSize),
+		subs:   map[*Subscription]struct{}{},
+		config: config,
+	}
+}
+
+// Subscription receives the events matching its filter on C. C is closed
+// when the hub shuts down, or when the subscriber falls more than the
+// buffer size behind; it should then reconnect with the last ID it got.
+type Subscription struct {
+	C <-chan Entry
+	// Backlog holds the logged events after the ID the subscriber resumed
+	// from, oldest first
+	Backlog []Entry
+	// Reset reports that the ID resumed from is no longer in the log, so
+	// events may have been missed and the subscriber should reload
+	Reset bool
+
+	c      chan Entry
+	filter Filter
+	hub    *Hub
+}
+
+// Close unsubscribes. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.drop(s)
+}
+
+// Subscribe subscribes to the events matching filter. A non-empty lastID
+// resumes after that event, replaying the logged events since in Backlog.
+func (h *Hub) Subscribe(filter Filter, lastID string) (*Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, ErrClosed
+	}
+
+	c := make(chan Entry, h.config.BufferSize)
+	sub := &Subscription{C: c, c: c, filter: filter, hub: h}
+	if lastID != "" {
+		sub.Backlog, sub.Reset = h.since(lastID)
+		kept := sub.Backlog[:0]
+		for _, e := range sub.Backlog {
+			if filter.match(e.Event) {
+				kept = append(kept, e)
+			}
+		}
+		sub.Backlog = kept
+	}
+	h.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// since returns a copy of the logged entries after lastID, and whether
+// entries between lastID and the log may be missing
+func (h *Hub) since(lastID string) ([]Entry, bool) {
+	seq, ok := h.parseID(lastID)
+	if !ok || seq > h.seq {
+		return h.entries(0), true
+	}
+	oldest := h.seq - uint64(len(h.log)) + 1
+	if seq+1 < oldest {
+		return h.entries(0), true
+	}
+	return h.entries(int(seq + 1 - oldest)), false
+}
+
+// entries returns a copy of the log from its from-th oldest entry on
+func (h *Hub) entries(from int) []Entry {
+	entries := make([]Entry, 0, len(h.log)-from)
+	for i := from; i < len(h.log); i++ {
+		entries = append(entries, h.log[(h.start+i)%len(h.log)])
+	}
+	return entries
+}
+
+func (h *Hub) id(seq uint64) string {
+	return fmt.Sprintf("%s-%d", h.epoch, seq)
+}
+
+func (h *Hub) parseID(id string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(id, "-")
+	if !ok || epoch != h.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	return n, err == nil
+}
+
+// Publish logs an event and sends it to every matching subscriber.
+// Subscribers whose buffer is full are dropped rather than waited for. An
+// event still in the log, published again when catching up, is skipped.
+func (h *Hub) Publish(_ context.Context, e outbox.Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+	for _, logged := range h.log {
+		if logged.Event.ID == e.ID {
+			return nil
+		}
+	}
+
+	h.seq++
+	entry := Entry{ID: h.id(h.seq), Event: e}
+	if len(h.log) < h.config.LogSize {
+		h.log = append(h.log, entry)
+	} else {
+		h.log[h.start] = entry
+		h.start = (h.start + 1) % len(h.log)
+	}
+
+	for sub := range h.subs {
+		if !sub.filter.match(e) {
+			continue
+		}
+		select {
+		case sub.c <- entry:
+		default:
+			h.drop(sub)
+		}
+	}
+	return nil
+}
+
+// Subscribers returns how many subscriptions are open
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// Close ends every subscription and refuses new ones
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for sub := range h.subs {
+		h.drop(sub)
+	}
+}
+
+// drop removes a subscription and closes its channel; h.mu must be held
+func (h *Hub) drop(sub *Subscription) {
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.c)
+	}
+}
@@ -0,0 +1,138 @@
+// internal/stream/stream_test.go
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/outbox"
+)
+
+func publish(t *testing.T, h *Hub, id int64, userID int32, eventType string) {
+	t.Helper()
+	e := outbox.Event{ID: id, Type: eventType, UserID: userID, Data: json.RawMessage(`{}`)}
+	if err := h.Publish(context.Background(), e); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+}
+
+// received drains the events waiting on sub and returns their outbox IDs
+func received(sub *Subscription) []int64 {
+	var ids []int64
+	for {
+		select {
+		case e, ok := <-sub.C:
+			if !ok {
+				return ids
+			}
+			ids = append(ids, e.Event.ID)
+		default:
+			return ids
+		}
+	}
+}
+
+func equalIDs(got, want []int64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestHub_FiltersEvents(t *testing.T) {
+	h := NewHub(Config{})
+	all, _ := h.Subscribe(Filter{}, "")
+	alice, _ := h.Subscribe(Filter{UserIDs: []int32{1}}, "")
+	deletes, _ := h.Subscribe(Filter{Types: []string{outbox.UserDeleted}}, "")
+
+	publish(t, h, 1, 1, outbox.UserCreated)
+	publish(t, h, 2, 2, outbox.UserCreated)
+	publish(t, h, 3, 2, outbox.UserDeleted)
+	// A relay retry publishes an event again
+	publish(t, h, 2, 2, outbox.UserCreated)
+
+	tests := []struct {
+		name string
+		sub  *Subscription
+		want []int64
+	}{
+		{"All", all, []int64{1, 2, 3}},
+		{"By user", alice, []int64{1}},
+		{"By type", deletes, []int64{3}},
+	}
+	for _, tt := range tests {
+		if got := received(tt.sub); !equalIDs(got, tt.want) {
+			t.Errorf("%s: received %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHub_Resumes(t *testing.T) {
+	h := NewHub(Config{LogSize: 3})
+	sub, _ := h.Subscribe(Filter{}, "")
+	for i := int64(1); i <= 3; i++ {
+		publish(t, h, i, 1, outbox.UserUpdated)
+	}
+	var ids []string
+	for range 3 {
+		ids = append(ids, (<-sub.C).ID)
+	}
+	sub.Close()
+
+	resumed, _ := h.Subscribe(Filter{}, ids[0])
+	if resumed.Reset || len(resumed.Backlog) != 2 || resumed.Backlog[0].ID != ids[1] {
+		t.Errorf("resuming from %s: backlog %v, reset %v; want the 2 later events", ids[0], resumed.Backlog, resumed.Reset)
+	}
+	if latest, _ := h.Subscribe(Filter{}, ids[2]); latest.Reset || len(latest.Backlog) != 0 {
+		t.Errorf("resuming from the latest event: backlog %v, reset %v; want nothing", latest.Backlog, latest.Reset)
+	}
+
+	// The log only keeps 3 events, so the first has been forgotten
+	publish(t, h, 4, 1, outbox.UserUpdated)
+	publish(t, h, 5, 1, outbox.UserUpdated)
+	gone, _ := h.Subscribe(Filter{}, ids[0])
+	if !gone.Reset || len(gone.Backlog) != 3 || gone.Backlog[0].Event.ID != 3 {
+		t.Errorf("resuming from a forgotten event: backlog %v, reset %v; want a reset and events 3 to 5", gone.Backlog, gone.Reset)
+	}
+	if other, _ := h.Subscribe(Filter{}, "otherprocess-2"); !other.Reset {
+		t.Error("resuming from another process's ID did not reset")
+	}
+}
+
+func TestHub_DropsSlowSubscribers(t *testing.T) {
+	h := NewHub(Config{BufferSize: 2})
+	slow, _ := h.Subscribe(Filter{}, "")
+	for i := int64(1); i <= 3; i++ {
+		publish(t, h, i, 1, outbox.UserUpdated)
+	}
+
+	if got := received(slow); !equalIDs(got, []int64{1, 2}) {
+		t.Errorf("slow subscriber received %v, want [1 2] before being dropped", got)
+	}
+	if _, ok := <-slow.C; ok {
+		t.Error("slow subscriber's channel is still open")
+	}
+	if n := h.Subscribers(); n != 0 {
+		t.Errorf("Subscribers() = %d, want 0", n)
+	}
+	slow.Close()
+}
+
+func TestHub_Close(t *testing.T) {
+	h := NewHub(Config{})
+	sub, _ := h.Subscribe(Filter{}, "")
+	h.Close()
+
+	if _, ok := <-sub.C; ok {
+		t.Error("subscription is still open after Close()")
+	}
+	if _, err := h.Subscribe(Filter{}, ""); err != ErrClosed {
+		t.Errorf("Subscribe() after Close() error = %v, want %v", err, ErrClosed)
+	}
+}
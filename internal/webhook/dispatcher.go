@@ -0,0 +1,242 @@
+// internal/webhook/dispatcher.go
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/clock"
+	"go.uber.org/zap"
+)
+
+// Store hands out due deliveries and records how they went;
+// repository.WebhookRepository is one
+type Store interface {
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]sqlc.ClaimWebhookDeliveriesRow, error)
+	RecordAttempt(ctx context.Context, attempt sqlc.RecordWebhookAttemptParams) error
+}
+
+// Config holds how the dispatcher sends and retries deliveries
+type Config struct {
+	// PollInterval is how often due deliveries are looked for
+	PollInterval time.Duration
+	// BatchSize is how many deliveries are claimed at a time, and
+	// Concurrency how many of them are sent at once
+	BatchSize   int
+	Concurrency int
+	// Timeout bounds each attempt
+	Timeout time.Duration
+
+	// MaxAttempts is how many attempts a delivery gets before it is dead
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry; each further retry
+	// waits twice as long, up to MaxBackoff
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Clock stamps deliveries; the system clock when nil
+	Clock clock.Clock
+}
+
+// Dispatcher sends queued deliveries to their subscribers, signed with the
+// subscription's secret. A delivery succeeds on any 2xx response; redirects
+// are not followed.
+type Dispatcher struct {
+	store  Store
+	client *http.Client
+	config Config
+	logger *zap.Logger
+}
+
+func NewDispatcher(store Store, client *http.Client, config Config, logger *zap.Logger) *Dispatcher {
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 20
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 4
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 10
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = 30 * time.Second
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = max(time.Hour, config.MinBackoff)
+	}
+	if config.Clock == nil {
+		config.Clock = clock.System()
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return &Dispatcher{
+		store:  store,
+		client: &noRedirects,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Run sends due deliveries every poll interval until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := d.RunOnce(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			d.logger.Error("Failed to dispatch webhooks", zap.Error(err))
+		}
+	}
+}
+
+// RunOnce sends the deliveries due now and returns how many were attempted
+func (d *Dispatcher) RunOnce(ctx context.Context) (int, error) {
+	attempted := 0
+	for {
+		// Claims outlive a full batch of timed out attempts, so no other
+		// dispatcher picks them up meanwhile
+		lease := d.config.Timeout * time.Duration(d.config.BatchSize/d.config.Concurrency+2)
+		due, err := d.store.ClaimDeliveries(ctx, d.config.BatchSize, lease)
+		if err != nil {
+			return attempted, err
+		}
+
+		var (
+			wg   sync.WaitGroup
+			mu   sync.Mutex
+			errs []error
+			sem  = make(chan struct{}, d.config.Concurrency)
+		)
+		for _, delivery := range due {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer func() { <-sem; wg.Done() }()
+				if err := d.store.RecordAttempt(ctx, d.attempt(ctx, delivery)); err != nil {
+					mu.Lock()
+					errs = append(errs, err)
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		attempted += len(due)
+		if err := errors.Join(errs...); err != nil {
+			return attempted, err
+		}
+		if len(due) < d.config.BatchSize {
+			return attempted, nil
+		}
+	}
+}
+
+// attempt sends a delivery once and returns the outcome to record
+func (d *Dispatcher) attempt(ctx context.Context, delivery sqlc.ClaimWebhookDeliveriesRow) sqlc.RecordWebhookAttemptParams {
+	start := time.Now()
+	statusCode, err := d.send(ctx, delivery)
+	result := sqlc.RecordWebhookAttemptParams{
+		ID:         delivery.ID,
+		StatusCode: sql.NullInt32{Int32: int32(statusCode), Valid: statusCode != 0},
+		DurationMs: int32(time.Since(start).Milliseconds()),
+		Status:     StatusDelivered,
+	}
+	if err == nil {
+		return result
+	}
+
+	result.Error = sql.NullString{String: err.Error(), Valid: true}
+	attempts := int(delivery.Attempts) + 1
+	if attempts >= d.config.MaxAttempts {
+		result.Status = StatusDead
+		d.logger.Warn("Webhook delivery is dead",
+			zap.Error(err),
+			zap.Int64("delivery_id", delivery.ID),
+			zap.Int32("subscription_id", delivery.SubscriptionID),
+			zap.Int("attempts", attempts),
+		)
+		return result
+	}
+
+	retryIn := d.backoff(int(delivery.Attempts))
+	result.Status = StatusPending
+	result.RetrySeconds = retryIn.Seconds()
+	d.logger.Info("Webhook delivery failed",
+		zap.Error(err),
+		zap.Int64("delivery_id", delivery.ID),
+		zap.Int32("subscription_id", delivery.SubscriptionID),
+		zap.Int("attempts", attempts),
+		zap.Duration("retry_in", retryIn),
+	)
+	return result
+}
+
+// send POSTs a delivery and returns the response status, 0 when there was
+// no response
+func (d *Dispatcher) send(ctx context.Context, delivery sqlc.ClaimWebhookDeliveriesRow) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	now := d.config.Clock.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-user-api-webhooks")
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, now, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before retrying a delivery that failed
+// attempts times before
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.MinBackoff
+	for i := 0; i < attempts && delay < d.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.config.MaxBackoff)
+}
@@ -0,0 +1,103 @@
+// internal/webhook/webhook.go
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/internal/outbox"
+)
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	// StatusDead marks a delivery whose every retry failed
+	StatusDead = "dead"
+)
+
+// Headers sent with each delivery
+const (
+	HeaderDelivery  = "X-Webhook-ID"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+var (
+	ErrInvalidSignature = errors.New("webhook signature does not match")
+	ErrStaleTimestamp   = errors.New("webhook timestamp is missing or outside the tolerance")
+)
+
+// NewSecret returns a random secret for signing deliveries
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Sign returns the signature header of body sent at timestamp: "sha256="
+// followed by the hex HMAC-SHA256, keyed by secret, of the timestamp in Unix
+// seconds, a dot and the body
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature of a delivery and that it was sent within
+// tolerance of now, so receivers can reject forged and replayed deliveries
+func Verify(secret string, header http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	seconds, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrStaleTimestamp
+	}
+	timestamp := time.Unix(seconds, 0)
+	if d := now.Sub(timestamp); d > tolerance || d < -tolerance {
+		return ErrStaleTimestamp
+	}
+
+	signature := header.Get(HeaderSignature)
+	if !strings.HasPrefix(signature, "sha256=") || !hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Enqueuer queues deliveries of an event; repository.WebhookRepository is
+// one
+type Enqueuer interface {
+	EnqueueDeliveries(ctx context.Context, eventID int64, eventType string, payload json.RawMessage) (int64, error)
+}
+
+type sink struct {
+	store Enqueuer
+}
+
+// NewSink returns the outbox sink that queues a delivery of each event to
+// every active subscription to its type. The dispatcher sends them.
+func NewSink(store Enqueuer) outbox.Sink {
+	return &sink{store: store}
+}
+
+func (s *sink) Publish(ctx context.Context, e outbox.Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = s.store.EnqueueDeliveries(ctx, e.ID, e.Type, payload)
+	return err
+}
@@ -0,0 +1,256 @@
+// internal/webhook/webhook_test.go
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shravanirajulu2004/go-user-api/db/sqlc"
+	"github.com/shravanirajulu2004/go-user-api/internal/clock"
+	"github.com/shravanirajulu2004/go-user-api/internal/outbox"
+	"go.uber.org/zap"
+)
+
+type storedDelivery struct {
+	sqlc.ClaimWebhookDeliveriesRow
+	status   string
+	retryAt  time.Time
+	attempts []sqlc.RecordWebhookAttemptParams
+}
+
+// memoryStore queues deliveries in memory; its time only moves when told to
+type memoryStore struct {
+	mu         sync.Mutex
+	deliveries []*storedDelivery
+	// inactive holds the subscriptions whose deliveries are never claimed
+	inactive map[int32]bool
+	now      time.Time
+}
+
+func (s *memoryStore) EnqueueDeliveries(_ context.Context, eventID int64, eventType string, payload json.RawMessage) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.deliveries {
+		if d.EventID == eventID {
+			return 0, nil
+		}
+	}
+	s.deliveries = append(s.deliveries, &storedDelivery{
+		ClaimWebhookDeliveriesRow: sqlc.ClaimWebhookDeliveriesRow{
+			ID: int64(len(s.deliveries) + 1), SubscriptionID: 1, EventID: eventID, EventType: eventType, Payload: payload,
+		},
+		status: StatusPending,
+	})
+	return 1, nil
+}
+
+// point aims every delivery at url, signed with secret
+func (s *memoryStore) point(url, secret string) {
+	for _, d := range s.deliveries {
+		d.Url, d.Secret = url, secret
+	}
+}
+
+func (s *memoryStore) ClaimDeliveries(_ context.Context, limit int, lease time.Duration) ([]sqlc.ClaimWebhookDeliveriesRow, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []sqlc.ClaimWebhookDeliveriesRow
+	for _, d := range s.deliveries {
+		if d.status == StatusPending && !d.retryAt.After(s.now) && !s.inactive[d.SubscriptionID] && len(due) < limit {
+			d.retryAt = s.now.Add(lease)
+			due = append(due, d.ClaimWebhookDeliveriesRow)
+		}
+	}
+	return due, nil
+}
+
+func (s *memoryStore) RecordAttempt(_ context.Context, attempt sqlc.RecordWebhookAttemptParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	d := s.deliveries[attempt.ID-1]
+	d.Attempts++
+	d.status = attempt.Status
+	d.retryAt = s.now.Add(time.Duration(attempt.RetrySeconds * float64(time.Second)))
+	d.attempts = append(d.attempts, attempt)
+	return nil
+}
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"id":1}`)
+	header := http.Header{}
+	header.Set(HeaderTimestamp, "1700000000")
+	header.Set(HeaderSignature, Sign("topsecret", now, body))
+
+	if err := Verify("topsecret", header, body, now.Add(time.Minute), 5*time.Minute); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+	if err := Verify("othersecret", header, body, now, 5*time.Minute); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() with the wrong secret error = %v, want %v", err, ErrInvalidSignature)
+	}
+	if err := Verify("topsecret", header, []byte(`{"id":2}`), now, 5*time.Minute); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Verify() of a changed body error = %v, want %v", err, ErrInvalidSignature)
+	}
+	if err := Verify("topsecret", header, body, now.Add(time.Hour), 5*time.Minute); !errors.Is(err, ErrStaleTimestamp) {
+		t.Errorf("Verify() of a replay error = %v, want %v", err, ErrStaleTimestamp)
+	}
+}
+
+func TestDispatcher_DeliversSignedEvents(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify("topsecret", r.Header, body, now, time.Minute); err != nil {
+			t.Errorf("Verify() error = %v", err)
+		}
+		mu.Lock()
+		received = append(received, r.Header.Get(HeaderEvent))
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	store := &memoryStore{now: now}
+	sink := NewSink(store)
+	ctx := context.Background()
+	for i, eventType := range []string{outbox.UserCreated, outbox.UserUpdated} {
+		if err := sink.Publish(ctx, outbox.Event{ID: int64(i + 1), Type: eventType, UserID: 1, Data: json.RawMessage(`{}`)}); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+	store.point(server.URL, "topsecret")
+
+	d := NewDispatcher(store, server.Client(), Config{Clock: clock.Fixed(now)}, zap.NewNop())
+	if n, err := d.RunOnce(ctx); err != nil || n != 2 {
+		t.Fatalf("RunOnce() = %d, %v, want 2 attempts", n, err)
+	}
+	if len(received) != 2 {
+		t.Fatalf("server received %d deliveries, want 2", len(received))
+	}
+	for _, delivery := range store.deliveries {
+		if delivery.status != StatusDelivered {
+			t.Errorf("delivery %d status = %s, want %s", delivery.ID, delivery.status, StatusDelivered)
+		}
+	}
+	if n, _ := d.RunOnce(ctx); n != 0 {
+		t.Errorf("RunOnce() again attempted %d deliveries, want 0", n)
+	}
+}
+
+func TestDispatcher_RetriesWithBackoffThenDies(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	store := &memoryStore{now: now}
+	ctx := context.Background()
+	_ = NewSink(store).Publish(ctx, outbox.Event{ID: 1, Type: outbox.UserDeleted, UserID: 1, Data: json.RawMessage(`{}`)})
+	store.point(server.URL, "topsecret")
+
+	d := NewDispatcher(store, server.Client(), Config{
+		MaxAttempts: 3,
+		MinBackoff:  time.Minute,
+		MaxBackoff:  time.Hour,
+	}, zap.NewNop())
+
+	delivery := store.deliveries[0]
+	for i, wantRetry := range []time.Duration{time.Minute, 2 * time.Minute} {
+		if _, err := d.RunOnce(ctx); err != nil {
+			t.Fatalf("RunOnce() error = %v", err)
+		}
+		if delivery.status != StatusPending || delivery.retryAt != store.now.Add(wantRetry) {
+			t.Fatalf("after attempt %d: status %s, retry at %v, want pending at %v",
+				i+1, delivery.status, delivery.retryAt, store.now.Add(wantRetry))
+		}
+		if n, _ := d.RunOnce(ctx); n != 0 {
+			t.Fatalf("RunOnce() before the backoff attempted %d deliveries", n)
+		}
+		store.now = delivery.retryAt
+	}
+
+	if _, err := d.RunOnce(ctx); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if delivery.status != StatusDead || calls != 3 {
+		t.Errorf("status %s after %d calls, want %s after 3", delivery.status, calls, StatusDead)
+	}
+	last := delivery.attempts[len(delivery.attempts)-1]
+	if last.StatusCode.Int32 != http.StatusServiceUnavailable || !last.Error.Valid {
+		t.Errorf("last attempt = %+v, want a logged 503", last)
+	}
+}
+
+func TestDispatcher_DoesNotFollowRedirects(t *testing.T) {
+	followed := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer server.Close()
+
+	store := &memoryStore{now: time.Now()}
+	ctx := context.Background()
+	_ = NewSink(store).Publish(ctx, outbox.Event{ID: 1, Type: outbox.UserCreated, UserID: 1, Data: json.RawMessage(`{}`)})
+	store.point(server.URL, "topsecret")
+
+	if _, err := NewDispatcher(store, server.Client(), Config{}, zap.NewNop()).RunOnce(ctx); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if followed {
+		t.Error("dispatcher followed a redirect")
+	}
+	if status := store.deliveries[0].status; status != StatusPending {
+		t.Errorf("status = %s, want %s", status, StatusPending)
+	}
+}
+
+func TestDispatcher_SkipsInactiveSubscriptions(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	// Subscription 2 was deactivated with a backlog larger than a batch,
+	// queued ahead of subscription 1's delivery
+	store := &memoryStore{now: now, inactive: map[int32]bool{2: true}}
+	for i, subscriptionID := range []int32{2, 2, 2, 1} {
+		store.deliveries = append(store.deliveries, &storedDelivery{
+			ClaimWebhookDeliveriesRow: sqlc.ClaimWebhookDeliveriesRow{
+				ID: int64(i + 1), SubscriptionID: subscriptionID, EventID: int64(i + 1), EventType: outbox.UserUpdated, Payload: json.RawMessage(`{}`),
+			},
+			status: StatusPending,
+		})
+	}
+	store.point(server.URL, "topsecret")
+
+	d := NewDispatcher(store, server.Client(), Config{BatchSize: 2, Clock: clock.Fixed(now)}, zap.NewNop())
+	if n, err := d.RunOnce(context.Background()); err != nil || n != 1 {
+		t.Fatalf("RunOnce() = %d, %v, want 1 attempt", n, err)
+	}
+	for _, delivery := range store.deliveries {
+		want := StatusPending
+		if delivery.SubscriptionID == 1 {
+			want = StatusDelivered
+		}
+		if delivery.status != want || (want == StatusPending && delivery.Attempts != 0) {
+			t.Errorf("delivery %d to subscription %d: status = %s after %d attempts, want %s",
+				delivery.ID, delivery.SubscriptionID, delivery.status, delivery.Attempts, want)
+		}
+	}
+}